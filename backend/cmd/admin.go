@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"go-learn-platform/internal/admin"
+	"go-learn-platform/internal/database"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/routes"
+)
+
+// runUser implements `user promote|demote|disable|enable <email>`
+func runUser(opts config.Options, args []string) {
+    if len(args) != 2 {
+        fmt.Fprintln(os.Stderr, "usage: user promote|demote|disable|enable <email>")
+        os.Exit(2)
+    }
+    action, email := args[0], args[1]
+
+    cfg, db := setup(opts)
+    defer database.Close(db)
+    requireSchema(db)
+    catalog := routes.NewServices(db, cfg).Catalog
+
+    var (
+        user models.User
+        err  error
+    )
+    switch action {
+    case "promote":
+        user, err = admin.SetRole(db, email, models.RoleAdmin, catalog)
+    case "demote":
+        user, err = admin.SetRole(db, email, models.RoleUser, catalog)
+    case "disable":
+        user, err = admin.SetDisabled(db, email, true, catalog)
+    case "enable":
+        user, err = admin.SetDisabled(db, email, false, catalog)
+    default:
+        fmt.Fprintf(os.Stderr, "unknown user action %q\n", action)
+        os.Exit(2)
+    }
+    if err != nil {
+        log.Fatalf("Failed to %s user: %v", action, err)
+    }
+
+    status := "active"
+    if user.DisabledAt != nil {
+        status = "disabled"
+    }
+    log.Printf("User %d (%s): role=%s, status=%s", user.ID, user.Email, user.Role, status)
+}
+
+// runCourse implements `course transfer <course-id> <new-owner-email>`
+func runCourse(opts config.Options, args []string) {
+    if len(args) != 3 || args[0] != "transfer" {
+        fmt.Fprintln(os.Stderr, "usage: course transfer <course-id> <new-owner-email>")
+        os.Exit(2)
+    }
+
+    courseID, err := strconv.ParseUint(args[1], 10, 32)
+    if err != nil {
+        log.Fatalf("Invalid course ID %q", args[1])
+    }
+
+    cfg, db := setup(opts)
+    defer database.Close(db)
+    requireSchema(db)
+
+    // Service dengan cache yang dikonfigurasi, agar kursus dan profil di cache ikut diperbarui
+    svc := routes.NewServices(db, cfg)
+    course, err := admin.TransferCourse(db, uint(courseID), args[2], svc.Catalog)
+    if err != nil {
+        log.Fatalf("Failed to transfer course: %v", err)
+    }
+    log.Printf("Course %d (%s) now belongs to %s", course.ID, course.Title, args[2])
+}
+
+// runRecomputeProgress implements `recompute-progress [-course id]`
+func runRecomputeProgress(opts config.Options, args []string) {
+    fs := flag.NewFlagSet("recompute-progress", flag.ExitOnError)
+    courseID := fs.Uint("course", 0, "only recompute enrollments of this course")
+    fs.Parse(args)
+
+    cfg, db := setup(opts)
+    defer database.Close(db)
+    requireSchema(db)
+
+    // Service dengan cache yang dikonfigurasi, agar profil di cache ikut diperbarui
+    svc := routes.NewServices(db, cfg)
+    updated, err := admin.RecomputeProgress(db, svc.Progress, *courseID)
+    if err != nil {
+        log.Fatalf("Failed after updating %d enrollment(s): %v", updated, err)
+    }
+    log.Printf("Recomputed progress of %d enrollment(s)", updated)
+}
+
+// runGCUploads implements `gc-uploads [-dry-run] [-older-than 24h]`
+func runGCUploads(opts config.Options, args []string) {
+    fs := flag.NewFlagSet("gc-uploads", flag.ExitOnError)
+    dryRun := fs.Bool("dry-run", false, "only list what would be deleted")
+    olderThan := fs.Duration("older-than", 24*time.Hour, "only delete uploads and files older than this")
+    fs.Parse(args)
+
+    _, db := setup(opts)
+    defer database.Close(db)
+    requireSchema(db)
+
+    report, err := admin.GCUploads(db, *olderThan, *dryRun)
+    if err != nil {
+        log.Fatalf("Failed to collect uploads: %v", err)
+    }
+
+    verb := "Deleted"
+    if *dryRun {
+        verb = "Would delete"
+    }
+    for _, id := range report.AbandonedUploads {
+        log.Printf("%s abandoned upload %s", verb, id)
+    }
+    for _, path := range report.OrphanFiles {
+        log.Printf("%s orphan file %s", verb, path)
+    }
+    log.Printf("%s %d upload(s) and %d file(s), %d bytes", verb, len(report.AbandonedUploads), len(report.OrphanFiles), report.FreedBytes)
+}
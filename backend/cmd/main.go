@@ -1,79 +1,108 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"log"
+	"os"
+
 	"go-learn-platform/internal/auth"
-	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/database"
+	"go-learn-platform/internal/migrations"
 	"go-learn-platform/internal/pkg/config"
-	"go-learn-platform/internal/routes"
-
-	"log"
-    "time"
+	"go-learn-platform/internal/pkg/logger"
+	"go-learn-platform/internal/pkg/media"
+	"go-learn-platform/internal/pkg/urls"
 
-    "github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+const usage = `usage: main [-config file] [-env-file file] <command> [arguments]
 
-// initDB: Inisialisasi koneksi database
-func initDB() (*gorm.DB, error) {
-    dsn := config.LoadConfig().DBDsn
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-    if err != nil {
-        return nil, err
-    }
-    return db, nil
-}
+commands:
+  serve                              run the HTTP server (default)
+  worker                             only run background jobs
+  migrate up|down|status|create      manage database migrations
+  user promote|demote <email>        grant or revoke the admin role
+  user disable|enable <email>        block or unblock an account
+  course transfer <id> <email>       give a course to another owner
+  recompute-progress [-course id]    recalculate enrollment progress
+  gc-uploads [-dry-run] [-older-than 24h]
+                                     delete abandoned uploads and orphan files
+  seed [-fixture demo]               load demo data (not in production)
+  token <email>                      print a JWT for a seeded user (not in production)
 
-// migrateDB: Migrasi model ke database
-func migrateDB(db *gorm.DB) error {
-    err := models.Migrate(db)
-    if err != nil {
-        return err
-    }
-    return nil
+Run "main <command> -h" for the flags of a command.
+`
+
+// commands maps each subcommand to its implementation
+var commands = map[string]func(opts config.Options, args []string){
+    "serve":              runServe,
+    "worker":             runWorker,
+    "migrate":            runMigrate,
+    "user":               runUser,
+    "course":             runCourse,
+    "recompute-progress": runRecomputeProgress,
+    "gc-uploads":         runGCUploads,
+    "seed":               runSeed,
+    "token":              runToken,
 }
 
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nglobal flags:")
+		flag.PrintDefaults()
 	}
+	configFile := flag.String("config", "", "path to a YAML or TOML config file (default: $CONFIG_FILE)")
+	envFile := flag.String("env-file", "", "path to a .env file (default: $ENV_FILE or .env)")
+	flag.Parse()
 
-	cfg := config.LoadConfig()
-	auth.InitGoogleConfig(cfg)
+	opts := config.Options{ConfigFile: *configFile, EnvFile: *envFile}
 
-    DB, err = initDB()
-    if err != nil {
-        log.Fatalf("Failed to initialize database: %v", err)
-    }
-    log.Println("Successfully connected to the database!")
+	name := flag.Arg(0)
+	if name == "" {
+		name = "serve"
+	}
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var args []string
+	if flag.NArg() > 1 {
+		args = flag.Args()[1:]
+	}
+	command(opts, args)
+}
 
-    err = migrateDB(DB)
+// setup loads the configuration, initializes the shared packages and opens
+// the database. Every command uses it so they behave exactly like the server.
+func setup(opts config.Options) (*config.Config, *gorm.DB) {
+    cfg, err := config.Load(opts)
     if err != nil {
-        log.Fatalf("Failed to migrate database: %v", err)
+        log.Fatal(err)
     }
-    log.Println("Database migration completed successfully!")
-
-    r := gin.Default()
+    logger.Init(cfg)
 
-    r.Use(cors.New(cors.Config{
-        AllowOrigins:     []string{"http://localhost:5173"}, // frontend origin kamu
-        AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
-        AllowCredentials: true,
-        MaxAge: 12 * time.Hour,
-    }))
-    
-    r.Static("/public", "./public")
+    auth.InitGoogleConfig(cfg)
+    auth.InitJWT(cfg)
+    media.Init(cfg)
+    urls.Init(cfg)
 
-    routes.Routes(r, DB)
+    db, err := database.Open(cfg)
+    if err != nil {
+        log.Fatalf("Failed to initialize database: %v", err)
+    }
+    return cfg, db
+}
 
-	fmt.Println("server running in http://localhost:8080")
-    r.Run(":8080")
-}
\ No newline at end of file
+// requireSchema stops a command when migrations are pending
+func requireSchema(db *gorm.DB) {
+    if err := migrations.EnsureUpToDate(context.Background(), db); err != nil {
+        log.Fatalf("%v (run `go run ./cmd migrate up`)", err)
+    }
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"go-learn-platform/internal/database"
+	"go-learn-platform/internal/migrations"
+	"go-learn-platform/internal/pkg/config"
+)
+
+const migrateUsage = `usage: main [-config file] [-env-file file] migrate <command>
+
+commands:
+  up [n]        apply all pending migrations, or only the next n
+  down [n]      roll back the last applied migration, or the last n
+  status        list migrations and when they were applied
+  create <name> write a new empty up/down migration pair (-dir to change the folder)
+`
+
+// runMigrate implements the `migrate` subcommand
+func runMigrate(opts config.Options, args []string) {
+    if len(args) == 0 {
+        fmt.Fprint(os.Stderr, migrateUsage)
+        os.Exit(2)
+    }
+
+    command, rest := args[0], args[1:]
+
+    // create tidak butuh koneksi database
+    if command == "create" {
+        fs := flag.NewFlagSet("migrate create", flag.ExitOnError)
+        dir := fs.String("dir", migrations.DefaultDir, "folder to write the migration files to")
+        fs.Parse(rest)
+        if fs.NArg() != 1 {
+            log.Fatal("usage: migrate create [-dir folder] <name>")
+        }
+
+        upPath, downPath, err := migrations.Create(*dir, fs.Arg(0))
+        if err != nil {
+            log.Fatalf("Failed to create migration: %v", err)
+        }
+        fmt.Printf("Created %s\nCreated %s\n", upPath, downPath)
+        return
+    }
+
+    steps := 0
+    if len(rest) > 0 {
+        n, err := strconv.Atoi(rest[0])
+        if err != nil || n < 1 {
+            log.Fatalf("Invalid number of steps %q", rest[0])
+        }
+        steps = n
+    }
+
+    _, db := setup(opts)
+    defer database.Close(db)
+
+    migrator, err := migrations.New(db)
+    if err != nil {
+        log.Fatalf("Failed to load migrations: %v", err)
+    }
+    migrator.Logf = log.Printf
+
+    ctx := context.Background()
+    switch command {
+    case "up":
+        applied, err := migrator.Up(ctx, steps)
+        if err != nil {
+            log.Fatal(err)
+        }
+        log.Printf("Applied %d migration(s)", len(applied))
+    case "down":
+        rolledBack, err := migrator.Down(ctx, steps)
+        if err != nil {
+            log.Fatal(err)
+        }
+        log.Printf("Rolled back %d migration(s)", len(rolledBack))
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            log.Fatal(err)
+        }
+
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+        for _, status := range statuses {
+            appliedAt := "pending"
+            if status.AppliedAt != nil {
+                appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+            }
+            fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+        }
+        w.Flush()
+    default:
+        fmt.Fprint(os.Stderr, migrateUsage)
+        os.Exit(2)
+    }
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go-learn-platform/internal/auth"
+	"go-learn-platform/internal/database"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/routes"
+	"go-learn-platform/internal/seed"
+)
+
+// runSeed implements `seed [-fixture name|file.yaml]`
+func runSeed(opts config.Options, args []string) {
+    fs := flag.NewFlagSet("seed", flag.ExitOnError)
+    fixtureName := fs.String("fixture", seed.DefaultFixture, "embedded fixture name or path to a fixture .yaml file")
+    fs.Parse(args)
+
+    fixture, err := seed.Load(*fixtureName)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    cfg, db := setup(opts)
+    defer database.Close(db)
+    if cfg.IsProduction() {
+        log.Fatal("Refusing to seed demo data in production")
+    }
+    requireSchema(db)
+
+    report, err := seed.Apply(db, fixture, routes.NewServices(db, cfg).Catalog)
+    if err != nil {
+        log.Fatalf("Failed to seed %s: %v", *fixtureName, err)
+    }
+    log.Printf("Seeded %s: created %d user(s), %d course(s), %d lesson(s), %d quiz(zes), %d enrollment(s), %d quiz result(s); existing records updated",
+        *fixtureName, report.Users, report.Courses, report.Lessons, report.Quizzes, report.Enrollments, report.QuizResults)
+}
+
+// runToken implements `token <email>`, printing a JWT for a seeded user
+func runToken(opts config.Options, args []string) {
+    if len(args) != 1 {
+        fmt.Fprintln(os.Stderr, "usage: token <email of a seeded user>")
+        os.Exit(2)
+    }
+
+    cfg, db := setup(opts)
+    defer database.Close(db)
+    if cfg.IsProduction() {
+        log.Fatal("Refusing to mint dev tokens in production")
+    }
+    requireSchema(db)
+
+    _, token, err := auth.DevToken(db, args[0])
+    if err != nil {
+        log.Fatalf("Failed to mint token for %s: %v", args[0], err)
+    }
+    fmt.Println(token)
+}
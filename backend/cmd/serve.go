@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-learn-platform/internal/controllers"
+	"go-learn-platform/internal/database"
+	"go-learn-platform/internal/migrations"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/tracing"
+	"go-learn-platform/internal/pkg/urls"
+	"go-learn-platform/internal/pkg/version"
+	"go-learn-platform/internal/routes"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// migrateDB: Jalankan migrasi yang tertunda (jika diaktifkan) lalu pastikan skema terbaru
+func migrateDB(cfg *config.Config, db *gorm.DB) error {
+    if cfg.Database.MigrateOnStart {
+        migrator, err := migrations.New(db)
+        if err != nil {
+            return err
+        }
+        migrator.Logf = func(format string, args ...interface{}) {
+            slog.Info(fmt.Sprintf(format, args...))
+        }
+        if _, err := migrator.Up(context.Background(), 0); err != nil {
+            return err
+        }
+    }
+
+    return migrations.EnsureUpToDate(context.Background(), db)
+}
+
+// runServe implements the `serve` command
+func runServe(opts config.Options, args []string) {
+    opts.Serve = true
+    cfg, DB := setup(opts)
+    defer database.Close(DB)
+    slog.Info("Loaded configuration", "config", cfg.String())
+    slog.Info("Successfully connected to the database")
+
+    shutdownTracing, err := tracing.Init(context.Background(), cfg)
+    if err != nil {
+        slog.Error("Failed to initialize tracing", "error", err)
+        os.Exit(1)
+    }
+    // Kirim span yang masih tertahan sebelum proses berhenti
+    defer func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        if err := shutdownTracing(ctx); err != nil {
+            slog.Warn("Failed to flush traces", "error", err)
+        }
+    }()
+
+    if cfg.IsProduction() {
+        gin.SetMode(gin.ReleaseMode)
+    }
+    // Daftar route dari gin ikut masuk ke log terstruktur
+    gin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+        slog.Debug("Route registered", "method", method, "path", path, "handler", handler)
+    }
+
+    err = migrateDB(cfg, DB)
+    if err != nil {
+        slog.Error("Refusing to start, run `go run ./cmd migrate up`", "error", err)
+        os.Exit(1)
+    }
+    slog.Info("Database schema is up to date")
+
+    // Access log, recovery, CORS dan security header dipasang oleh routes.Routes
+    r := gin.New()
+
+    // X-Forwarded-For hanya dipercaya dari proxy yang terdaftar, sehingga
+    // rate limit per IP tidak bisa diakali dengan header palsu
+    if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+        slog.Error("Invalid TRUSTED_PROXIES", "error", err)
+        os.Exit(1)
+    }
+
+    svc := routes.Routes(r, DB, cfg)
+
+    srv := &http.Server{
+        Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+        Handler:           r,
+        ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+    }
+
+    // Stream notifikasi ditutup saat shutdown, jika tidak Shutdown menunggu
+    // sampai client memutus koneksi
+    srv.RegisterOnShutdown(svc.Notifications.Close)
+
+    // Tunggu SIGINT/SIGTERM lalu drain request yang masih berjalan
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    serverErr := make(chan error, 1)
+    go func() {
+        serverErr <- srv.ListenAndServe()
+    }()
+
+    metricsSrv := startMetrics(cfg, DB, serverErr)
+
+    // Job latar belakang ikut berjalan di proses server kecuali JOB_WORKERS=0
+    stopJobs := func(context.Context) {}
+    if cfg.Jobs.Workers > 0 {
+        // Worker memakai service yang sama agar cache katalog ikut diperbarui
+        stopJobs, err = startJobs(cfg, DB, svc)
+        if err != nil {
+            slog.Error("Failed to start job workers", "error", err)
+            os.Exit(1)
+        }
+    }
+
+    slog.Info("Go Learn Platform "+version.Get().String(),
+        "env", cfg.Env, "addr", srv.Addr, "public_url", urls.API(""))
+
+    select {
+    case err := <-serverErr:
+        if err != nil && !errors.Is(err, http.ErrServerClosed) {
+            slog.Error("Server failed", "error", err)
+            os.Exit(1)
+        }
+    case <-ctx.Done():
+        stop()
+        slog.Info("Shutdown signal received, draining requests", "timeout", cfg.Server.ShutdownTimeout.String())
+        controllers.MarkShuttingDown()
+
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+        defer cancel()
+        if err := srv.Shutdown(shutdownCtx); err != nil {
+            slog.Warn("Graceful shutdown did not complete", "error", err)
+        }
+        if metricsSrv != nil {
+            metricsSrv.Shutdown(shutdownCtx)
+        }
+        stopJobs(shutdownCtx)
+    }
+
+    slog.Info("Server stopped")
+}
+
+// startMetrics registers the database pool metrics and, when METRICS_ADDR is
+// set, serves /metrics on that separate listener. Errors of the listener are
+// sent to serverErr.
+func startMetrics(cfg *config.Config, db *gorm.DB, serverErr chan<- error) *http.Server {
+    if !cfg.Metrics.Enabled {
+        return nil
+    }
+    if sqlDB, err := db.DB(); err == nil {
+        metrics.RegisterDB(sqlDB)
+    }
+    if cfg.Metrics.Addr == "" {
+        slog.Info("Serving metrics on the API port at /metrics")
+        return nil
+    }
+
+    mux := http.NewServeMux()
+    mux.Handle("/metrics", metrics.Handler())
+    srv := &http.Server{
+        Addr:              cfg.Metrics.Addr,
+        Handler:           mux,
+        ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+    }
+    go func() {
+        if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            serverErr <- fmt.Errorf("metrics listener: %w", err)
+        }
+    }()
+    slog.Info("Serving metrics", "addr", cfg.Metrics.Addr)
+    return srv
+}
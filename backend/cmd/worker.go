@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go-learn-platform/internal/database"
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/jobs/tasks"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/routes"
+	"go-learn-platform/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// startJobs runs the job workers in the background, delivering domain events
+// to the subscribers of svc. The returned function stops them and waits for
+// running jobs until ctx is done.
+func startJobs(cfg *config.Config, db *gorm.DB, svc *services.Services) (func(ctx context.Context), error) {
+    bus := events.NewBus()
+    svc.Subscribe(bus)
+    queue := jobs.New(db, cfg.Jobs)
+    if err := tasks.Register(queue, db, cfg, bus); err != nil {
+        return nil, err
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        queue.Run(ctx)
+    }()
+
+    return func(shutdownCtx context.Context) {
+        cancel()
+        select {
+        case <-done:
+        case <-shutdownCtx.Done():
+            slog.Warn("Job workers did not stop in time")
+        }
+    }, nil
+}
+
+// runWorker implements `worker`: only run background jobs, for deployments
+// that keep them out of the API processes (JOB_WORKERS=0 there)
+func runWorker(opts config.Options, args []string) {
+    cfg, db := setup(opts)
+    defer database.Close(db)
+    requireSchema(db)
+    if cfg.Jobs.Workers < 1 {
+        cfg.Jobs.Workers = 1
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    stopJobs, err := startJobs(cfg, db, routes.NewServices(db, cfg))
+    if err != nil {
+        log.Fatalf("Failed to start job workers: %v", err)
+    }
+    <-ctx.Done()
+
+    slog.Info("Shutdown signal received, finishing running jobs", "timeout", cfg.Server.ShutdownTimeout.String())
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+    defer cancel()
+    stopJobs(shutdownCtx)
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// ErrUserNotFound is returned when no user matches the given email
+var ErrUserNotFound = errors.New("user not found")
+
+// findUser looks up a user by email
+func findUser(db *gorm.DB, email string) (models.User, error) {
+    var user models.User
+    if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return user, fmt.Errorf("%w: %s", ErrUserNotFound, email)
+        }
+        return user, err
+    }
+    return user, nil
+}
+
+// SetRole changes the role of a user. catalog should come from services.New
+// so the cached profile is refreshed.
+func SetRole(db *gorm.DB, email string, role string, catalog *services.Catalog) (models.User, error) {
+    if role != models.RoleUser && role != models.RoleAdmin {
+        return models.User{}, fmt.Errorf("unknown role %q (use %s or %s)", role, models.RoleUser, models.RoleAdmin)
+    }
+
+    user, err := findUser(db, email)
+    if err != nil {
+        return user, err
+    }
+
+    user.Role = role
+    if err := db.Model(&user).Update("role", role).Error; err != nil {
+        return user, err
+    }
+    catalog.ProfileChanged(context.Background(), user.ID)
+    return user, nil
+}
+
+// SetDisabled disables or re-enables a user account. Disabled users cannot
+// log in and their existing tokens are rejected by AuthMiddleware.
+func SetDisabled(db *gorm.DB, email string, disabled bool, catalog *services.Catalog) (models.User, error) {
+    user, err := findUser(db, email)
+    if err != nil {
+        return user, err
+    }
+
+    var disabledAt *time.Time
+    if disabled {
+        now := time.Now()
+        disabledAt = &now
+    }
+
+    user.DisabledAt = disabledAt
+    if err := db.Model(&user).Update("disabled_at", disabledAt).Error; err != nil {
+        return user, err
+    }
+    catalog.ProfileChanged(context.Background(), user.ID)
+    return user, nil
+}
+
+// TransferCourse makes another user the owner of a course. The cached course
+// and the profiles of the old and the new owner are dropped from catalog.
+func TransferCourse(db *gorm.DB, courseID uint, newOwnerEmail string, catalog *services.Catalog) (models.Course, error) {
+    var course models.Course
+    if err := db.First(&course, courseID).Error; err != nil {
+        return course, fmt.Errorf("course %d: %w", courseID, err)
+    }
+
+    owner, err := findUser(db, newOwnerEmail)
+    if err != nil {
+        return course, err
+    }
+
+    oldOwner := course.UserID
+    course.UserID = owner.ID
+    if err := db.Model(&course).Update("user_id", owner.ID).Error; err != nil {
+        return course, err
+    }
+
+    ctx := context.Background()
+    catalog.CourseChanged(ctx, course.ID)
+    catalog.ProfileChanged(ctx, oldOwner)
+    catalog.ProfileChanged(ctx, owner.ID)
+    return course, nil
+}
+
+// RecomputeProgress recalculates the stored progress of every enrollment, or
+// only those of one course when courseID is not zero. progress should come
+// from services.New so cached profiles are refreshed. It returns the number
+// of enrollments updated.
+func RecomputeProgress(db *gorm.DB, progress services.ProgressService, courseID uint) (int, error) {
+    query := db.Model(&models.Enrollment{})
+    if courseID != 0 {
+        query = query.Where("course_id = ?", courseID)
+    }
+
+    var enrollments []models.Enrollment
+    if err := query.Find(&enrollments).Error; err != nil {
+        return 0, err
+    }
+
+    for i, enrollment := range enrollments {
+        if err := progress.Recalculate(context.Background(), enrollment.UserID, enrollment.CourseID); err != nil {
+            return i, fmt.Errorf("enrollment %d: %w", enrollment.ID, err)
+        }
+    }
+    return len(enrollments), nil
+}
@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/media"
+
+	"gorm.io/gorm"
+)
+
+// GCReport lists what GCUploads removed, or would remove in a dry run
+type GCReport struct {
+    AbandonedUploads []string // ID upload session yang tidak pernah selesai
+    OrphanFiles      []string // File di disk yang tidak dirujuk database
+    FreedBytes       int64
+}
+
+// GCUploads removes abandoned resumable uploads and files that are no longer
+// referenced by any course, profile, lesson or video. Only things older than
+// olderThan are touched so uploads in progress are left alone.
+func GCUploads(db *gorm.DB, olderThan time.Duration, dryRun bool) (GCReport, error) {
+    var report GCReport
+    cutoff := time.Now().Add(-olderThan)
+
+    var sessions []models.UploadSession
+    if err := db.Where("completed_at IS NULL AND updated_at < ?", cutoff).Find(&sessions).Error; err != nil {
+        return report, err
+    }
+    for _, session := range sessions {
+        partPath := filepath.Join(media.TempDir(), session.ID+".part")
+        report.AbandonedUploads = append(report.AbandonedUploads, session.ID)
+        report.FreedBytes += fileSize(partPath)
+        if dryRun {
+            continue
+        }
+        if err := db.Delete(&session).Error; err != nil {
+            return report, err
+        }
+        os.Remove(partPath)
+    }
+
+    referenced, err := referencedFiles(db)
+    if err != nil {
+        return report, err
+    }
+
+    dirs := []string{media.PublicDir(), media.PrivateDir(), media.VideoDir(), media.TempDir()}
+    for _, dir := range dirs {
+        entries, err := os.ReadDir(dir)
+        if err != nil {
+            if os.IsNotExist(err) {
+                continue
+            }
+            return report, err
+        }
+
+        for _, entry := range entries {
+            if entry.IsDir() {
+                continue
+            }
+            info, err := entry.Info()
+            if err != nil || info.ModTime().After(cutoff) {
+                continue
+            }
+
+            path := filepath.Join(dir, entry.Name())
+            if referenced[filepath.Clean(path)] {
+                continue
+            }
+
+            report.OrphanFiles = append(report.OrphanFiles, path)
+            report.FreedBytes += info.Size()
+            if !dryRun {
+                if err := os.Remove(path); err != nil {
+                    return report, err
+                }
+            }
+        }
+    }
+
+    return report, nil
+}
+
+// referencedFiles returns the disk paths of every file the database points to
+func referencedFiles(db *gorm.DB) (map[string]bool, error) {
+    referenced := map[string]bool{}
+
+    // URL relatif (/public/x, /media/private/x) dipetakan ke lokasi di disk
+    var images []string
+    for _, model := range []interface{}{&models.Course{}, &models.Profile{}, &models.Lesson{}} {
+        var batch []string
+        if err := db.Unscoped().Model(model).Where("image <> ''").Pluck("image", &batch).Error; err != nil {
+            return nil, err
+        }
+        images = append(images, batch...)
+    }
+    for _, image := range images {
+        switch {
+        case strings.HasPrefix(image, "/public/"):
+            referenced[filepath.Join(media.PublicDir(), filepath.Base(image))] = true
+        case strings.HasPrefix(image, "/media/private/"):
+            referenced[filepath.Join(media.PrivateDir(), filepath.Base(image))] = true
+        }
+    }
+
+    var videos []string
+    if err := db.Unscoped().Model(&models.LessonVideo{}).Pluck("path", &videos).Error; err != nil {
+        return nil, err
+    }
+    for _, video := range videos {
+        referenced[filepath.Clean(video)] = true
+    }
+
+    var sessions []string
+    if err := db.Model(&models.UploadSession{}).Where("completed_at IS NULL").Pluck("id", &sessions).Error; err != nil {
+        return nil, err
+    }
+    for _, id := range sessions {
+        referenced[filepath.Join(media.TempDir(), id+".part")] = true
+    }
+
+    return referenced, nil
+}
+
+// fileSize returns the size of a file, or zero if it does not exist
+func fileSize(path string) int64 {
+    info, err := os.Stat(path)
+    if err != nil {
+        return 0
+    }
+    return info.Size()
+}
@@ -0,0 +1,326 @@
+// Package apitest boots the complete router against a throwaway SQLite
+// database so handlers can be tested end to end, including the auth
+// middleware, without Postgres or Google OAuth.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/auth"
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/jobs/tasks"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/media"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/tracing"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/urls"
+	"go-learn-platform/internal/routes"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Server is a running test instance of the API
+type Server struct {
+    t        *testing.T
+    DB       *gorm.DB
+    Config   *config.Config
+    Router   *gin.Engine
+    Services *services.Services
+}
+
+// User is a user created by the harness together with a valid token
+type User struct {
+    ID    uint
+    Email string
+    Token string
+}
+
+// Config returns the configuration used by New. Storage lives in dir so
+// every test gets its own uploads.
+func Config(dir string) *config.Config {
+    cfg := &config.Config{Env: "test"}
+    cfg.Server.Port = 8080
+    cfg.Server.CORSOrigins = []string{"http://app.test"}
+    cfg.Server.CORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+    cfg.Server.CORSCredentials = true
+    cfg.Server.CORSMaxAge = 12 * time.Hour
+    cfg.JWT.Secret = "apitest-secret-0123456789"
+    cfg.JWT.TTL = time.Hour
+    cfg.Storage.PublicDir = filepath.Join(dir, "public")
+    cfg.Storage.Dir = filepath.Join(dir, "storage")
+    cfg.Storage.MaxVideoSize = 64 << 20
+    cfg.Storage.MaxChunkSize = 1 << 20
+    cfg.Storage.SigningKey = "apitest-signing-key-0123456789abcdef"
+    cfg.Storage.SignedURLTTL = 15 * time.Minute
+    cfg.URLs.PublicAPI = "http://api.test"
+    cfg.URLs.Frontend = "http://app.test"
+    cfg.Features.VideoUploads = true
+    cfg.Log.Level = "info"
+    cfg.Log.Format = "json"
+    cfg.Log.AccessLog = "off" // Aktifkan lewat configure untuk menguji access log
+    cfg.Log.RedactParams = []string{"token", "code", "state", "signature"}
+    // Job dijalankan oleh RunJobs, bukan oleh worker di latar belakang
+    cfg.Jobs = config.JobsConfig{
+        PollInterval: time.Second,
+        LockTimeout:  time.Minute,
+        MaxAttempts:  3,
+        BackoffBase:  time.Minute,
+        BackoffMax:   time.Hour,
+        Retention:    24 * time.Hour,
+    }
+    // Receiver webhook di test berjalan di localhost
+    cfg.Webhooks = config.WebhooksConfig{Timeout: 5 * time.Second, MaxAttempts: 3, AllowPrivate: true}
+    // Tanpa host email hanya dicatat di log; pakai mailtest.Server untuk menerimanya
+    cfg.Mail = config.MailConfig{Port: 587, TLS: "none", Timeout: 5 * time.Second, From: "Go Learn Platform <no-reply@example.com>"}
+    // Polling cepat agar test stream notifikasi tidak lama menunggu
+    cfg.Notifications = config.NotificationsConfig{PollInterval: 20 * time.Millisecond, KeepAlive: 15 * time.Second}
+    cfg.Security = config.SecurityConfig{
+        ContentSecurityPolicy:     "default-src 'none'; frame-ancestors 'none'",
+        FileContentSecurityPolicy: "default-src 'none'; img-src 'self'; media-src 'self'; style-src 'unsafe-inline'; sandbox",
+        HSTSMaxAge:                365 * 24 * time.Hour,
+        FrameOptions:              "DENY",
+        ReferrerPolicy:            "no-referrer",
+    }
+    return cfg
+}
+
+// New creates a fresh SQLite database in a temporary directory, creates the
+// schema and registers all routes. Pass a function to tweak the config
+// before the router is built.
+//
+// The auth, media and urls packages keep global state, so tests using the
+// harness must not run in parallel.
+func New(t *testing.T, configure ...func(*config.Config)) *Server {
+    t.Helper()
+    gin.SetMode(gin.TestMode)
+
+    dir := t.TempDir()
+    cfg := Config(dir)
+    for _, fn := range configure {
+        fn(cfg)
+    }
+
+    db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")+"?_pragma=foreign_keys(1)"), &gorm.Config{
+        Logger:         logger.Default.LogMode(logger.Silent),
+        TranslateError: true,
+    })
+    if err != nil {
+        t.Fatalf("open test database: %v", err)
+    }
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("open test database: %v", err)
+    }
+    if err := db.Use(metrics.GormPlugin{}); err != nil {
+        t.Fatalf("register metrics plugin: %v", err)
+    }
+    if err := db.Use(tracing.GormPlugin{}); err != nil {
+        t.Fatalf("register tracing plugin: %v", err)
+    }
+    // SQLite hanya mengizinkan satu penulis sekaligus
+    sqlDB.SetMaxOpenConns(1)
+    t.Cleanup(func() { sqlDB.Close() })
+
+    if err := models.Migrate(db); err != nil {
+        t.Fatalf("migrate test database: %v", err)
+    }
+
+    auth.InitJWT(cfg)
+    media.Init(cfg)
+    urls.Init(cfg)
+
+    router := gin.New()
+    svc := routes.Routes(router, db, cfg)
+    t.Cleanup(svc.Notifications.Close)
+
+    return &Server{t: t, DB: db, Config: cfg, Router: router, Services: svc}
+}
+
+// RunJobs relays the recorded events and runs every due job, like a worker
+// would, so tests can check the effects of background work
+func (s *Server) RunJobs() {
+    s.t.Helper()
+    bus := events.NewBus()
+    s.Services.Subscribe(bus)
+    queue := jobs.New(s.DB, s.Config.Jobs)
+    if err := tasks.Register(queue, s.DB, s.Config, bus); err != nil {
+        s.t.Fatalf("register jobs: %v", err)
+    }
+    if err := queue.Drain(context.Background()); err != nil {
+        s.t.Fatalf("run jobs: %v", err)
+    }
+}
+
+// CreateUser inserts a user with an empty profile and returns it with a token
+func (s *Server) CreateUser(email string) User {
+    s.t.Helper()
+
+    user := models.User{GoogleID: "test:" + email, Email: email, Role: models.RoleUser}
+    if err := s.DB.Create(&user).Error; err != nil {
+        s.t.Fatalf("create user %s: %v", email, err)
+    }
+    if err := s.DB.Create(&models.Profile{UserID: user.ID}).Error; err != nil {
+        s.t.Fatalf("create profile %s: %v", email, err)
+    }
+
+    token, err := auth.GenerateJWT(user.ID, user.Email)
+    if err != nil {
+        s.t.Fatalf("generate token %s: %v", email, err)
+    }
+    return User{ID: user.ID, Email: email, Token: token}
+}
+
+// Create inserts any model directly, for test fixtures
+func (s *Server) Create(value interface{}) {
+    s.t.Helper()
+    if err := s.DB.Create(value).Error; err != nil {
+        s.t.Fatalf("create %T: %v", value, err)
+    }
+}
+
+// Request describes a call to the API. Leave As empty for anonymous calls.
+type Request struct {
+    Method string
+    Path   string
+    As     *User
+    JSON   interface{}       // Dikirim sebagai application/json
+    Form   map[string]string // Dikirim sebagai multipart/form-data
+    Files  map[string][]byte // File untuk form multipart, key = nama field
+    Body   []byte            // Dikirim apa adanya, mis. chunk upload
+    Header map[string]string
+}
+
+// Response is a recorded API response
+type Response struct {
+    t    *testing.T
+    Code int
+    Body []byte
+    HTTP *http.Response
+}
+
+// Do sends a request through the router
+func (s *Server) Do(req Request) *Response {
+    s.t.Helper()
+
+    var body io.Reader
+    contentType := ""
+    switch {
+    case req.Form != nil || req.Files != nil:
+        var buf bytes.Buffer
+        writer := multipart.NewWriter(&buf)
+        for key, value := range req.Form {
+            writer.WriteField(key, value)
+        }
+        for field, data := range req.Files {
+            part, err := writer.CreateFormFile(field, field+".png")
+            if err != nil {
+                s.t.Fatalf("build multipart body: %v", err)
+            }
+            part.Write(data)
+        }
+        writer.Close()
+        body, contentType = &buf, writer.FormDataContentType()
+    case req.Body != nil:
+        body = bytes.NewReader(req.Body)
+    case req.JSON != nil:
+        data, err := json.Marshal(req.JSON)
+        if err != nil {
+            s.t.Fatalf("encode request body: %v", err)
+        }
+        body, contentType = bytes.NewReader(data), "application/json"
+    }
+
+    httpReq := httptest.NewRequest(req.Method, req.Path, body)
+    if contentType != "" {
+        httpReq.Header.Set("Content-Type", contentType)
+    }
+    if req.As != nil {
+        httpReq.Header.Set("Authorization", "Bearer "+req.As.Token)
+    }
+    for key, value := range req.Header {
+        httpReq.Header.Set(key, value)
+    }
+
+    recorder := httptest.NewRecorder()
+    s.Router.ServeHTTP(recorder, httpReq)
+
+    result := recorder.Result()
+    data, _ := io.ReadAll(result.Body)
+    return &Response{t: s.t, Code: recorder.Code, Body: data, HTTP: result}
+}
+
+// Get is a shortcut for an authenticated GET request
+func (s *Server) Get(path string, as *User) *Response {
+    s.t.Helper()
+    return s.Do(Request{Method: http.MethodGet, Path: path, As: as})
+}
+
+// ExpectStatus fails the test when the status code differs
+func (r *Response) ExpectStatus(code int) *Response {
+    r.t.Helper()
+    if r.Code != code {
+        r.t.Fatalf("expected status %d, got %d: %s", code, r.Code, r.Body)
+    }
+    return r
+}
+
+// Decode unmarshals the JSON body into v
+func (r *Response) Decode(v interface{}) {
+    r.t.Helper()
+    if err := json.Unmarshal(r.Body, v); err != nil {
+        r.t.Fatalf("decode response %s: %v", r.Body, err)
+    }
+}
+
+// Map decodes the JSON body into a generic map
+func (r *Response) Map() map[string]interface{} {
+    r.t.Helper()
+    var m map[string]interface{}
+    r.Decode(&m)
+    return m
+}
+
+// Data unmarshals the "data" field of a success envelope into v and returns
+// the envelope meta, if any
+func (r *Response) Data(v interface{}) *response.Meta {
+    r.t.Helper()
+    envelope := struct {
+        Data json.RawMessage `json:"data"`
+        Meta *response.Meta  `json:"meta"`
+    }{}
+    r.Decode(&envelope)
+    if err := json.Unmarshal(envelope.Data, v); err != nil {
+        r.t.Fatalf("decode response data %s: %v", envelope.Data, err)
+    }
+    return envelope.Meta
+}
+
+// APIError decodes the body of an error response
+func (r *Response) APIError() response.Error {
+    r.t.Helper()
+    var body response.ErrorBody
+    r.Decode(&body)
+    return body.Error
+}
+
+// ErrorMessage returns the message of an error response
+func (r *Response) ErrorMessage() string {
+    r.t.Helper()
+    return r.APIError().Message
+}
@@ -1,20 +1,85 @@
 package auth
 
 import (
+    "errors"
     "time"
 
+    "go-learn-platform/internal/pkg/config"
+
     "github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("your_secret_key") // Ganti dengan secret key yang aman
+var (
+    jwtKey []byte
+    jwtTTL = 24 * time.Hour
+)
+
+// InitJWT sets the secret and lifetime of the tokens issued after login
+func InitJWT(cfg *config.Config) {
+    jwtKey = []byte(cfg.JWT.Secret)
+    jwtTTL = cfg.JWT.TTL
+}
 
 // GenerateJWT generates a JWT token for the user
 func GenerateJWT(userID uint, email string) (string, error) {
     token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
         "user_id": userID,
         "email":   email,
-        "exp":     time.Now().Add(time.Hour * 24).Unix(), // Token valid selama 24 jam
+        "exp":     time.Now().Add(jwtTTL).Unix(),
     })
 
     return token.SignedString(jwtKey)
-}
\ No newline at end of file
+}
+
+// StreamTokenTTL is how long a stream token can be used to open a stream
+const StreamTokenTTL = time.Minute
+
+// streamPurpose marks the tokens only valid for the notification stream
+const streamPurpose = "notifications.stream"
+
+// GenerateStreamToken generates a short-lived token that only opens the
+// notification stream, for browsers whose EventSource cannot send headers
+func GenerateStreamToken(userID uint) (string, time.Time, error) {
+    expires := time.Now().Add(StreamTokenTTL)
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+        "user_id": userID,
+        "purpose": streamPurpose,
+        "exp":     expires.Unix(),
+    })
+    signed, err := token.SignedString(jwtKey)
+    return signed, expires, err
+}
+
+// ParseJWT validates a token issued by GenerateJWT and returns its user ID
+func ParseJWT(tokenString string) (uint, error) {
+    return parseToken(tokenString, "")
+}
+
+// ParseStreamToken validates a token issued by GenerateStreamToken and
+// returns its user ID
+func ParseStreamToken(tokenString string) (uint, error) {
+    return parseToken(tokenString, streamPurpose)
+}
+
+// parseToken validates a token and returns its user ID. Tokens with a
+// purpose are only accepted for that purpose.
+func parseToken(tokenString, purpose string) (uint, error) {
+    token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+        return jwtKey, nil
+    }, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+    if err != nil || !token.Valid {
+        return 0, errors.New("invalid token")
+    }
+    claims, ok := token.Claims.(jwt.MapClaims)
+    if !ok {
+        return 0, errors.New("invalid token claims")
+    }
+    if got, _ := claims["purpose"].(string); got != purpose {
+        return 0, errors.New("invalid token purpose")
+    }
+    userID, ok := claims["user_id"].(float64)
+    if !ok {
+        return 0, errors.New("invalid token claims")
+    }
+    return uint(userID), nil
+}
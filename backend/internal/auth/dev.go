@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/urls"
+	"go-learn-platform/internal/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+    // ErrNotSeedUser is returned when a dev token is requested for a real account
+    ErrNotSeedUser = errors.New("only seeded users can log in without OAuth")
+    // ErrAccountDisabled is returned for users disabled by an admin
+    ErrAccountDisabled = errors.New("account is disabled")
+)
+
+// DevToken mints a JWT for a user created by the seed command. It is only
+// reachable from development tooling; real accounts always go through Google.
+func DevToken(db *gorm.DB, email string) (models.User, string, error) {
+    var user models.User
+    if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+        return user, "", err
+    }
+    if !strings.HasPrefix(user.GoogleID, models.SeedGoogleIDPrefix) {
+        return user, "", ErrNotSeedUser
+    }
+    if user.DisabledAt != nil {
+        return user, "", ErrAccountDisabled
+    }
+
+    token, err := GenerateJWT(user.ID, user.Email)
+    return user, token, err
+}
+
+// HandleDevToken returns a JWT for a seeded user as JSON
+func HandleDevToken(c *gin.Context, db *gorm.DB) {
+    db = db.WithContext(c.Request.Context())
+
+    var input struct {
+        Email string `json:"email" binding:"required,email"`
+    }
+    if !validation.BindJSON(c, &input) {
+        return
+    }
+
+    user, token, err := DevToken(db, input.Email)
+    metrics.Login("dev", err == nil)
+    if err != nil {
+        devLoginError(c, err)
+        return
+    }
+
+    response.OK(c, dto.DevToken{Token: token, UserID: user.ID, Role: user.Role})
+}
+
+// HandleDevLogin logs in as a seeded user and redirects to the frontend just
+// like the Google callback does
+func HandleDevLogin(c *gin.Context, db *gorm.DB) {
+    db = db.WithContext(c.Request.Context())
+
+    _, token, err := DevToken(db, c.Query("email"))
+    metrics.Login("dev", err == nil)
+    if err != nil {
+        devLoginError(c, err)
+        return
+    }
+
+    c.Redirect(http.StatusTemporaryRedirect, urls.Frontend("/login?token="+url.QueryEscape(token)))
+}
+
+// devLoginError maps DevToken errors to a response
+func devLoginError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        response.Fail(c, http.StatusNotFound, "User not found")
+    case errors.Is(err, ErrAccountDisabled):
+        response.FailCode(c, http.StatusForbidden, response.CodeAccountDisabled, err.Error())
+    case errors.Is(err, ErrNotSeedUser):
+        response.Fail(c, http.StatusForbidden, err.Error())
+    default:
+        response.Fail(c, http.StatusInternalServerError, "Failed to generate JWT")
+    }
+}
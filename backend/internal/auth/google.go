@@ -3,12 +3,21 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
+	"go-learn-platform/internal/events"
 	"go-learn-platform/internal/models"
 	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/tracing"
+	"go-learn-platform/internal/pkg/urls"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"gorm.io/gorm"
@@ -16,11 +25,23 @@ import (
 
 var googleOauthConfig *oauth2.Config
 
+// oauthHTTPClient is used for the calls to Google so they are traced
+var oauthHTTPClient = &http.Client{
+    Transport: otelhttp.NewTransport(http.DefaultTransport),
+    Timeout:   15 * time.Second,
+}
+
+// googleUserInfo is the part of the Google userinfo response we use
+type googleUserInfo struct {
+    ID    string `json:"id"`
+    Email string `json:"email"`
+}
+
 func InitGoogleConfig(cfg *config.Config) {
 	googleOauthConfig = &oauth2.Config{
-		ClientID:     cfg.GoogleClientID,
-		ClientSecret: cfg.GoogleClientSecret,
-		RedirectURL:  cfg.RedirectURL,
+		ClientID:     cfg.Google.ClientID,
+		ClientSecret: cfg.Google.ClientSecret,
+		RedirectURL:  cfg.Google.RedirectURL,
 		Scopes: []string{
 			"https://www.googleapis.com/auth/userinfo.email",
 			"https://www.googleapis.com/auth/userinfo.profile",
@@ -35,30 +56,29 @@ func HandleGoogleLogin(c *gin.Context) {
 }
 
 func HandleGoogleCallback(c *gin.Context, db *gorm.DB) {
+    // Hitung percobaan login untuk metrics, gagal kecuali sampai redirect sukses
+    success := false
+    defer func() { metrics.Login("google", success) }()
+
+    // Panggilan ke Google menjadi child span dari request ini
+    ctx := context.WithValue(c.Request.Context(), oauth2.HTTPClient, oauthHTTPClient)
+    db = db.WithContext(ctx)
+
     code := c.Query("code")
-    token, err := googleOauthConfig.Exchange(context.Background(), code)
+    exchangeCtx, span := tracing.Start(ctx, "oauth.google.exchange")
+    token, err := googleOauthConfig.Exchange(exchangeCtx, code)
+    tracing.End(span, err)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to exchange token"})
+        response.Fail(c, http.StatusBadRequest, "Failed to exchange token")
         return
     }
 
-    client := googleOauthConfig.Client(context.Background(), token)
-
     // Ambil user info dari Google API
-    resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+    userInfoCtx, span := tracing.Start(ctx, "oauth.google.userinfo")
+    userInfo, err := fetchGoogleUserInfo(userInfoCtx, token)
+    tracing.End(span, err)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info"})
-        return
-    }
-    defer resp.Body.Close()
-
-    // Decode JSON response
-    var userInfo struct {
-        ID    string `json:"id"`
-        Email string `json:"email"`
-    }
-    if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user info"})
+        response.Fail(c, http.StatusInternalServerError, "Failed to get user info")
         return
     }
 
@@ -71,26 +91,65 @@ func HandleGoogleCallback(c *gin.Context, db *gorm.DB) {
             GoogleID: userInfo.ID,
             Email:    userInfo.Email,
         }
-        db.Create(&newUser)
+        err = db.Transaction(func(tx *gorm.DB) error {
+            if err := tx.Create(&newUser).Error; err != nil {
+                return err
+            }
 
-        // Buat profil dengan nilai default
-        newProfile := models.Profile{
-            UserID: newUser.ID, // Hubungkan ke User
-            Name:   "",         // Default name
-            Image:  "",         // Default image URL
+            // Buat profil dengan nilai default
+            newProfile := models.Profile{
+                UserID: newUser.ID, // Hubungkan ke User
+                Name:   "",         // Default name
+                Image:  "",         // Default image URL
+            }
+            if err := tx.Create(&newProfile).Error; err != nil {
+                return err
+            }
+            return events.Record(ctx, tx, events.UserRegistered{UserID: newUser.ID, Email: newUser.Email})
+        })
+        if err != nil {
+            response.Fail(c, http.StatusInternalServerError, "Failed to create user")
+            return
         }
-        db.Create(&newProfile)
 
         user = newUser
     }
 
+    // Akun yang dinonaktifkan admin tidak boleh login
+    if user.DisabledAt != nil {
+        c.Redirect(http.StatusTemporaryRedirect, urls.Frontend("/login?error=account_disabled"))
+        return
+    }
+
     // Generate JWT
     jwtToken, err := GenerateJWT(user.ID, user.Email)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate JWT"})
+        response.Fail(c, http.StatusInternalServerError, "Failed to generate JWT")
         return
     }
 
-    redirectURL := "http://localhost:5173/login?token=" + jwtToken
+    success = true
+    redirectURL := urls.Frontend("/login?token=" + url.QueryEscape(jwtToken))
     c.Redirect(http.StatusTemporaryRedirect, redirectURL)
-}
\ No newline at end of file
+}
+
+// fetchGoogleUserInfo reads the account of the logged in Google user
+func fetchGoogleUserInfo(ctx context.Context, token *oauth2.Token) (googleUserInfo, error) {
+    var userInfo googleUserInfo
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.googleapis.com/oauth2/v2/userinfo", nil)
+    if err != nil {
+        return userInfo, err
+    }
+    resp, err := googleOauthConfig.Client(ctx, token).Do(req)
+    if err != nil {
+        return userInfo, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return userInfo, fmt.Errorf("google userinfo: unexpected status %d", resp.StatusCode)
+    }
+    err = json.NewDecoder(resp.Body).Decode(&userInfo)
+    return userInfo, err
+}
@@ -1,325 +1,223 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"strconv"
+	"time"
 
+	"go-learn-platform/internal/dto"
 	"go-learn-platform/internal/middleware"
 	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/httpcache"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // GetCourseProgress calculates and returns the progress of a user in a course
-func GetCourseProgress(c *gin.Context, db *gorm.DB) {
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+func GetCourseProgress(c *gin.Context, progress services.ProgressService) {
+    userID, ok := currentUserID(c)
+    if !ok {
         return
     }
 
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+    courseID, ok := paramID(c, "course_id", "Invalid course ID")
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
-    // Ambil course_id dari parameter URL
-    courseID, err := strconv.Atoi(c.Param("course_id"))
+    value, err := progress.CourseProgress(c.Request.Context(), userID, courseID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
-        return
-    }
-
-    // Cek apakah pengguna terdaftar di kursus
-    var enrollment models.Enrollment
-    if err := db.Where("user_id = ? AND course_id = ?", userIDUint, courseID).First(&enrollment).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User is not enrolled in this course"})
-        return
-    }
-
-    // Hitung total lesson dalam kursus
-    var totalLessons int64
-    if err := db.Model(&models.Lesson{}).Where("course_id = ?", courseID).Count(&totalLessons).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count lessons"})
-        return
-    }
-
-    // Hitung jumlah lesson yang telah diselesaikan oleh pengguna
-    var completedLessons int64
-    if err := db.Table("lesson_progress").
-        Where("user_id = ? AND lesson_id IN (?)", userIDUint, db.Table("lessons").Select("id").Where("course_id = ?", courseID)).
-        Count(&completedLessons).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count completed lessons"})
+        if errors.Is(err, services.ErrNotEnrolled) {
+            response.FailCode(c, http.StatusNotFound, response.CodeNotEnrolled, "User is not enrolled in this course")
+            return
+        }
+        response.Fail(c, http.StatusInternalServerError, "Failed to calculate progress")
         return
     }
 
-    // Hitung progress dalam persentase
-    progress := 0.0
-    if totalLessons > 0 {
-        progress = (float64(completedLessons) / float64(totalLessons)) * 100
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "course_id": courseID,
-        "progress":  progress,
-    })
+    response.OK(c, dto.CourseProgress{CourseID: courseID, Progress: value})
 }
 
 // CreateCourse creates a new course
-func CreateCourse(c *gin.Context, db *gorm.DB) {
-    title := c.PostForm("title")
-    description := c.PostForm("description")
-
-    if title == "" || description == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Title and Description are required"})
-        return
+func CreateCourse(c *gin.Context, courses services.CourseService) {
+    var input struct {
+        Title       string `form:"title" binding:"required,notblank,max=200"`
+        Description string `form:"description" binding:"required,notblank,max=5000"`
     }
-
-    // Ambil user_id dari context (diatur middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+    if !validation.BindForm(c, &input) {
         return
     }
 
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
     // Upload image
     imageURL, err := middleware.UploadFile(c, "image")
     if err != nil && err.Error() != "failed to retrieve file: http: no such file" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        response.Fail(c, http.StatusBadRequest, err.Error())
         return
     }
 
     // Buat course baru
     course := models.Course{
-        Title:       title,
-        Description: description,
-        UserID:      userIDUint,
+        Title:       input.Title,
+        Description: input.Description,
+        UserID:      userID,
         Image:       imageURL,
     }
 
-    if err := db.Create(&course).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create course"})
+    if err := courses.Create(c.Request.Context(), &course); err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to create course")
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Course created successfully",
-        "course":  course,
-    })
+    response.Created(c, dto.NewCourse(course), "Course created successfully")
 }
 
 // UpdateCourse updates a specific course by ID
-func UpdateCourse(c *gin.Context, db *gorm.DB) {
-    id, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
-        return
-    }
-
-    var course models.Course
-    if err := db.First(&course, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-        return
-    }
-
-    // Ambil user_id dari context
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+func UpdateCourse(c *gin.Context, courses services.CourseService) {
+    id, ok := paramID(c, "id", "Invalid course ID")
+    if !ok {
         return
     }
 
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
-    // Cek ownership
-    if course.UserID != userIDUint {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to update this course"})
+    // Field kosong tidak diubah
+    var input struct {
+        Title       string `form:"title" binding:"omitempty,notblank,max=200"`
+        Description string `form:"description" binding:"omitempty,notblank,max=5000"`
+    }
+    if !validation.BindForm(c, &input) {
         return
     }
 
-    // Ambil data dari form
-    title := c.PostForm("title")
-    description := c.PostForm("description")
-
-    if title != "" {
-        course.Title = title
-    }
-    if description != "" {
-        course.Description = description
+    // Dengan If-Match update ditolak jika course berubah sejak dibaca klien
+    var version time.Time
+    if c.GetHeader("If-Match") != "" {
+        current, err := courses.Get(c.Request.Context(), id)
+        if err != nil {
+            response.Fail(c, http.StatusNotFound, "Course not found")
+            return
+        }
+        if !httpcache.IfMatch(c, courseVersion(current)) {
+            return
+        }
+        version = current.UpdatedAt
     }
 
     // Upload file baru jika ada
     imageURL, err := middleware.UploadFile(c, "image")
     if err != nil && err.Error() != "failed to retrieve file: http: no such file" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        response.Fail(c, http.StatusBadRequest, err.Error())
         return
     }
 
-    if imageURL != "" {
-        course.Image = imageURL
-    }
-
-    if err := db.Save(&course).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course"})
+    course, err := courses.Update(c.Request.Context(), userID, id, services.CourseChanges{
+        Title:       input.Title,
+        Description: input.Description,
+        Image:       imageURL,
+        Version:     version,
+    })
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Course not found")
+        return
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to update this course")
+        return
+    case errors.Is(err, services.ErrModified):
+        response.Fail(c, http.StatusPreconditionFailed, "The course was changed by someone else, reload it and try again")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to update course")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Course updated successfully",
-        "course":  course,
-    })
+    response.Updated(c, dto.NewCourse(course), "Course updated successfully")
 }
 
-// GetCourses retrieves a list of courses with their associated lessons and user profiles
-func GetCourses(c *gin.Context, db *gorm.DB) {
-    var courses []models.Course
-
-    // Preload "Lessons" and "User.Profile" relationships
-    if err := db.Preload("Lessons").
-        Preload("User.Profile"). // Preload User and Profile
-        Find(&courses).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch courses"})
+// GetCourses retrieves a page of courses with their instructor
+func GetCourses(c *gin.Context, courses services.CourseService) {
+    page, ok := pageParams(c)
+    if !ok {
         return
     }
 
-    // Construct course and profile image URLs
-    for i, course := range courses {
-        // Set image URL for the course
-        if course.Image != "" {
-            courses[i].Image = fmt.Sprintf("http://localhost:8080%s", course.Image)
-        }
-
-        // Set image URL for the user's profile
-        if course.User.Profile.Image != "" {
-            courses[i].User.Profile.Image = fmt.Sprintf("http://localhost:8080%s", course.User.Profile.Image)
-        }
+    list, total, err := courses.List(c.Request.Context(), page)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch courses")
+        return
+    }
+    if httpcache.NotModified(c, httpcache.ETag(courseListVersion(list, page, total), ""), httpcache.Revalidate) {
+        return
     }
 
-    // Return the courses along with user profile and image URLs
-    c.JSON(http.StatusOK, gin.H{"courses": courses})
+    response.List(c, dto.NewCourses(list), listMeta(page, total))
 }
 
 // GetCourse retrieves a specific course by ID, including profile & quizzes
-func GetCourse(c *gin.Context, db *gorm.DB) {
-    id, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+func GetCourse(c *gin.Context, courses services.CourseService) {
+    id, ok := paramID(c, "id", "Invalid course ID")
+    if !ok {
         return
     }
 
-    var course models.Course
-    // Preload User.Profile, Lessons, and nested Quizzes
-    if err := db.
-        Preload("User.Profile").
-        Preload("Lessons.Quizzes").
-        Preload("Lessons").
-        First(&course, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+    course, err := courses.Get(c.Request.Context(), id)
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "Course not found")
         return
     }
 
-    // Prefix image URLs
-    if course.Image != "" {
-        course.Image = fmt.Sprintf("http://localhost:8080%s", course.Image)
+    // Media lesson hanya untuk peserta terdaftar dan pemilik kursus
+    allowed, err := courses.CanAccess(c.Request.Context(), c.GetUint("userID"), course)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to check enrollment")
+        return
     }
-    if course.User.Profile.Image != "" {
-        course.User.Profile.Image = fmt.Sprintf("http://localhost:8080%s", course.User.Profile.Image)
+    etag := httpcache.ETag(courseVersion(course), mediaVariant(course.Lessons, allowed))
+    if httpcache.NotModified(c, etag, httpcache.Revalidate) {
+        return
     }
-    // Also prefix lesson images if you store them similarly
-    for i, lesson := range course.Lessons {
-        if lesson.Image != "" {
-            course.Lessons[i].Image = fmt.Sprintf("http://localhost:8080%s", lesson.Image)
-        }
+    for i := range course.Lessons {
+        signLessonMedia(&course.Lessons[i], allowed)
     }
 
-    c.JSON(http.StatusOK, gin.H{"course": course})
+    response.OK(c, dto.NewCourseDetail(course))
 }
 
-
 // DeleteCourse deletes a specific course by ID
-func DeleteCourse(c *gin.Context, db *gorm.DB) {
-    id, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
-        return
-    }
-
-    var course models.Course
-    if err := db.First(&course, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-        return
-    }
-
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+func DeleteCourse(c *gin.Context, courses services.CourseService) {
+    id, ok := paramID(c, "id", "Invalid course ID")
+    if !ok {
         return
     }
 
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
-    // Verifikasi bahwa pengguna memiliki kursus ini
-    if course.UserID != userIDUint {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this course"})
+    err := courses.Delete(c.Request.Context(), userID, id)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Course not found")
         return
-    }
-
-    if err := db.Delete(&course).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete course"})
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to delete this course")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to delete course")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Course deleted successfully"})
+    response.Message(c, "Course deleted successfully")
 }
-
-// UpdateCourseProgress calculates and updates the progress of a user in a course
-func UpdateCourseProgress(db *gorm.DB, userID uint, courseID uint) error {
-    // Hitung total lesson dalam kursus
-    var totalLessons int64
-    if err := db.Model(&models.Lesson{}).Where("course_id = ?", courseID).Count(&totalLessons).Error; err != nil {
-        return err
-    }
-
-    // Hitung jumlah lesson yang telah diselesaikan oleh pengguna
-    var completedLessons int64
-    if err := db.Table("quiz_results").
-        Where("user_id = ? AND quiz_id IN (?)", userID, db.Table("quizzes").Select("id").Where("lesson_id IN (?)", db.Table("lessons").Select("id").Where("course_id = ?", courseID))).
-        Distinct("quiz_id").
-        Count(&completedLessons).Error; err != nil {
-        return err
-    }
-
-    // Hitung progress dalam persentase
-    progress := 0.0
-    if totalLessons > 0 {
-        progress = (float64(completedLessons) / float64(totalLessons)) * 100
-    }
-
-    // Perbarui progress di tabel Enrollment
-    if err := db.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", userID, courseID).Update("progress", progress).Error; err != nil {
-        return err
-    }
-
-    return nil
-}
\ No newline at end of file
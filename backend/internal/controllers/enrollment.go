@@ -1,129 +1,92 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"strconv"
 
-	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // EnrollUser enrolls a user to a course
-func EnrollUser(c *gin.Context, db *gorm.DB) {
+func EnrollUser(c *gin.Context, enrollments services.EnrollmentService) {
     var input struct {
-        CourseID uint `json:"course_id" binding:"required"`
+        CourseID uint `json:"course_id" binding:"required,exists=course"`
     }
-
-    // Validasi input
-    if err := c.ShouldBindJSON(&input); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-        return
-    }
-
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+    if !validation.BindJSON(c, &input) {
         return
     }
 
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
-    // Cek apakah kursus ada
-    var course models.Course
-    if err := db.First(&course, input.CourseID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+    enrollment, err := enrollments.Enroll(c.Request.Context(), userID, input.CourseID)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Course not found")
         return
-    }
-
-    // Cek apakah pengguna sudah terdaftar di kursus
-    var existingEnrollment models.Enrollment
-    if err := db.Where("user_id = ? AND course_id = ?", userIDUint, input.CourseID).First(&existingEnrollment).Error; err == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "User is already enrolled in this course"})
+    case errors.Is(err, services.ErrAlreadyEnrolled):
+        response.FailCode(c, http.StatusBadRequest, response.CodeAlreadyEnrolled, "User is already enrolled in this course")
         return
-    }
-
-    // Buat pendaftaran baru
-    enrollment := models.Enrollment{
-        UserID:   userIDUint,
-        CourseID: input.CourseID,
-    }
-    if err := db.Create(&enrollment).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll user"})
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to enroll user")
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "User enrolled successfully",
-        "enrollment": enrollment,
-    })
+    response.Created(c, dto.NewEnrollment(enrollment), "User enrolled successfully")
 }
 
-// GetEnrollments retrieves all courses a user is enrolled in
-func GetEnrollments(c *gin.Context, db *gorm.DB) {
-    userID, err := strconv.Atoi(c.Param("user_id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+// GetEnrollments retrieves a page of the courses a user is enrolled in
+func GetEnrollments(c *gin.Context, enrollments services.EnrollmentService) {
+    userID, ok := paramID(c, "user_id", "Invalid user ID")
+    if !ok {
         return
     }
 
-    var enrollments []models.Enrollment
-    if err := db.Preload("Course").Where("user_id = ?", userID).Find(&enrollments).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollments"})
+    page, ok := pageParams(c)
+    if !ok {
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"enrollments": enrollments})
-}
-
-// CancelEnrollment cancels a user's enrollment in a course
-func CancelEnrollment(c *gin.Context, db *gorm.DB) {
-    enrollmentID, err := strconv.Atoi(c.Param("id"))
-    fmt.Println(enrollmentID)
+    list, total, err := enrollments.ListByUser(c.Request.Context(), userID, page)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch enrollments")
         return
     }
 
-    var enrollment models.Enrollment
-    if err := db.First(&enrollment, enrollmentID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
-        return
-    }
+    response.List(c, dto.NewEnrollments(list), listMeta(page, total))
+}
 
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+// CancelEnrollment cancels a user's enrollment in a course
+func CancelEnrollment(c *gin.Context, enrollments services.EnrollmentService) {
+    enrollmentID, ok := paramID(c, "id", "Invalid enrollment ID")
+    if !ok {
         return
     }
 
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
-    // Verifikasi bahwa pengguna adalah pemilik pendaftaran
-    if enrollment.UserID != userIDUint {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to cancel this enrollment"})
+    err := enrollments.Cancel(c.Request.Context(), userID, enrollmentID)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Enrollment not found")
         return
-    }
-
-    // Hapus pendaftaran
-    if err := db.Delete(&enrollment).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel enrollment"})
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to cancel this enrollment")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to cancel enrollment")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Enrollment canceled successfully"})
-}
\ No newline at end of file
+    response.Message(c, "Enrollment canceled successfully")
+}
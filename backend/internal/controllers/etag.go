@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"strconv"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/httpcache"
+	"go-learn-platform/internal/pkg/media"
+	"go-learn-platform/internal/services"
+)
+
+// courseListVersion is the version of a page of the catalog
+func courseListVersion(list []models.Course, page services.Page, total int64) string {
+    v := httpcache.NewVersion("courses").Add(page.Number, page.Size, total)
+    for _, course := range list {
+        v.Record(course.ID, course.UpdatedAt)
+        addInstructor(v, course.User)
+    }
+    return v.String()
+}
+
+// courseVersion is the version of a course with its instructor, lessons,
+// quizzes and videos
+func courseVersion(course models.Course) string {
+    v := httpcache.NewVersion("course").Record(course.ID, course.UpdatedAt)
+    addInstructor(v, course.User)
+    for _, lesson := range course.Lessons {
+        addLesson(v, lesson)
+    }
+    return v.String()
+}
+
+// lessonVersion is the version of a lesson with its quizzes and video
+func lessonVersion(lesson models.Lesson) string {
+    v := httpcache.NewVersion("lesson")
+    addLesson(v, lesson)
+    return v.String()
+}
+
+func addInstructor(v *httpcache.Version, user models.User) {
+    v.Add(user.ID).Record(user.Profile.ID, user.Profile.UpdatedAt)
+}
+
+func addLesson(v *httpcache.Version, lesson models.Lesson) {
+    v.Record(lesson.ID, lesson.UpdatedAt)
+    for _, quiz := range lesson.Quizzes {
+        v.Record(quiz.ID, quiz.UpdatedAt)
+    }
+    if lesson.Video != nil {
+        v.Record(lesson.Video.ID, lesson.Video.UpdatedAt)
+    }
+}
+
+// mediaVariant describes how the lesson media of a response look for the
+// viewer: hidden without access, otherwise signed URLs that change once per
+// signing window
+func mediaVariant(lessons []models.Lesson, allowed bool) string {
+    hasMedia := false
+    for _, lesson := range lessons {
+        hasMedia = hasMedia || lesson.Image != "" || lesson.Video != nil
+    }
+    switch {
+    case !hasMedia:
+        return ""
+    case !allowed:
+        return "hidden"
+    default:
+        return "signed:" + strconv.FormatInt(media.Expires(media.TTL()).Unix(), 10)
+    }
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go-learn-platform/internal/pkg/version"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// shuttingDown is set once the server starts draining, so /readyz tells the
+// load balancer to stop sending new traffic while in-flight requests finish
+var shuttingDown atomic.Bool
+
+// MarkShuttingDown makes the readiness probe fail from now on
+func MarkShuttingDown() {
+    shuttingDown.Store(true)
+}
+
+// Healthz is the liveness probe: it only reports that the process is serving
+func Healthz(c *gin.Context) {
+    c.Header("Cache-Control", "no-store")
+    c.JSON(http.StatusOK, gin.H{
+        "status":  "ok",
+        "version": version.Get(),
+    })
+}
+
+// Readyz is the readiness probe: it checks the database connection and that
+// the server is not shutting down. The schema is checked once at startup, as
+// serve refuses to start with pending migrations. A failed check is reported
+// as "unavailable"; the cause is logged.
+func Readyz(c *gin.Context, db *gorm.DB) {
+    c.Header("Cache-Control", "no-store")
+
+    checks := gin.H{}
+    ready := true
+
+    if shuttingDown.Load() {
+        checks["server"] = "shutting down"
+        ready = false
+    } else {
+        checks["server"] = "ok"
+    }
+
+    ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+    defer cancel()
+
+    sqlDB, err := db.DB()
+    if err == nil {
+        err = sqlDB.PingContext(ctx)
+    }
+    // Detail error hanya dicatat di log, endpoint ini tidak memakai login
+    if err != nil {
+        slog.ErrorContext(ctx, "Readiness check failed", "check", "database", "error", err)
+        checks["database"] = "unavailable"
+        ready = false
+    } else {
+        checks["database"] = "ok"
+    }
+
+    status := http.StatusOK
+    statusText := "ready"
+    if !ready {
+        status = http.StatusServiceUnavailable
+        statusText = "not ready"
+    }
+    c.JSON(status, gin.H{"status": statusText, "checks": checks})
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobs lists background jobs, optionally filtered by status and kind
+func GetJobs(c *gin.Context, jobs services.JobService) {
+    var query struct {
+        Status string `form:"status" binding:"omitempty,oneof=pending running done dead"`
+        Kind   string `form:"kind" binding:"omitempty,max=100"`
+    }
+    if !validation.BindQuery(c, &query) {
+        return
+    }
+    page, ok := pageParams(c)
+    if !ok {
+        return
+    }
+
+    list, total, err := jobs.List(c.Request.Context(), services.JobFilter{Status: query.Status, Kind: query.Kind}, page)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch jobs")
+        return
+    }
+
+    response.List(c, dto.NewJobs(list), listMeta(page, total))
+}
+
+// GetJob shows a background job with its last error
+func GetJob(c *gin.Context, jobs services.JobService) {
+    id, ok := paramID(c, "id", "Invalid job ID")
+    if !ok {
+        return
+    }
+
+    job, err := jobs.Get(c.Request.Context(), id)
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "Job not found")
+        return
+    }
+
+    response.OK(c, dto.NewJob(job))
+}
+
+// RetryJob schedules a dead or waiting job to run again right away
+func RetryJob(c *gin.Context, jobs services.JobService) {
+    id, ok := paramID(c, "id", "Invalid job ID")
+    if !ok {
+        return
+    }
+
+    job, err := jobs.Retry(c.Request.Context(), id)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Job not found")
+        return
+    case errors.Is(err, services.ErrNotRetryable):
+        response.Fail(c, http.StatusConflict, "Only dead or pending jobs can be retried")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to retry job")
+        return
+    }
+
+    response.Updated(c, dto.NewJob(job), "Job scheduled for retry")
+}
@@ -1,149 +1,162 @@
 package controllers
 
 import (
+	"errors"
+	"go-learn-platform/internal/dto"
 	"go-learn-platform/internal/middleware"
 	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/httpcache"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-// CreateLesson handles creating a new lesson
-func CreateLesson(c *gin.Context, db *gorm.DB) {
-    // Ambil data dari form
-    title := c.PostForm("title")
-    content := c.PostForm("content")
-    orderStr := c.PostForm("order")
-    courseIDStr := c.PostForm("course_id")
-
-    order, err := strconv.Atoi(orderStr)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order value"})
-        return
-    }
+// lessonInput is the form of CreateLesson and UpdateLesson
+type lessonInput struct {
+    Title    string `form:"title" binding:"required,notblank,max=200"`
+    Content  string `form:"content" binding:"required,notblank,max=100000"`
+    Order    int    `form:"order" binding:"required,min=1,max=10000"`
+    CourseID uint   `form:"course_id" binding:"required,exists=course"`
+}
 
-    courseID, err := strconv.ParseUint(courseIDStr, 10, 32)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+// CreateLesson handles creating a new lesson
+func CreateLesson(c *gin.Context, courses services.CourseService) {
+    var input lessonInput
+    if !validation.BindForm(c, &input) {
         return
     }
 
     // Upload image
-    imageURL, err := middleware.UploadFile(c, "image")
+    imageURL, err := middleware.UploadPrivateFile(c, "image")
     if err != nil && err.Error() != "failed to retrieve file: http: no such file" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        response.Fail(c, http.StatusBadRequest, err.Error())
         return
     }
 
     lesson := models.Lesson{
-        Title:    title,
-        Content:  content,
-        Order:    order,
-        CourseID: uint(courseID),
+        Title:    input.Title,
+        Content:  input.Content,
+        Order:    input.Order,
+        CourseID: input.CourseID,
         Image:    imageURL,
     }
 
-    if err := db.Create(&lesson).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lesson"})
+    if err := courses.CreateLesson(c.Request.Context(), &lesson); err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to create lesson")
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{"message": "Lesson created successfully", "data": lesson})
+    signLessonMedia(&lesson, true)
+
+    response.Created(c, dto.NewLesson(lesson), "Lesson created successfully")
 }
 
 // UpdateLesson updates an existing lesson
-func UpdateLesson(c *gin.Context, db *gorm.DB) {
-    lessonID, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+func UpdateLesson(c *gin.Context, courses services.CourseService) {
+    lessonID, ok := paramID(c, "id", "Invalid lesson ID")
+    if !ok {
         return
     }
 
-    var lesson models.Lesson
-    if err := db.First(&lesson, lessonID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+    var input lessonInput
+    if !validation.BindForm(c, &input) {
         return
     }
 
-    // Ambil data dari form
-    title := c.PostForm("title")
-    content := c.PostForm("content")
-    orderStr := c.PostForm("order")
-    courseIDStr := c.PostForm("course_id")
-
-    order, err := strconv.Atoi(orderStr)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order value"})
-        return
-    }
-
-    courseID, err := strconv.ParseUint(courseIDStr, 10, 32)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
-        return
+    // Dengan If-Match update ditolak jika lesson berubah sejak dibaca klien
+    var version time.Time
+    if c.GetHeader("If-Match") != "" {
+        current, _, err := courses.GetLesson(c.Request.Context(), lessonID)
+        if err != nil {
+            response.Fail(c, http.StatusNotFound, "Lesson not found")
+            return
+        }
+        if !httpcache.IfMatch(c, lessonVersion(current)) {
+            return
+        }
+        version = current.UpdatedAt
     }
 
     // Upload file baru jika ada
-    imageURL, err := middleware.UploadFile(c, "image")
+    imageURL, err := middleware.UploadPrivateFile(c, "image")
     if err != nil && err.Error() != "failed to retrieve file: http: no such file" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        response.Fail(c, http.StatusBadRequest, err.Error())
         return
     }
 
-    // Update field-fieldnya
-    lesson.Title = title
-    lesson.Content = content
-    lesson.Order = order
-    lesson.CourseID = uint(courseID)
-    if imageURL != "" {
-        lesson.Image = imageURL // kalau ada file baru, update image
-    }
-
-    if err := db.Save(&lesson).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lesson"})
+    lesson, err := courses.UpdateLesson(c.Request.Context(), lessonID, services.LessonChanges{
+        CourseID: input.CourseID,
+        Title:    input.Title,
+        Content:  input.Content,
+        Order:    input.Order,
+        Image:    imageURL,
+        Version:  version,
+    })
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Lesson not found")
+        return
+    case errors.Is(err, services.ErrModified):
+        response.Fail(c, http.StatusPreconditionFailed, "The lesson was changed by someone else, reload it and try again")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to update lesson")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Lesson updated successfully", "data": lesson})
+    signLessonMedia(&lesson, true)
+
+    response.Updated(c, dto.NewLesson(lesson), "Lesson updated successfully")
 }
 
+// GetLesson retrieves a lesson with its quizzes and video
+func GetLesson(c *gin.Context, courses services.CourseService) {
+    lessonID, ok := paramID(c, "id", "Invalid lesson ID")
+    if !ok {
+        return
+    }
 
-func GetLesson(c *gin.Context, db *gorm.DB){
-	lessonID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+    lesson, course, err := courses.GetLesson(c.Request.Context(), lessonID)
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "Lesson not found")
+        return
+    }
 
-	var lesson models.Lesson
-    if err := db.Preload("Quizzes").First(&lesson, lessonID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+    // Media lesson hanya untuk peserta terdaftar dan pemilik kursus
+    allowed, err := courses.CanAccess(c.Request.Context(), c.GetUint("userID"), course)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to check enrollment")
+        return
+    }
+    etag := httpcache.ETag(lessonVersion(lesson), mediaVariant([]models.Lesson{lesson}, allowed))
+    if httpcache.NotModified(c, etag, httpcache.Revalidate) {
         return
     }
+    signLessonMedia(&lesson, allowed)
 
-	c.JSON(http.StatusOK, gin.H{"data": lesson})
+    response.OK(c, dto.NewLesson(lesson))
 }
 
 // DeleteLesson deletes a lesson by ID
-func DeleteLesson(c *gin.Context, db *gorm.DB) {
-    lessonID, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+func DeleteLesson(c *gin.Context, courses services.CourseService) {
+    lessonID, ok := paramID(c, "id", "Invalid lesson ID")
+    if !ok {
         return
     }
 
-    var lesson models.Lesson
-    if err := db.First(&lesson, lessonID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+    err := courses.DeleteLesson(c.Request.Context(), lessonID)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Lesson not found")
         return
-    }
-
-    if err := db.Delete(&lesson).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete lesson"})
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to delete lesson")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Lesson deleted successfully"})
-}
\ No newline at end of file
+    response.Message(c, "Lesson deleted successfully")
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/media"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/tracing"
+	"go-learn-platform/internal/pkg/urls"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// privateMediaDirs lists the folders under the storage dir served through /media
+var privateMediaDirs = map[string]bool{
+    "private": true,
+    "videos":  true,
+}
+
+// ServePublicFile serves catalog files such as course thumbnails and profile
+// pictures with long-lived cache headers. Legacy lesson images that still live
+// in the public folder are only served with a valid signature.
+func ServePublicFile(c *gin.Context, courses services.CourseService) {
+    name := path.Clean("/" + c.Param("filepath"))
+    urlPath := "/public" + name
+
+    private, err := courses.IsLessonImage(c.Request.Context(), urlPath)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to check file access")
+        return
+    }
+
+    if private {
+        if err := media.Verify(urlPath, c.Request.URL.Query()); err != nil {
+            response.FailCode(c, http.StatusForbidden, response.CodeInvalidSignature, "A valid signed URL is required for this file")
+            return
+        }
+        c.Header("Cache-Control", "private, max-age=300")
+    } else {
+        // Nama file acak dan tidak pernah ditimpa, jadi aman untuk di-cache lama
+        c.Header("Cache-Control", "public, max-age=31536000, immutable")
+    }
+
+    serveRegularFile(c, filepath.Join(media.PublicDir(), filepath.FromSlash(name)))
+}
+
+// ServeSignedMedia serves private course media after checking the HMAC
+// signature and expiry issued by SignedMediaURL
+func ServeSignedMedia(c *gin.Context) {
+    name := path.Clean("/" + c.Param("filepath"))
+    urlPath := "/media" + name
+
+    dir, _, found := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+    if !found || !privateMediaDirs[dir] {
+        response.Fail(c, http.StatusNotFound, "File not found")
+        return
+    }
+
+    if err := media.Verify(urlPath, c.Request.URL.Query()); err != nil {
+        response.FailCode(c, http.StatusForbidden, response.CodeInvalidSignature, "Invalid or expired media URL")
+        return
+    }
+
+    c.Header("Cache-Control", "private, max-age=300")
+    serveRegularFile(c, filepath.Join(media.StorageDir(), filepath.FromSlash(name)))
+}
+
+// SignedMediaURL returns an absolute, time-limited URL for a stored media path
+func SignedMediaURL(mediaPath string) string {
+    return urls.API(media.SignURL(mediaPath, media.TTL()))
+}
+
+// signLessonMedia replaces the lesson image and video with signed URLs when the
+// viewer may access the course, and hides them otherwise
+func signLessonMedia(lesson *models.Lesson, allowed bool) {
+    if lesson.Image != "" {
+        if allowed {
+            lesson.Image = SignedMediaURL(lesson.Image)
+        } else {
+            lesson.Image = ""
+        }
+    }
+
+    if lesson.Video != nil {
+        if allowed {
+            lesson.Video.URL = SignedMediaURL("/media/videos/" + filepath.Base(lesson.Video.Path))
+        } else {
+            lesson.Video.URL = ""
+        }
+    }
+}
+
+// serveRegularFile serves a file from disk, refusing directories
+func serveRegularFile(c *gin.Context, filePath string) {
+    _, span := tracing.Start(c.Request.Context(), "storage.read",
+        attribute.String("file.name", filepath.Base(filePath)))
+
+    info, err := os.Stat(filePath)
+    if err == nil && !info.Mode().IsRegular() {
+        err = errors.New("not a regular file")
+    }
+    if err != nil {
+        tracing.End(span, err)
+        response.Fail(c, http.StatusNotFound, "File not found")
+        return
+    }
+
+    c.File(filePath)
+    span.End()
+}
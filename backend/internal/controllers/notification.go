@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-learn-platform/internal/auth"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/emails"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotificationSettings returns the notification emails the user receives
+func GetNotificationSettings(c *gin.Context, profiles services.ProfileService) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    profile, err := profiles.Notifications(c.Request.Context(), userID)
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "User not found")
+        return
+    }
+    response.OK(c, dto.NewNotificationSettings(profile))
+}
+
+// UpdateNotificationSettings turns notification emails on or off; missing
+// fields keep their value
+func UpdateNotificationSettings(c *gin.Context, profiles services.ProfileService) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    var input struct {
+        Enrollments *bool `json:"enrollments"`
+        NewLessons  *bool `json:"new_lessons"`
+        QuizResults *bool `json:"quiz_results"`
+    }
+    if !validation.BindJSON(c, &input) {
+        return
+    }
+
+    profile, err := profiles.UpdateNotifications(c.Request.Context(), userID, services.NotificationChanges{
+        Enrollments: input.Enrollments,
+        NewLessons:  input.NewLessons,
+        QuizResults: input.QuizResults,
+    })
+    if errors.Is(err, services.ErrNotFound) {
+        response.Fail(c, http.StatusNotFound, "User not found")
+        return
+    }
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to update notification settings")
+        return
+    }
+    response.Updated(c, dto.NewNotificationSettings(profile), "Notification settings updated successfully")
+}
+
+// Unsubscribe turns off the emails of the token from an unsubscribe link.
+// Mail clients call it directly for one-click unsubscribing (RFC 8058), the
+// frontend after the user confirmed; no login is needed.
+func Unsubscribe(c *gin.Context, profiles services.ProfileService, key string) {
+    userID, category, err := emails.ParseUnsubscribeToken(key, c.Query("token"))
+    if err != nil {
+        response.Fail(c, http.StatusBadRequest, "Invalid unsubscribe link")
+        return
+    }
+
+    off := false
+    var changes services.NotificationChanges
+    switch category {
+    case emails.CategoryEnrollments:
+        changes.Enrollments = &off
+    case emails.CategoryNewLessons:
+        changes.NewLessons = &off
+    case emails.CategoryQuizResults:
+        changes.QuizResults = &off
+    case emails.CategoryAll:
+        changes = services.NotificationChanges{Enrollments: &off, NewLessons: &off, QuizResults: &off}
+    }
+
+    profile, err := profiles.UpdateNotifications(c.Request.Context(), userID, changes)
+    if errors.Is(err, services.ErrNotFound) {
+        response.Fail(c, http.StatusNotFound, "User not found")
+        return
+    }
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to unsubscribe")
+        return
+    }
+    response.Updated(c, dto.NewNotificationSettings(profile), "You have been unsubscribed")
+}
+
+// GetNotifications lists the in-app notifications of the user, newest first.
+// Pass unread=true for unread notifications only.
+func GetNotifications(c *gin.Context, notifications services.NotificationService) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+    var query struct {
+        Unread bool `form:"unread"`
+    }
+    if !validation.BindQuery(c, &query) {
+        return
+    }
+    page, ok := pageParams(c)
+    if !ok {
+        return
+    }
+
+    list, total, err := notifications.List(c.Request.Context(), userID, query.Unread, page)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch notifications")
+        return
+    }
+    response.List(c, dto.NewNotifications(list), listMeta(page, total))
+}
+
+// MarkNotificationRead marks a notification of the user as read
+func MarkNotificationRead(c *gin.Context, notifications services.NotificationService) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+    id, ok := paramID(c, "id", "Invalid notification ID")
+    if !ok {
+        return
+    }
+
+    notification, err := notifications.MarkRead(c.Request.Context(), userID, id)
+    if errors.Is(err, services.ErrNotFound) {
+        response.Fail(c, http.StatusNotFound, "Notification not found")
+        return
+    }
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to update notification")
+        return
+    }
+    response.Updated(c, dto.NewNotification(notification), "Notification marked as read")
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user as read
+func MarkAllNotificationsRead(c *gin.Context, notifications services.NotificationService) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    count, err := notifications.MarkAllRead(c.Request.Context(), userID)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to update notifications")
+        return
+    }
+    response.Message(c, fmt.Sprintf("%d notifications marked as read", count))
+}
+
+// CreateStreamToken issues a short-lived token for opening the notification
+// stream with EventSource, which cannot send the Authorization header
+func CreateStreamToken(c *gin.Context) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    token, expires, err := auth.GenerateStreamToken(userID)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to generate stream token")
+        return
+    }
+    response.OK(c, dto.StreamToken{Token: token, ExpiresAt: expires})
+}
+
+// catchUpBatch is the page size used to send the notifications a resuming
+// stream missed
+const catchUpBatch = 100
+
+// StreamNotifications sends the new notifications of the user as Server-Sent
+// Events until the client disconnects. Clients reconnecting with
+// Last-Event-ID first receive the notifications they missed. A comment is
+// sent every keepAlive so proxies keep the idle connection open.
+func StreamNotifications(c *gin.Context, notifications services.NotificationService, keepAlive time.Duration) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+    // EventSource baru tidak bisa mengirim header, jadi juga lewat query
+    lastEventID := c.GetHeader("Last-Event-ID")
+    if lastEventID == "" {
+        lastEventID = c.Query("last_event_id")
+    }
+    var lastID uint
+    if lastEventID != "" {
+        id, err := strconv.ParseUint(lastEventID, 10, 32)
+        if err != nil {
+            response.Fail(c, http.StatusBadRequest, "Invalid Last-Event-ID")
+            return
+        }
+        lastID = uint(id)
+    }
+
+    // Berlangganan dulu agar tidak ada notifikasi yang terlewat di antara
+    // catch-up dan stream
+    stream, unsubscribe := notifications.Stream(userID)
+    defer unsubscribe()
+
+    var missed []models.Notification
+    if lastID > 0 {
+        var err error
+        missed, err = notifications.Since(c.Request.Context(), userID, lastID, catchUpBatch)
+        if err != nil {
+            response.Fail(c, http.StatusInternalServerError, "Failed to fetch notifications")
+            return
+        }
+    }
+    // Notifikasi dari catch-up bisa muncul lagi di stream
+    caughtUp := make(map[uint]bool, len(missed))
+
+    header := c.Writer.Header()
+    header.Set("Content-Type", "text/event-stream")
+    header.Set("Cache-Control", "no-cache")
+    header.Set("X-Accel-Buffering", "no") // Nginx tidak boleh menahan event
+    c.Status(http.StatusOK)
+
+    // ID event adalah ID tertinggi yang sudah dikirim. Notifikasi yang
+    // commit terlambat bisa punya ID lebih kecil, Last-Event-ID tetap naik.
+    send := func(notification models.Notification) bool {
+        if caughtUp[notification.ID] {
+            return true // Sudah dikirim saat catch-up
+        }
+        if notification.ID > lastID {
+            lastID = notification.ID
+        }
+        data, err := json.Marshal(dto.NewNotification(notification))
+        if err != nil {
+            return false
+        }
+        _, err = fmt.Fprintf(c.Writer, "id: %d\nevent: notification\ndata: %s\n\n", lastID, data)
+        return err == nil
+    }
+    // Catch-up per halaman sampai halaman terakhir tidak penuh
+    for len(missed) > 0 {
+        for _, notification := range missed {
+            if !send(notification) {
+                return
+            }
+            caughtUp[notification.ID] = true
+        }
+        if len(missed) < catchUpBatch {
+            break
+        }
+        var err error
+        missed, err = notifications.Since(c.Request.Context(), userID, lastID, catchUpBatch)
+        if err != nil {
+            // Header sudah terkirim; klien menyambung lagi dengan Last-Event-ID
+            slog.ErrorContext(c.Request.Context(), "Failed to fetch missed notifications", "error", err)
+            return
+        }
+    }
+    // Header dikirim segera, juga tanpa notifikasi
+    c.Writer.Flush()
+
+    ticker := time.NewTicker(keepAlive)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-c.Request.Context().Done():
+            return
+        case notification, open := <-stream:
+            if !open {
+                return // Server berhenti
+            }
+            if !send(notification) {
+                return
+            }
+        case <-ticker.C:
+            if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+                return
+            }
+        }
+        c.Writer.Flush()
+    }
+}
@@ -1,193 +1,85 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"go-learn-platform/internal/dto"
 	"go-learn-platform/internal/middleware"
-	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // GetMyProfile retrieves the profile of the currently authenticated user
-func GetMyProfile(c *gin.Context, db *gorm.DB) {
+func GetMyProfile(c *gin.Context, profiles services.ProfileService) {
     userID := c.MustGet("userID").(uint)
 
-    var user models.User
-    result := db.
-        Preload("Profile").
-        Preload("Courses").
-        Preload("Enrollments.Course").
-        First(&user, userID)
-    if result.Error != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+    user, err := profiles.Get(c.Request.Context(), userID)
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "User not found")
         return
     }
 
-    imageURL := ""
-    if user.Profile.Image != "" {
-        imageURL = fmt.Sprintf("http://localhost:8080%s", user.Profile.Image)
-    }
-
-    createdCourses := make([]gin.H, 0)
-    for _, course := range user.Courses {
-        courseImage := ""
-        if course.Image != "" {
-            courseImage = fmt.Sprintf("http://localhost:8080%s", course.Image)
-        }
-
-        createdCourses = append(createdCourses, gin.H{
-            "id":          course.ID,
-            "title":       course.Title,
-            "description": course.Description,
-            "image":       courseImage,
-        })
-    }
-
-    enrolledCourses := make([]gin.H, 0)
-    for _, enrollment := range user.Enrollments {
-        courseImage := ""
-        if enrollment.Course.Image != "" {
-            courseImage = fmt.Sprintf("http://localhost:8080%s", enrollment.Course.Image)
-        }
-
-        enrolledCourses = append(enrolledCourses, gin.H{
-            "id":          enrollment.Course.ID,
-            "title":       enrollment.Course.Title,
-            "description": enrollment.Course.Description,
-            "image":       courseImage,
-            "progress":    enrollment.Progress,
-        })
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "id":    user.ID,
-        "email": user.Email,
-        "profile": gin.H{
-            "name":  user.Profile.Name,
-            "image": imageURL,
-        },
-        "created_courses":  createdCourses,
-        "enrolled_courses": enrolledCourses,
-    })
+    response.OK(c, dto.NewUser(user, true))
 }
 
 
-// GetProfile retrieves the profile of a user by their ID
-func GetProfile(c *gin.Context, db *gorm.DB) {
-    userID := c.Param("id")
-
-    var user models.User
-    result := db.
-        Preload("Profile").
-        Preload("Courses").
-        Preload("Enrollments.Course").
-        First(&user, userID)
-    if result.Error != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+// GetProfile retrieves the public profile of a user by their ID
+func GetProfile(c *gin.Context, profiles services.ProfileService) {
+    userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "User not found")
         return
     }
 
-    imageURL := ""
-    if user.Profile.Image != "" {
-        imageURL = fmt.Sprintf("http://localhost:8080%s", user.Profile.Image)
-    }
-
-    createdCourses := make([]gin.H, 0)
-    for _, course := range user.Courses {
-        createdCourses = append(createdCourses, gin.H{
-            "id":          course.ID,
-            "title":       course.Title,
-            "description": course.Description,
-            "image":       course.Image,
-        })
-    }
-
-    enrolledCourses := make([]gin.H, 0)
-    for _, enrollment := range user.Enrollments {
-        enrolledCourses = append(enrolledCourses, gin.H{
-            "id":          enrollment.Course.ID,
-            "title":       enrollment.Course.Title,
-            "description": enrollment.Course.Description,
-            "image":       enrollment.Course.Image,
-            "progress":    enrollment.Progress,
-        })
+    user, err := profiles.Public(c.Request.Context(), uint(userID))
+    if err != nil {
+        response.Fail(c, http.StatusNotFound, "User not found")
+        return
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "id":    user.ID,
-        "email": user.Email,
-        "profile": gin.H{
-            "name":  user.Profile.Name,
-            "image": imageURL,
-        },
-        "created_courses":  createdCourses,
-        "enrolled_courses": enrolledCourses,
-    })
+    response.OK(c, dto.NewUser(user, false))
 }
 
 
-func UpdateProfile(c *gin.Context, db *gorm.DB) {
+func UpdateProfile(c *gin.Context, profiles services.ProfileService) {
     userID, exists := c.Get("userID")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+        response.Fail(c, http.StatusUnauthorized, "User ID not found in context")
         return
     }
 
 
-    // Ambil field name dari form
-    name := c.PostForm("name")
+    var input struct {
+        Name string `form:"name" binding:"required,min=2,max=100,personname"`
+    }
+    if !validation.BindForm(c, &input) {
+        return
+    }
+    name := strings.TrimSpace(input.Name)
 
     // Upload file image
     imageURL, err := middleware.UploadFile(c, "image")
-    if err != nil && err.Error() != "http: no such file" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if err != nil && err.Error() != "failed to retrieve file: http: no such file" {
+        response.Fail(c, http.StatusBadRequest, err.Error())
         return
     }
 
-    // Temukan user dan preload Profile
-    var user models.User
-    result := db.Preload("Profile").First(&user, userID)
-    if result.Error != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+    // Profil dibuat jika belum ada
+    user, err := profiles.Update(c.Request.Context(), userID.(uint), services.ProfileChanges{Name: name, Image: imageURL})
+    if errors.Is(err, services.ErrNotFound) {
+        response.Fail(c, http.StatusNotFound, "User not found")
         return
     }
-
-    // Jika profil belum ada, buat baru
-    if user.Profile.UserID == 0 {
-        newProfile := models.Profile{
-            UserID: user.ID,
-            Name:   name,
-            Image:  imageURL,
-        }
-        if err := db.Create(&newProfile).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create profile"})
-            return
-        }
-        user.Profile = newProfile
-    } else {
-        // Update profil yang ada
-        user.Profile.Name = name
-        if imageURL != "" {
-            user.Profile.Image = imageURL
-        }
-        if err := db.Save(&user.Profile).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
-            return
-        }
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to update profile")
+        return
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Profile updated successfully",
-        "user": gin.H{
-            "id":    user.ID,
-            "email": user.Email,
-            "profile": gin.H{
-                "name":  user.Profile.Name,
-                "image": user.Profile.Image,
-            },
-        },
-    })
-}
\ No newline at end of file
+    response.Updated(c, dto.NewUser(user, true), "Profile updated successfully")
+}
@@ -1,45 +1,49 @@
 package controllers
 
 import (
+    "errors"
     "net/http"
-    "strconv"
 
+    "go-learn-platform/internal/dto"
     "go-learn-platform/internal/models"
+    "go-learn-platform/internal/pkg/response"
+    "go-learn-platform/internal/pkg/validation"
+    "go-learn-platform/internal/services"
     "github.com/gin-gonic/gin"
-    "gorm.io/gorm"
 )
 
 
-// GetAllQuizzes retrieves all quizzes from the database
-func GetAllQuizzes(c *gin.Context, db *gorm.DB) {
-    var quizzes []models.Quiz
-    if err := db.Preload("Lesson").Find(&quizzes).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quizzes"})
+// scoreInput is the body of CompleteQuiz. Score is a pointer so that 0 is a
+// valid score.
+type scoreInput struct {
+    Score *int `json:"score" binding:"required,min=0,max=100"`
+}
+
+// GetAllQuizzes retrieves a page of quizzes
+func GetAllQuizzes(c *gin.Context, quizzes services.QuizService) {
+    page, ok := pageParams(c)
+    if !ok {
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"quizzes": quizzes})
+    list, total, err := quizzes.List(c.Request.Context(), page)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch quizzes")
+        return
+    }
+
+    response.List(c, dto.NewQuizzes(list), listMeta(page, total))
 }
 
 // CreateQuiz creates a new quiz in the database
-func CreateQuiz(c *gin.Context, db *gorm.DB) {
+func CreateQuiz(c *gin.Context, quizzes services.QuizService) {
     var input struct {
-        LessonID uint   `json:"lesson_id" binding:"required"`
-        Question string `json:"question" binding:"required"`
-        Options  string `json:"options" binding:"required"`
-        Answer   string `json:"answer" binding:"required"`
+        LessonID uint   `json:"lesson_id" binding:"required,exists=lesson"`
+        Question string `json:"question" binding:"required,notblank,max=1000"`
+        Options  string `json:"options" binding:"required,notblank,max=2000"`
+        Answer   string `json:"answer" binding:"required,notblank,max=500"`
     }
-
-    // Validasi input
-    if err := c.ShouldBindJSON(&input); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-        return
-    }
-
-    // Cek apakah lesson ada
-    var lesson models.Lesson
-    if err := db.First(&lesson, input.LessonID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+    if !validation.BindJSON(c, &input) {
         return
     }
 
@@ -50,101 +54,79 @@ func CreateQuiz(c *gin.Context, db *gorm.DB) {
         Options:  input.Options,
         Answer:   input.Answer,
     }
-    if err := db.Create(&quiz).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quiz"})
+    err := quizzes.Create(c.Request.Context(), &quiz)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Lesson not found")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to create quiz")
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Quiz created successfully",
-        "quiz":    quiz,
-    })
+    response.Created(c, dto.NewQuiz(quiz), "Quiz created successfully")
 }
 
 // DeleteQuiz deletes a quiz by ID
-func DeleteQuiz(c *gin.Context, db *gorm.DB) {
-    quizID, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+func DeleteQuiz(c *gin.Context, quizzes services.QuizService) {
+    quizID, ok := paramID(c, "id", "Invalid quiz ID")
+    if !ok {
         return
     }
 
-    var quiz models.Quiz
-    if err := db.First(&quiz, quizID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+    err := quizzes.Delete(c.Request.Context(), quizID)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Quiz not found")
         return
-    }
-
-    // Hapus quiz
-    if err := db.Delete(&quiz).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quiz"})
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to delete quiz")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Quiz deleted successfully"})
+    response.Message(c, "Quiz deleted successfully")
 }
 
 // CompleteQuiz marks a quiz as completed and updates the course progress
-func CompleteQuiz(c *gin.Context, db *gorm.DB) {
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
-        return
-    }
-
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+func CompleteQuiz(c *gin.Context, quizzes services.QuizService) {
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
     // Ambil quiz_id dari parameter URL
-    quizID, err := strconv.Atoi(c.Param("quiz_id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
-        return
-    }
-
-    // Cek apakah quiz ada dan muat data Lesson terkait
-    var quiz models.Quiz
-    if err := db.Preload("Lesson").First(&quiz, quizID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
-        return
-    }
-
-    // Cek apakah pengguna sudah menyelesaikan quiz ini
-    var existingResult models.QuizResult
-    if err := db.Where("user_id = ? AND quiz_id = ?", userIDUint, quizID).First(&existingResult).Error; err == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have already completed this quiz"})
+    quizID, ok := paramID(c, "quiz_id", "Invalid quiz ID")
+    if !ok {
         return
     }
 
-    // Simpan hasil quiz baru
-    var input struct {
-        Score int `json:"score" binding:"required"`
-    }
-    if err := c.ShouldBindJSON(&input); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+    var input scoreInput
+    if !validation.BindJSON(c, &input) {
         return
     }
 
-    result := models.QuizResult{
-        UserID: userIDUint,
-        QuizID: uint(quizID),
-        Score:  input.Score,
-    }
-    if err := db.Create(&result).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save quiz result"})
+    result, err := quizzes.Complete(c.Request.Context(), userID, quizID, *input.Score)
+    if completeFailed(c, err) {
         return
     }
 
-    // Perbarui progress kursus
-    if err := UpdateCourseProgress(db, userIDUint, quiz.Lesson.CourseID); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course progress"})
-        return
-    }
+    response.Updated(c, dto.NewQuizResult(result), "Quiz completed successfully")
+}
 
-    c.JSON(http.StatusOK, gin.H{"message": "Quiz completed successfully", "result": result})
-}
\ No newline at end of file
+// completeFailed answers the errors of QuizService.Complete and reports
+// whether there was one
+func completeFailed(c *gin.Context, err error) bool {
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Quiz not found")
+    case errors.Is(err, services.ErrAlreadyCompleted):
+        response.FailCode(c, http.StatusBadRequest, response.CodeAlreadyCompleted, "You have already completed this quiz")
+    case errors.Is(err, services.ErrNotEnrolled):
+        response.FailCode(c, http.StatusForbidden, response.CodeNotEnrolled, "User is not enrolled in this course")
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to save quiz result")
+    default:
+        return false
+    }
+    return true
+}
@@ -1,115 +1,81 @@
 package controllers
 
 import (
+    "errors"
     "net/http"
-    "strconv"
 
-    "go-learn-platform/internal/models"
+    "go-learn-platform/internal/dto"
+    "go-learn-platform/internal/pkg/response"
+    "go-learn-platform/internal/pkg/validation"
+    "go-learn-platform/internal/services"
     "github.com/gin-gonic/gin"
-    "gorm.io/gorm"
 )
 
-// GetQuizResults retrieves all quiz results from the database
-func GetQuizResults(c *gin.Context, db *gorm.DB) {
-    var results []models.QuizResult
-    if err := db.Preload("Quiz").Find(&results).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz results"})
+// GetQuizResults retrieves a page of quiz results
+func GetQuizResults(c *gin.Context, quizzes services.QuizService) {
+    page, ok := pageParams(c)
+    if !ok {
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"quiz_results": results})
+    results, total, err := quizzes.ListResults(c.Request.Context(), page)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch quiz results")
+        return
+    }
+
+    response.List(c, dto.NewQuizResults(results), listMeta(page, total))
 }
 
-// CreateQuizResult creates a new quiz result in the database
-func CreateQuizResult(c *gin.Context, db *gorm.DB) {
+// CreateQuizResult completes a quiz like CompleteQuiz, with the quiz ID in
+// the body
+func CreateQuizResult(c *gin.Context, quizzes services.QuizService) {
     var input struct {
-        QuizID uint `json:"quiz_id" binding:"required"`
-        Score  int  `json:"score" binding:"required"`
+        QuizID uint `json:"quiz_id" binding:"required,exists=quiz"`
+        Score  *int `json:"score" binding:"required,min=0,max=100"`
     }
-
-    // Validasi input
-    if err := c.ShouldBindJSON(&input); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+    if !validation.BindJSON(c, &input) {
         return
     }
 
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
-        return
-    }
-
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
-        return
-    }
-
-    // Cek apakah quiz ada
-    var quiz models.Quiz
-    if err := db.First(&quiz, input.QuizID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
         return
     }
 
-    // Buat hasil quiz baru
-    result := models.QuizResult{
-        UserID: userIDUint,
-        QuizID: input.QuizID,
-        Score:  input.Score,
-    }
-    if err := db.Create(&result).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quiz result"})
+    // Sama dengan menyelesaikan quiz: hanya peserta, sekali, dengan event
+    result, err := quizzes.Complete(c.Request.Context(), userID, input.QuizID, *input.Score)
+    if completeFailed(c, err) {
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Quiz result created successfully",
-        "result":  result,
-    })
+    response.Created(c, dto.NewQuizResult(result), "Quiz result created successfully")
 }
 
 // DeleteQuizResult deletes a quiz result by ID
-func DeleteQuizResult(c *gin.Context, db *gorm.DB) {
-    resultID, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz result ID"})
-        return
-    }
-
-    var result models.QuizResult
-    if err := db.First(&result, resultID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Quiz result not found"})
-        return
-    }
-
-    // Ambil user_id dari context (diatur oleh middleware)
-    userID, exists := c.Get("userID")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+func DeleteQuizResult(c *gin.Context, quizzes services.QuizService) {
+    resultID, ok := paramID(c, "id", "Invalid quiz result ID")
+    if !ok {
         return
     }
 
-    // Pastikan userID adalah uint
-    userIDUint, ok := userID.(uint)
+    userID, ok := currentUserID(c)
     if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user ID"})
         return
     }
 
-    // Verifikasi bahwa pengguna adalah pemilik hasil quiz
-    if result.UserID != userIDUint {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this quiz result"})
+    err := quizzes.DeleteResult(c.Request.Context(), userID, resultID)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Quiz result not found")
         return
-    }
-
-    // Hapus hasil quiz
-    if err := db.Delete(&result).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quiz result"})
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to delete this quiz result")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to delete quiz result")
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Quiz result deleted successfully"})
-}
\ No newline at end of file
+    response.Message(c, "Quiz result deleted successfully")
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPerPage is the page size of list endpoints without per_page
+const defaultPerPage = 20
+
+// currentUserID returns the ID of the user set by AuthMiddleware. It answers
+// 401 and returns false when the request is not authenticated.
+func currentUserID(c *gin.Context) (uint, bool) {
+    userID, ok := c.Get("userID")
+    if ok {
+        if id, isUint := userID.(uint); isUint {
+            return id, true
+        }
+    }
+
+    response.Fail(c, http.StatusUnauthorized, "User ID not found in context")
+    return 0, false
+}
+
+// currentActor returns the user set by AuthMiddleware together with their
+// role, like currentUserID
+func currentActor(c *gin.Context) (services.Actor, bool) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return services.Actor{}, false
+    }
+    return services.Actor{UserID: userID, Admin: c.GetString("userRole") == models.RoleAdmin}, true
+}
+
+// paramID parses a numeric URL parameter. It answers 400 with the given
+// message and returns false when the parameter is not a valid ID.
+func paramID(c *gin.Context, name, message string) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param(name), 10, 32)
+    if err != nil {
+        response.Fail(c, http.StatusBadRequest, message)
+        return 0, false
+    }
+    return uint(id), true
+}
+
+// pageParams reads the page and per_page query parameters of list endpoints.
+// It answers 400 and returns false when they are out of range.
+func pageParams(c *gin.Context) (services.Page, bool) {
+    var query struct {
+        Page    *int `form:"page" binding:"omitempty,min=1"`
+        PerPage *int `form:"per_page" binding:"omitempty,min=1,max=100"`
+    }
+    if !validation.BindQuery(c, &query) {
+        return services.Page{}, false
+    }
+
+    page := services.Page{Number: 1, Size: defaultPerPage}
+    if query.Page != nil {
+        page.Number = *query.Page
+    }
+    if query.PerPage != nil {
+        page.Size = *query.PerPage
+    }
+    return page, true
+}
+
+// listMeta builds the pagination meta of a page
+func listMeta(page services.Page, total int64) *response.Meta {
+    return response.NewMeta(page.Number, page.Size, total)
+}
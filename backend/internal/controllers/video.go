@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/media"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/tracing"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreateUpload starts a resumable upload of a video for a lesson
+func CreateUpload(c *gin.Context, uploads services.UploadService) {
+    // Tipe dan ukuran video dicek terpisah karena dijawab dengan 415 dan 413
+    var input struct {
+        LessonID    uint    `json:"lesson_id" binding:"required,exists=lesson"`
+        Filename    string  `json:"filename" binding:"required,notblank,max=255"`
+        ContentType string  `json:"content_type" binding:"required"`
+        Size        int64   `json:"size" binding:"required"`
+        Duration    float64 `json:"duration" binding:"min=0"`
+        Checksum    string  `json:"checksum" binding:"omitempty,len=64,hexadecimal"`
+    }
+    if !validation.BindJSON(c, &input) {
+        return
+    }
+
+    userIDUint, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    session, err := uploads.Create(c.Request.Context(), userIDUint, services.UploadInput{
+        LessonID:    input.LessonID,
+        Filename:    input.Filename,
+        ContentType: input.ContentType,
+        Size:        input.Size,
+        Duration:    input.Duration,
+        Checksum:    input.Checksum,
+    })
+    switch {
+    case errors.Is(err, services.ErrUnsupportedVideo):
+        response.Fail(c, http.StatusUnsupportedMediaType, "Unsupported video type")
+        return
+    case errors.Is(err, services.ErrVideoTooLarge):
+        response.Fail(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Video size must be between 1 and %d bytes", media.MaxVideoSize()))
+        return
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Lesson not found")
+        return
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to upload a video for this lesson")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to create upload")
+        return
+    }
+
+    c.Header("Location", "/uploads/"+session.ID)
+    c.Header("Upload-Offset", "0")
+    c.Header("Upload-Length", strconv.FormatInt(session.Size, 10))
+    response.Created(c, dto.NewUpload(session), "Upload created successfully")
+}
+
+// GetUpload returns the state of an upload so the client can resume it.
+// A HEAD request only returns the Upload-Offset and Upload-Length headers.
+func GetUpload(c *gin.Context, uploads services.UploadService) {
+    session, ok := loadUploadSession(c, uploads)
+    if !ok {
+        return
+    }
+
+    c.Header("Cache-Control", "no-store")
+    c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+    c.Header("Upload-Length", strconv.FormatInt(session.Size, 10))
+    if c.Request.Method == http.MethodHead {
+        c.Status(http.StatusOK)
+        return
+    }
+
+    response.OK(c, dto.NewUpload(session))
+}
+
+// PatchUpload appends a chunk to an upload. The request must carry the current
+// offset in the Upload-Offset header and may carry an Upload-Checksum header
+// ("sha256 <base64 digest>") that is verified before the chunk is accepted.
+func PatchUpload(c *gin.Context, uploads services.UploadService) {
+    userIDUint, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+    if err != nil || offset < 0 {
+        response.Fail(c, http.StatusBadRequest, "Invalid Upload-Offset header")
+        return
+    }
+
+    var checksum []byte
+    if header := c.GetHeader("Upload-Checksum"); header != "" {
+        algorithm, digest, found := strings.Cut(header, " ")
+        if !found || !strings.EqualFold(algorithm, "sha256") {
+            response.Fail(c, http.StatusBadRequest, "Only sha256 checksums are supported")
+            return
+        }
+        checksum, err = base64.StdEncoding.DecodeString(digest)
+        if err != nil || len(checksum) != sha256.Size {
+            response.Fail(c, http.StatusBadRequest, "Invalid Upload-Checksum header")
+            return
+        }
+    }
+
+    body := http.MaxBytesReader(c.Writer, c.Request.Body, media.MaxChunkSize()+1)
+    session, err := uploads.Append(c.Request.Context(), userIDUint, c.Param("id"), services.Chunk{
+        Offset:   offset,
+        Body:     body,
+        Checksum: checksum,
+    })
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Upload not found")
+        return
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to access this upload")
+        return
+    case errors.Is(err, services.ErrUploadCompleted):
+        response.Fail(c, http.StatusConflict, "Upload is already completed")
+        return
+    case errors.Is(err, services.ErrOffsetMismatch):
+        c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+        response.FailCode(c, http.StatusConflict, response.CodeOffsetMismatch, "Upload-Offset does not match the current offset")
+        return
+    case errors.Is(err, services.ErrBadChunk):
+        response.Fail(c, http.StatusBadRequest, "Failed to write chunk: "+err.Error())
+        return
+    case errors.Is(err, services.ErrChunkChecksum):
+        response.FailCode(c, http.StatusBadRequest, response.CodeChecksumMismatch, "Chunk checksum mismatch")
+        return
+    case errors.Is(err, services.ErrUploadReset):
+        c.Header("Upload-Offset", "0")
+        response.FailCode(c, http.StatusUnprocessableEntity, response.CodeChecksumMismatch, "Upload checksum mismatch, the upload has been reset")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to save upload progress")
+        return
+    }
+
+    c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+    c.Status(http.StatusNoContent)
+}
+
+// DeleteUpload aborts an unfinished upload and removes its partial data
+func DeleteUpload(c *gin.Context, uploads services.UploadService) {
+    userIDUint, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    err := uploads.Delete(c.Request.Context(), userIDUint, c.Param("id"))
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Upload not found")
+        return
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to access this upload")
+        return
+    case errors.Is(err, services.ErrUploadCompleted):
+        response.Fail(c, http.StatusConflict, "Upload is already completed")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to delete upload")
+        return
+    }
+
+    response.Message(c, "Upload deleted successfully")
+}
+
+// StreamLessonVideo streams the video of a lesson, honouring HTTP range requests
+func StreamLessonVideo(c *gin.Context, courses services.CourseService) {
+    lessonID, ok := paramID(c, "id", "Invalid lesson ID")
+    if !ok {
+        return
+    }
+
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    lesson, course, err := courses.GetLesson(c.Request.Context(), lessonID)
+    if err != nil || lesson.Video == nil {
+        response.Fail(c, http.StatusNotFound, "Video not found")
+        return
+    }
+
+    allowed, err := courses.CanAccess(c.Request.Context(), userID, course)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to check enrollment")
+        return
+    }
+    if !allowed {
+        response.FailCode(c, http.StatusForbidden, response.CodeNotEnrolled, "You are not enrolled in this course")
+        return
+    }
+
+    serveVideoFile(c, lesson.Video)
+}
+
+// VideoHeartbeat records the playback position of a lesson video. Only time
+// that was actually played between two heartbeats counts as watched, and the
+// lesson is marked completed once enough of the video has been watched.
+func VideoHeartbeat(c *gin.Context, progress services.ProgressService) {
+    lessonID, ok := paramID(c, "id", "Invalid lesson ID")
+    if !ok {
+        return
+    }
+
+    var input struct {
+        Position *float64 `json:"position" binding:"required,min=0"`
+    }
+    if !validation.BindJSON(c, &input) {
+        return
+    }
+
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
+    heartbeat, err := progress.RecordHeartbeat(c.Request.Context(), userID, lessonID, *input.Position)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Video not found")
+        return
+    case errors.Is(err, services.ErrNotEnrolled):
+        response.FailCode(c, http.StatusForbidden, response.CodeNotEnrolled, "User is not enrolled in this course")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to save progress")
+        return
+    }
+
+    response.OK(c, dto.NewHeartbeat(heartbeat))
+}
+
+// loadUploadSession fetches the upload from the URL and checks it belongs to the caller
+func loadUploadSession(c *gin.Context, uploads services.UploadService) (models.UploadSession, bool) {
+    userIDUint, ok := currentUserID(c)
+    if !ok {
+        return models.UploadSession{}, false
+    }
+
+    session, err := uploads.Get(c.Request.Context(), userIDUint, c.Param("id"))
+    switch {
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to access this upload")
+        return session, false
+    case err != nil:
+        response.Fail(c, http.StatusNotFound, "Upload not found")
+        return session, false
+    }
+    return session, true
+}
+
+// serveVideoFile writes a stored video using http.ServeContent, which handles
+// Range, If-Range and conditional requests for seeking in the player
+func serveVideoFile(c *gin.Context, video *models.LessonVideo) {
+    _, span := tracing.Start(c.Request.Context(), "storage.read",
+        attribute.String("storage.area", "videos"),
+        attribute.String("http.range", c.GetHeader("Range")))
+
+    file, err := os.Open(video.Path)
+    if err != nil {
+        tracing.End(span, err)
+        response.Fail(c, http.StatusNotFound, "Video not found")
+        return
+    }
+    defer file.Close()
+
+    c.Header("Content-Type", video.ContentType)
+    c.Header("Cache-Control", "private, max-age=3600")
+    http.ServeContent(c.Writer, c.Request, filepath.Base(video.Path), video.UpdatedAt, file)
+    span.End()
+}
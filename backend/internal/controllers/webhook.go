@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhookInput is the body of the create and update webhook endpoints
+type webhookInput struct {
+    URL      string   `json:"url" binding:"required,http_url,max=2048"`
+    Events   []string `json:"events" binding:"required,min=1,dive,oneof=enrollment.created enrollment.cancelled quiz.submitted course.completed"`
+    CourseID *uint    `json:"course_id" binding:"omitempty,min=1"`
+    Active   *bool    `json:"active"`
+}
+
+// GetWebhooks lists the webhooks of the user; admins see all webhooks
+func GetWebhooks(c *gin.Context, hooks services.WebhookService) {
+    actor, ok := currentActor(c)
+    if !ok {
+        return
+    }
+    page, ok := pageParams(c)
+    if !ok {
+        return
+    }
+
+    list, total, err := hooks.List(c.Request.Context(), actor, page)
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to fetch webhooks")
+        return
+    }
+
+    response.List(c, dto.NewWebhooks(list), listMeta(page, total))
+}
+
+// CreateWebhook registers a webhook for a course of the user, or for all
+// courses when an admin leaves out course_id. The response contains the
+// signing secret, which is not shown again.
+func CreateWebhook(c *gin.Context, hooks services.WebhookService) {
+    actor, ok := currentActor(c)
+    if !ok {
+        return
+    }
+    var input webhookInput
+    if !validation.BindJSON(c, &input) {
+        return
+    }
+
+    hook, err := hooks.Create(c.Request.Context(), actor, services.WebhookInput{
+        URL:      input.URL,
+        Events:   input.Events,
+        CourseID: input.CourseID,
+        Active:   input.Active,
+    })
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Course not found")
+        return
+    case errors.Is(err, services.ErrForbidden) && input.CourseID == nil:
+        response.Fail(c, http.StatusForbidden, "Only admins can register webhooks for all courses")
+        return
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to register webhooks for this course")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to create webhook")
+        return
+    }
+
+    body := dto.NewWebhook(hook)
+    body.Secret = hook.Secret
+    response.Created(c, body, "Webhook created successfully")
+}
+
+// GetWebhook shows a webhook
+func GetWebhook(c *gin.Context, hooks services.WebhookService) {
+    actor, id, ok := webhookParams(c)
+    if !ok {
+        return
+    }
+
+    hook, err := hooks.Get(c.Request.Context(), actor, id)
+    if webhookError(c, err, "Failed to fetch webhook") {
+        return
+    }
+    response.OK(c, dto.NewWebhook(hook))
+}
+
+// UpdateWebhook changes the URL, events and active flag of a webhook
+func UpdateWebhook(c *gin.Context, hooks services.WebhookService) {
+    actor, id, ok := webhookParams(c)
+    if !ok {
+        return
+    }
+    var input webhookInput
+    if !validation.BindJSON(c, &input) {
+        return
+    }
+
+    hook, err := hooks.Update(c.Request.Context(), actor, id, services.WebhookInput{
+        URL:    input.URL,
+        Events: input.Events,
+        Active: input.Active,
+    })
+    if webhookError(c, err, "Failed to update webhook") {
+        return
+    }
+    response.Updated(c, dto.NewWebhook(hook), "Webhook updated successfully")
+}
+
+// DeleteWebhook deletes a webhook; pending deliveries are dropped
+func DeleteWebhook(c *gin.Context, hooks services.WebhookService) {
+    actor, id, ok := webhookParams(c)
+    if !ok {
+        return
+    }
+
+    err := hooks.Delete(c.Request.Context(), actor, id)
+    if webhookError(c, err, "Failed to delete webhook") {
+        return
+    }
+    response.Message(c, "Webhook deleted successfully")
+}
+
+// GetWebhookDeliveries lists the delivery log of a webhook, newest first
+func GetWebhookDeliveries(c *gin.Context, hooks services.WebhookService) {
+    actor, id, ok := webhookParams(c)
+    if !ok {
+        return
+    }
+    page, ok := pageParams(c)
+    if !ok {
+        return
+    }
+
+    list, total, err := hooks.Deliveries(c.Request.Context(), actor, id, page)
+    if webhookError(c, err, "Failed to fetch deliveries") {
+        return
+    }
+    response.List(c, dto.NewWebhookDeliveries(list), listMeta(page, total))
+}
+
+// RedeliverWebhook sends the payload of a delivery again
+func RedeliverWebhook(c *gin.Context, hooks services.WebhookService) {
+    actor, id, ok := webhookParams(c)
+    if !ok {
+        return
+    }
+    deliveryID, ok := paramID(c, "delivery_id", "Invalid delivery ID")
+    if !ok {
+        return
+    }
+
+    delivery, err := hooks.Redeliver(c.Request.Context(), actor, id, deliveryID)
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Delivery not found")
+        return
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to manage this webhook")
+        return
+    case errors.Is(err, services.ErrWebhookDisabled):
+        response.Fail(c, http.StatusConflict, "Webhook is disabled")
+        return
+    case err != nil:
+        response.Fail(c, http.StatusInternalServerError, "Failed to redeliver")
+        return
+    }
+
+    response.Created(c, dto.NewWebhookDelivery(delivery), "Delivery scheduled")
+}
+
+// webhookParams reads the current user and the webhook ID of the URL
+func webhookParams(c *gin.Context) (services.Actor, uint, bool) {
+    id, ok := paramID(c, "id", "Invalid webhook ID")
+    if !ok {
+        return services.Actor{}, 0, false
+    }
+    actor, ok := currentActor(c)
+    return actor, id, ok
+}
+
+// webhookError answers the errors shared by the webhook endpoints and
+// reports whether there was one
+func webhookError(c *gin.Context, err error, message string) bool {
+    switch {
+    case err == nil:
+        return false
+    case errors.Is(err, services.ErrNotFound):
+        response.Fail(c, http.StatusNotFound, "Webhook not found")
+    case errors.Is(err, services.ErrForbidden):
+        response.Fail(c, http.StatusForbidden, "You are not authorized to manage this webhook")
+    default:
+        response.Fail(c, http.StatusInternalServerError, message)
+    }
+    return true
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/tracing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Open connects to Postgres and applies the connection pool settings
+func Open(cfg *config.Config) (*gorm.DB, error) {
+    db, err := gorm.Open(postgres.Open(cfg.Database.DSN), &gorm.Config{
+        Logger: NewLogger(cfg.Log.SlowQuery),
+        // Error unik dari Postgres menjadi gorm.ErrDuplicatedKey
+        TranslateError: true,
+    })
+    if err != nil {
+        return nil, err
+    }
+    if err := db.Use(metrics.GormPlugin{}); err != nil {
+        return nil, err
+    }
+    if err := db.Use(tracing.GormPlugin{}); err != nil {
+        return nil, err
+    }
+
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+    sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+    sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+    sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+    sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+
+    return db, nil
+}
+
+// Close closes the underlying connection pool
+func Close(db *gorm.DB) {
+    if sqlDB, err := db.DB(); err == nil {
+        sqlDB.Close()
+    }
+}
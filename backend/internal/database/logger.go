@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// queryLogger sends GORM logs to slog with the context of the query, so SQL
+// errors and slow queries carry the request and user ID
+type queryLogger struct {
+    level     gormlogger.LogLevel
+    slowQuery time.Duration
+}
+
+// NewLogger creates a GORM logger. Failed queries are logged as errors,
+// queries slower than slowQuery as warnings and, at debug level, every query.
+func NewLogger(slowQuery time.Duration) gormlogger.Interface {
+    return &queryLogger{level: gormlogger.Info, slowQuery: slowQuery}
+}
+
+func (l *queryLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+    clone := *l
+    clone.level = level
+    return &clone
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+    if l.level >= gormlogger.Info {
+        slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+    }
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+    if l.level >= gormlogger.Warn {
+        slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+    }
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+    if l.level >= gormlogger.Error {
+        slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+    }
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+    if l.level <= gormlogger.Silent {
+        return
+    }
+
+    elapsed := time.Since(begin)
+    level := slog.LevelDebug
+    msg := "query"
+    switch {
+    // Record tidak ditemukan adalah hasil biasa, bukan error
+    case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+        level, msg = slog.LevelError, "query failed"
+    case l.slowQuery > 0 && elapsed > l.slowQuery && l.level >= gormlogger.Warn:
+        level, msg = slog.LevelWarn, "slow query"
+    }
+    if !slog.Default().Enabled(ctx, level) {
+        return
+    }
+
+    sql, rows := fc()
+    attrs := []slog.Attr{
+        slog.String("sql", sql),
+        slog.Int64("rows", rows),
+        slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+    }
+    if level == slog.LevelError {
+        attrs = append(attrs, slog.String("error", err.Error()))
+    }
+    slog.LogAttrs(ctx, level, msg, attrs...)
+}
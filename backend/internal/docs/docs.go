@@ -0,0 +1,62 @@
+// Package docs serves the OpenAPI specification of the API and a small
+// documentation UI on top of it.
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/urls"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+//go:embed index.html
+var indexHTML []byte
+
+// ContentSecurityPolicy allows the documentation page to load Swagger UI from
+// unpkg and the specification from the API
+const ContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; " +
+    "style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https:; frame-ancestors 'none'"
+
+var (
+    specOnce sync.Once
+    specJSON []byte
+    specErr  error
+)
+
+// Spec returns the OpenAPI document as JSON with the server URL taken from
+// the configured public API base
+func Spec() ([]byte, error) {
+    specOnce.Do(func() {
+        var doc map[string]interface{}
+        if specErr = yaml.Unmarshal(specYAML, &doc); specErr != nil {
+            return
+        }
+        doc["servers"] = []map[string]string{{"url": urls.Default().APIBase}}
+        specJSON, specErr = json.Marshal(doc)
+    })
+    return specJSON, specErr
+}
+
+// ServeSpec handles GET /openapi.json
+func ServeSpec(c *gin.Context) {
+    spec, err := Spec()
+    if err != nil {
+        response.Fail(c, http.StatusInternalServerError, "Failed to load API specification")
+        return
+    }
+    c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+// ServeUI handles GET /docs
+func ServeUI(c *gin.Context) {
+    c.Data(http.StatusOK, "text/html; charset=utf-8", indexHTML)
+}
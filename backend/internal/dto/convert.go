@@ -0,0 +1,335 @@
+package dto
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/urls"
+	"go-learn-platform/internal/services"
+)
+
+// NewUserSummary converts a user with a preloaded profile
+func NewUserSummary(user models.User) *UserSummary {
+    if user.ID == 0 {
+        return nil
+    }
+    return &UserSummary{ID: user.ID, Name: user.Profile.Name, Image: urls.Asset(user.Profile.Image)}
+}
+
+// NewCourse converts a course; the instructor is included when preloaded
+func NewCourse(course models.Course) Course {
+    return Course{
+        ID:           course.ID,
+        Title:        course.Title,
+        Description:  course.Description,
+        Image:        urls.Asset(course.Image),
+        InstructorID: course.UserID,
+        Instructor:   NewUserSummary(course.User),
+        CreatedAt:    course.CreatedAt,
+        UpdatedAt:    course.UpdatedAt,
+    }
+}
+
+// NewCourses converts a list of courses
+func NewCourses(courses []models.Course) []Course {
+    out := make([]Course, 0, len(courses))
+    for _, course := range courses {
+        out = append(out, NewCourse(course))
+    }
+    return out
+}
+
+// NewCourseDetail converts a course with its lessons. Lesson media must
+// already be signed by the caller.
+func NewCourseDetail(course models.Course) CourseDetail {
+    return CourseDetail{Course: NewCourse(course), Lessons: NewLessons(course.Lessons)}
+}
+
+// NewLesson converts a lesson. Media URLs must already be signed by the caller.
+func NewLesson(lesson models.Lesson) Lesson {
+    out := Lesson{
+        ID:        lesson.ID,
+        CourseID:  lesson.CourseID,
+        Title:     lesson.Title,
+        Content:   lesson.Content,
+        Order:     lesson.Order,
+        Image:     lesson.Image,
+        Quizzes:   NewQuizzes(lesson.Quizzes),
+        CreatedAt: lesson.CreatedAt,
+        UpdatedAt: lesson.UpdatedAt,
+    }
+    if lesson.Video != nil {
+        out.Video = &Video{
+            ContentType:     lesson.Video.ContentType,
+            Size:            lesson.Video.Size,
+            DurationSeconds: lesson.Video.DurationSeconds,
+            URL:             lesson.Video.URL,
+        }
+    }
+    return out
+}
+
+// NewLessons converts a list of lessons
+func NewLessons(lessons []models.Lesson) []Lesson {
+    out := make([]Lesson, 0, len(lessons))
+    for _, lesson := range lessons {
+        out = append(out, NewLesson(lesson))
+    }
+    return out
+}
+
+// NewQuiz converts a quiz without its answer
+func NewQuiz(quiz models.Quiz) Quiz {
+    return Quiz{
+        ID:        quiz.ID,
+        LessonID:  quiz.LessonID,
+        Question:  quiz.Question,
+        Options:   quiz.Options,
+        CreatedAt: quiz.CreatedAt,
+    }
+}
+
+// NewQuizzes converts a list of quizzes
+func NewQuizzes(quizzes []models.Quiz) []Quiz {
+    out := make([]Quiz, 0, len(quizzes))
+    for _, quiz := range quizzes {
+        out = append(out, NewQuiz(quiz))
+    }
+    return out
+}
+
+// NewQuizResult converts a quiz result
+func NewQuizResult(result models.QuizResult) QuizResult {
+    return QuizResult{
+        ID:        result.ID,
+        UserID:    result.UserID,
+        QuizID:    result.QuizID,
+        Score:     result.Score,
+        CreatedAt: result.CreatedAt,
+    }
+}
+
+// NewQuizResults converts a list of quiz results
+func NewQuizResults(results []models.QuizResult) []QuizResult {
+    out := make([]QuizResult, 0, len(results))
+    for _, result := range results {
+        out = append(out, NewQuizResult(result))
+    }
+    return out
+}
+
+// NewEnrollment converts an enrollment; the course is included when preloaded
+func NewEnrollment(enrollment models.Enrollment) Enrollment {
+    out := Enrollment{
+        ID:        enrollment.ID,
+        UserID:    enrollment.UserID,
+        CourseID:  enrollment.CourseID,
+        Progress:  enrollment.Progress,
+        CreatedAt: enrollment.CreatedAt,
+    }
+    if enrollment.Course.ID != 0 {
+        course := NewCourse(enrollment.Course)
+        out.Course = &course
+    }
+    return out
+}
+
+// NewEnrollments converts a list of enrollments
+func NewEnrollments(enrollments []models.Enrollment) []Enrollment {
+    out := make([]Enrollment, 0, len(enrollments))
+    for _, enrollment := range enrollments {
+        out = append(out, NewEnrollment(enrollment))
+    }
+    return out
+}
+
+// NewHeartbeat converts the result of a video heartbeat
+func NewHeartbeat(heartbeat services.Heartbeat) Heartbeat {
+    return Heartbeat{
+        LessonID:       heartbeat.LessonID,
+        Position:       heartbeat.Position,
+        WatchedSeconds: heartbeat.WatchedSeconds,
+        Duration:       heartbeat.Duration,
+        Completed:      heartbeat.Completed,
+    }
+}
+
+// NewUpload converts an upload session
+func NewUpload(session models.UploadSession) Upload {
+    return Upload{
+        ID:              session.ID,
+        LessonID:        session.LessonID,
+        Filename:        session.Filename,
+        ContentType:     session.ContentType,
+        Size:            session.Size,
+        Offset:          session.Offset,
+        DurationSeconds: session.DurationSeconds,
+        Checksum:        session.Checksum,
+        CompletedAt:     session.CompletedAt,
+        CreatedAt:       session.CreatedAt,
+    }
+}
+
+// NewProfile converts the profile of a user
+func NewProfile(profile models.Profile) Profile {
+    return Profile{Name: profile.Name, Image: urls.Asset(profile.Image)}
+}
+
+// NewNotificationSettings converts the email preferences of a profile
+func NewNotificationSettings(profile models.Profile) NotificationSettings {
+    return NotificationSettings{
+        Enrollments: profile.EmailEnrollments,
+        NewLessons:  profile.EmailNewLessons,
+        QuizResults: profile.EmailQuizResults,
+    }
+}
+
+// NewUser converts a user with preloaded Profile, Courses and
+// Enrollments.Course. Set private for the user's own profile to include the
+// email and role.
+func NewUser(user models.User, private bool) User {
+    out := User{
+        ID:              user.ID,
+        Profile:         NewProfile(user.Profile),
+        CreatedCourses:  make([]ProfileCourse, 0, len(user.Courses)),
+        EnrolledCourses: make([]ProfileCourse, 0, len(user.Enrollments)),
+    }
+    if private {
+        out.Email = user.Email
+        out.Role = user.Role
+    }
+
+    for _, course := range user.Courses {
+        out.CreatedCourses = append(out.CreatedCourses, ProfileCourse{
+            ID:          course.ID,
+            Title:       course.Title,
+            Description: course.Description,
+            Image:       urls.Asset(course.Image),
+        })
+    }
+    for _, enrollment := range user.Enrollments {
+        progress := enrollment.Progress
+        out.EnrolledCourses = append(out.EnrolledCourses, ProfileCourse{
+            ID:          enrollment.Course.ID,
+            Title:       enrollment.Course.Title,
+            Description: enrollment.Course.Description,
+            Image:       urls.Asset(enrollment.Course.Image),
+            Progress:    &progress,
+        })
+    }
+    return out
+}
+
+// NewJob converts a background job
+func NewJob(job models.Job) Job {
+    payload := json.RawMessage(job.Payload)
+    if !json.Valid(payload) {
+        payload = nil
+    }
+    return Job{
+        ID:          job.ID,
+        Kind:        job.Kind,
+        Payload:     payload,
+        Status:      job.Status,
+        Attempts:    job.Attempts,
+        MaxAttempts: job.MaxAttempts,
+        RunAt:       job.RunAt,
+        LockedBy:    job.LockedBy,
+        LastError:   job.LastError,
+        FinishedAt:  job.FinishedAt,
+        CreatedAt:   job.CreatedAt,
+    }
+}
+
+// NewJobs converts a list of jobs
+func NewJobs(jobs []models.Job) []Job {
+    out := make([]Job, 0, len(jobs))
+    for _, job := range jobs {
+        out = append(out, NewJob(job))
+    }
+    return out
+}
+
+// NewWebhook converts a webhook without its secret
+func NewWebhook(hook models.Webhook) Webhook {
+    events := []string{}
+    if hook.Events != "" {
+        events = strings.Split(hook.Events, ",")
+    }
+    return Webhook{
+        ID:        hook.ID,
+        CourseID:  hook.CourseID,
+        URL:       hook.URL,
+        Events:    events,
+        Active:    hook.Active,
+        CreatedAt: hook.CreatedAt,
+        UpdatedAt: hook.UpdatedAt,
+    }
+}
+
+// NewWebhooks converts a list of webhooks
+func NewWebhooks(hooks []models.Webhook) []Webhook {
+    out := make([]Webhook, 0, len(hooks))
+    for _, hook := range hooks {
+        out = append(out, NewWebhook(hook))
+    }
+    return out
+}
+
+// NewWebhookDelivery converts a webhook delivery
+func NewWebhookDelivery(delivery models.WebhookDelivery) WebhookDelivery {
+    payload := json.RawMessage(delivery.Payload)
+    if !json.Valid(payload) {
+        payload = nil
+    }
+    return WebhookDelivery{
+        ID:           delivery.ID,
+        EventID:      delivery.EventID,
+        Event:        delivery.Event,
+        Payload:      payload,
+        Status:       delivery.Status,
+        Attempts:     delivery.Attempts,
+        StatusCode:   delivery.StatusCode,
+        Response:     delivery.Response,
+        Error:        delivery.Error,
+        DurationMS:   delivery.DurationMS,
+        DeliveredAt:  delivery.DeliveredAt,
+        RedeliveryOf: delivery.RedeliveryOf,
+        CreatedAt:    delivery.CreatedAt,
+    }
+}
+
+// NewWebhookDeliveries converts a list of webhook deliveries
+func NewWebhookDeliveries(deliveries []models.WebhookDelivery) []WebhookDelivery {
+    out := make([]WebhookDelivery, 0, len(deliveries))
+    for _, delivery := range deliveries {
+        out = append(out, NewWebhookDelivery(delivery))
+    }
+    return out
+}
+
+// NewNotification converts an in-app notification
+func NewNotification(notification models.Notification) Notification {
+    payload := json.RawMessage(notification.Payload)
+    if !json.Valid(payload) {
+        payload = nil
+    }
+    return Notification{
+        ID:        notification.ID,
+        Type:      notification.Type,
+        Payload:   payload,
+        Read:      notification.ReadAt != nil,
+        ReadAt:    notification.ReadAt,
+        CreatedAt: notification.CreatedAt,
+    }
+}
+
+// NewNotifications converts a list of in-app notifications
+func NewNotifications(notifications []models.Notification) []Notification {
+    out := make([]Notification, 0, len(notifications))
+    for _, notification := range notifications {
+        out = append(out, NewNotification(notification))
+    }
+    return out
+}
@@ -0,0 +1,215 @@
+// Package dto defines the JSON representations returned by the API. Handlers
+// never send GORM models directly, so internal fields such as DeletedAt,
+// storage paths or quiz answers cannot leak into responses.
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserSummary is the public face of a user, e.g. the instructor of a course
+type UserSummary struct {
+    ID    uint   `json:"id"`
+    Name  string `json:"name"`
+    Image string `json:"image"`
+}
+
+// Course is a course without its lessons
+type Course struct {
+    ID           uint         `json:"id"`
+    Title        string       `json:"title"`
+    Description  string       `json:"description"`
+    Image        string       `json:"image"`
+    InstructorID uint         `json:"instructor_id"`
+    Instructor   *UserSummary `json:"instructor,omitempty"`
+    CreatedAt    time.Time    `json:"created_at"`
+    UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// CourseDetail is a course with all its lessons
+type CourseDetail struct {
+    Course
+    Lessons []Lesson `json:"lessons"`
+}
+
+// Lesson is a lesson with its quizzes and video. Image and Video.URL are
+// signed URLs and stay empty for users without access to the course.
+type Lesson struct {
+    ID        uint      `json:"id"`
+    CourseID  uint      `json:"course_id"`
+    Title     string    `json:"title"`
+    Content   string    `json:"content"`
+    Order     int       `json:"order"`
+    Image     string    `json:"image"`
+    Quizzes   []Quiz    `json:"quizzes"`
+    Video     *Video    `json:"video"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Video is the video attached to a lesson
+type Video struct {
+    ContentType     string  `json:"content_type"`
+    Size            int64   `json:"size"`
+    DurationSeconds float64 `json:"duration_seconds"`
+    URL             string  `json:"url"`
+}
+
+// Quiz is a quiz question. The answer is never sent to clients.
+type Quiz struct {
+    ID        uint      `json:"id"`
+    LessonID  uint      `json:"lesson_id"`
+    Question  string    `json:"question"`
+    Options   string    `json:"options"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// QuizResult is the score of a user on a quiz
+type QuizResult struct {
+    ID        uint      `json:"id"`
+    UserID    uint      `json:"user_id"`
+    QuizID    uint      `json:"quiz_id"`
+    Score     int       `json:"score"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// Enrollment is the enrollment of a user in a course
+type Enrollment struct {
+    ID        uint      `json:"id"`
+    UserID    uint      `json:"user_id"`
+    CourseID  uint      `json:"course_id"`
+    Progress  float64   `json:"progress"`
+    Course    *Course   `json:"course,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// CourseProgress is the completion percentage of a user in a course
+type CourseProgress struct {
+    CourseID uint    `json:"course_id"`
+    Progress float64 `json:"progress"`
+}
+
+// Heartbeat is the playback state returned after a video heartbeat
+type Heartbeat struct {
+    LessonID       uint    `json:"lesson_id"`
+    Position       float64 `json:"position"`
+    WatchedSeconds float64 `json:"watched_seconds"`
+    Duration       float64 `json:"duration"`
+    Completed      bool    `json:"completed"`
+}
+
+// Upload is a resumable video upload
+type Upload struct {
+    ID              string     `json:"id"`
+    LessonID        uint       `json:"lesson_id"`
+    Filename        string     `json:"filename"`
+    ContentType     string     `json:"content_type"`
+    Size            int64      `json:"size"`
+    Offset          int64      `json:"offset"`
+    DurationSeconds float64    `json:"duration_seconds"`
+    Checksum        string     `json:"checksum"`
+    CompletedAt     *time.Time `json:"completed_at"`
+    CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Profile is the name and picture of a user
+type Profile struct {
+    Name  string `json:"name"`
+    Image string `json:"image"`
+}
+
+// NotificationSettings are the notification emails a user receives
+type NotificationSettings struct {
+    Enrollments bool `json:"enrollments"`
+    NewLessons  bool `json:"new_lessons"`
+    QuizResults bool `json:"quiz_results"`
+}
+
+// ProfileCourse is a course listed on a profile page
+type ProfileCourse struct {
+    ID          uint     `json:"id"`
+    Title       string   `json:"title"`
+    Description string   `json:"description"`
+    Image       string   `json:"image"`
+    Progress    *float64 `json:"progress,omitempty"` // Hanya untuk kursus yang diikuti
+}
+
+// User is a user profile page. Email and Role are only filled in for the
+// user's own profile.
+type User struct {
+    ID              uint            `json:"id"`
+    Email           string          `json:"email,omitempty"`
+    Role            string          `json:"role,omitempty"`
+    Profile         Profile         `json:"profile"`
+    CreatedCourses  []ProfileCourse `json:"created_courses"`
+    EnrolledCourses []ProfileCourse `json:"enrolled_courses"`
+}
+
+// DevToken is the result of a development login
+type DevToken struct {
+    Token  string `json:"token"`
+    UserID uint   `json:"user_id"`
+    Role   string `json:"role"`
+}
+
+// StreamToken opens the notification stream from an EventSource
+type StreamToken struct {
+    Token     string    `json:"token"`
+    ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Job is a background job as shown to admins
+type Job struct {
+    ID          uint            `json:"id"`
+    Kind        string          `json:"kind"`
+    Payload     json.RawMessage `json:"payload"`
+    Status      string          `json:"status"`
+    Attempts    int             `json:"attempts"`
+    MaxAttempts int             `json:"max_attempts,omitempty"` // 0 = default dari konfigurasi
+    RunAt       time.Time       `json:"run_at"`
+    LockedBy    string          `json:"locked_by,omitempty"`
+    LastError   string          `json:"last_error,omitempty"`
+    FinishedAt  *time.Time      `json:"finished_at"`
+    CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Webhook is a registered webhook. The secret is only shown when the
+// webhook is created.
+type Webhook struct {
+    ID        uint      `json:"id"`
+    CourseID  *uint     `json:"course_id"`
+    URL       string    `json:"url"`
+    Events    []string  `json:"events"`
+    Active    bool      `json:"active"`
+    Secret    string    `json:"secret,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is an entry of the delivery log of a webhook
+type WebhookDelivery struct {
+    ID           uint            `json:"id"`
+    EventID      uint            `json:"event_id"`
+    Event        string          `json:"event"`
+    Payload      json.RawMessage `json:"payload"`
+    Status       string          `json:"status"`
+    Attempts     int             `json:"attempts"`
+    StatusCode   int             `json:"status_code,omitempty"`
+    Response     string          `json:"response,omitempty"`
+    Error        string          `json:"error,omitempty"`
+    DurationMS   int64           `json:"duration_ms"`
+    DeliveredAt  *time.Time      `json:"delivered_at"`
+    RedeliveryOf *uint           `json:"redelivery_of,omitempty"`
+    CreatedAt    time.Time       `json:"created_at"`
+}
+
+// Notification is an entry of the in-app inbox of a user
+type Notification struct {
+    ID        uint            `json:"id"`
+    Type      string          `json:"type"`
+    Payload   json.RawMessage `json:"payload"`
+    Read      bool            `json:"read"`
+    ReadAt    *time.Time      `json:"read_at"`
+    CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,129 @@
+// Package emails sends the notification emails of the platform. Subscribers
+// on the event bus queue one Send job per recipient; the job checks the
+// preferences of the recipient, renders the HTML and text templates and
+// hands the email to the SMTP server, retried by the job queue on failure.
+//
+// Every email links to a signed unsubscribe token and carries the
+// List-Unsubscribe headers for one-click unsubscribing in mail clients.
+package emails
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// Email templates
+const (
+    Enrolled   = "enrolled"
+    NewLesson  = "new_lesson"
+    QuizResult = "quiz_result"
+)
+
+// Notification categories users can turn off, named like the fields of the
+// notification settings. All turns off every category.
+const (
+    CategoryEnrollments = "enrollments"
+    CategoryNewLessons  = "new_lessons"
+    CategoryQuizResults = "quiz_results"
+    CategoryAll         = "all"
+)
+
+// categories maps every template to the category it belongs to
+var categories = map[string]string{
+    Enrolled:   CategoryEnrollments,
+    NewLesson:  CategoryNewLessons,
+    QuizResult: CategoryQuizResults,
+}
+
+// SendPayload are the arguments of Send. Only the IDs are queued; the email
+// is rendered from the current data when it is sent.
+type SendPayload struct {
+    Template string `json:"template"`
+    UserID   uint   `json:"user_id"`
+    CourseID uint   `json:"course_id,omitempty"`
+    LessonID uint   `json:"lesson_id,omitempty"`
+    ResultID uint   `json:"result_id,omitempty"`
+}
+
+// Send renders and sends one email
+var Send = jobs.Type[SendPayload]{Name: "emails.send"}
+
+// ErrInvalidToken is returned for unsubscribe tokens that were not signed
+// by this platform
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+// Wants reports whether the owner of profile receives emails of category
+func Wants(profile models.Profile, category string) bool {
+    switch category {
+    case CategoryEnrollments:
+        return profile.EmailEnrollments
+    case CategoryNewLessons:
+        return profile.EmailNewLessons
+    case CategoryQuizResults:
+        return profile.EmailQuizResults
+    }
+    return false
+}
+
+// UnsubscribeKey returns the key signing unsubscribe tokens:
+// MAIL_UNSUBSCRIBE_KEY, or else a sub-key derived from the JWT secret with
+// HKDF, so a token never doubles as anything signed with the secret itself.
+// Tokens do not expire, so the key must stay the same across restarts.
+func UnsubscribeKey(cfg *config.Config) string {
+    if cfg.Mail.UnsubscribeKey != "" {
+        return cfg.Mail.UnsubscribeKey
+    }
+    key := make([]byte, 32)
+    if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(cfg.JWT.Secret), nil, []byte("go-learn-platform unsubscribe")), key); err != nil {
+        panic(err) // HKDF hanya gagal jika kunci yang diminta terlalu panjang
+    }
+    return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// UnsubscribeToken returns the token that turns off a category of emails for
+// a user. It does not expire, like the emails carrying it.
+func UnsubscribeToken(key string, userID uint, category string) string {
+    id := strconv.FormatUint(uint64(userID), 10)
+    return id + "." + category + "." + tokenSignature(key, id, category)
+}
+
+// ParseUnsubscribeToken returns the user and category of a token
+func ParseUnsubscribeToken(key, token string) (uint, string, error) {
+    parts := strings.Split(token, ".")
+    if len(parts) != 3 {
+        return 0, "", ErrInvalidToken
+    }
+    id, category, signature := parts[0], parts[1], parts[2]
+    if !hmac.Equal([]byte(signature), []byte(tokenSignature(key, id, category))) {
+        return 0, "", ErrInvalidToken
+    }
+    userID, err := strconv.ParseUint(id, 10, 32)
+    if err != nil {
+        return 0, "", ErrInvalidToken
+    }
+    switch category {
+    case CategoryEnrollments, CategoryNewLessons, CategoryQuizResults, CategoryAll:
+        return uint(userID), category, nil
+    }
+    return 0, "", fmt.Errorf("%w: unknown category %q", ErrInvalidToken, category)
+}
+
+// tokenSignature is the HMAC-SHA256 of the user and category, with a prefix
+// so the key signs nothing else that looks the same
+func tokenSignature(key, id, category string) string {
+    mac := hmac.New(sha256.New, []byte(key))
+    fmt.Fprintf(mac, "unsubscribe\n%s\n%s", id, category)
+    return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,67 @@
+package emails
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+    token := UnsubscribeToken("secret", 42, CategoryNewLessons)
+    userID, category, err := ParseUnsubscribeToken("secret", token)
+    if err != nil || userID != 42 || category != CategoryNewLessons {
+        t.Fatalf("unexpected %d %q %v", userID, category, err)
+    }
+
+    for name, bad := range map[string]string{
+        "other key":      UnsubscribeToken("other", 42, CategoryNewLessons),
+        "other user":     strings.Replace(token, "42.", "43.", 1),
+        "other category": strings.Replace(token, CategoryNewLessons, CategoryAll, 1),
+        "empty":          "",
+        "garbage":        "a.b",
+    } {
+        if _, _, err := ParseUnsubscribeToken("secret", bad); !errors.Is(err, ErrInvalidToken) {
+            t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+        }
+    }
+}
+
+func TestUnsubscribeKey(t *testing.T) {
+    cfg := &config.Config{}
+    cfg.JWT.Secret = "jwt-secret-0123456789"
+
+    // Tanpa kunci khusus, kunci diturunkan dan tidak sama dengan JWT secret
+    derived := UnsubscribeKey(cfg)
+    if derived == "" || derived == cfg.JWT.Secret || derived != UnsubscribeKey(cfg) {
+        t.Fatalf("expected a stable key derived from the JWT secret, got %q", derived)
+    }
+    token := UnsubscribeToken(derived, 42, CategoryAll)
+    if _, _, err := ParseUnsubscribeToken(cfg.JWT.Secret, token); !errors.Is(err, ErrInvalidToken) {
+        t.Fatalf("expected the JWT secret not to verify tokens, got %v", err)
+    }
+
+    cfg.Mail.UnsubscribeKey = "unsubscribe-key-0123456789"
+    if got := UnsubscribeKey(cfg); got != cfg.Mail.UnsubscribeKey {
+        t.Fatalf("expected MAIL_UNSUBSCRIBE_KEY, got %q", got)
+    }
+}
+
+func TestTemplatesRender(t *testing.T) {
+    for name := range categories {
+        email, err := render(name, templateData{Name: "Andi", Course: "Go & <Web>", Lesson: "Channel", Score: 90, UnsubscribeURL: "http://app.test/unsubscribe?token=a.b"})
+        if err != nil {
+            t.Fatalf("%s: %v", name, err)
+        }
+        if email.Subject == "" || strings.Contains(email.Subject, "\n") {
+            t.Errorf("%s: unexpected subject %q", name, email.Subject)
+        }
+        if !strings.Contains(email.Text, "Go & <Web>") || !strings.Contains(email.HTML, "Go &amp; &lt;Web&gt;") {
+            t.Errorf("%s: expected the course title raw in text and escaped in HTML", name)
+        }
+        if !strings.Contains(email.Text, "Hi Andi,") || !strings.Contains(email.HTML, "unsubscribe?token=a.b") {
+            t.Errorf("%s: expected greeting and unsubscribe link", name)
+        }
+    }
+}
@@ -0,0 +1,138 @@
+package emails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/mail"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/urls"
+
+	"gorm.io/gorm"
+)
+
+// errSkipped ends a Send job without an email, e.g. when the user turned the
+// category off or the course was deleted in the meantime
+var errSkipped = errors.New("email skipped")
+
+// sender renders and sends the queued emails
+type sender struct {
+    db     *gorm.DB
+    mailer mail.Sender
+    key    string // Kunci token unsubscribe
+}
+
+// Register handles Send jobs on q. Unsubscribe tokens are signed with
+// UnsubscribeKey.
+func Register(q *jobs.Queue, db *gorm.DB, cfg *config.Config) {
+    s := &sender{db: db, mailer: mail.New(cfg.Mail), key: UnsubscribeKey(cfg)}
+    jobs.Handle(q, Send, s.send)
+}
+
+// send sends one email. SMTP rejections (5xx) are permanent, other failures
+// are retried.
+func (s *sender) send(ctx context.Context, payload SendPayload) error {
+    category, ok := categories[payload.Template]
+    if !ok {
+        return jobs.Permanent(fmt.Errorf("unknown email template %q", payload.Template))
+    }
+
+    msg, err := s.message(ctx, payload, category)
+    if errors.Is(err, errSkipped) {
+        metrics.EmailSent(payload.Template, "skipped")
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    err = s.mailer.Send(ctx, msg)
+    switch {
+    case err == nil:
+        metrics.EmailSent(payload.Template, "sent")
+        slog.InfoContext(ctx, "Email sent", "template", payload.Template, "user_id", payload.UserID)
+    case mail.Rejected(err):
+        metrics.EmailSent(payload.Template, "failed")
+        err = jobs.Permanent(err)
+    default:
+        metrics.EmailSent(payload.Template, "retry")
+    }
+    return err
+}
+
+// message loads the recipient and the data of the template and renders the
+// email
+func (s *sender) message(ctx context.Context, payload SendPayload, category string) (mail.Message, error) {
+    db := s.db.WithContext(ctx)
+
+    var user models.User
+    if err := db.Preload("Profile").First(&user, payload.UserID).Error; err != nil {
+        return mail.Message{}, missing(err)
+    }
+    // User tanpa profil memakai preferensi default: semua email aktif
+    if user.DisabledAt != nil || (user.Profile.ID != 0 && !Wants(user.Profile, category)) {
+        return mail.Message{}, errSkipped
+    }
+
+    data := templateData{
+        Name:           user.Profile.Name,
+        SettingsURL:    urls.Frontend("/myProfile"),
+        UnsubscribeURL: urls.Frontend("/unsubscribe?token=" + url.QueryEscape(UnsubscribeToken(s.key, user.ID, category))),
+    }
+    if data.Name == "" {
+        data.Name = "there"
+    }
+
+    var course models.Course
+    if err := db.First(&course, payload.CourseID).Error; err != nil {
+        return mail.Message{}, missing(err)
+    }
+    data.Course = course.Title
+    data.CourseURL = urls.Frontend(fmt.Sprintf("/courses/%d", course.ID))
+
+    if payload.LessonID != 0 {
+        var lesson models.Lesson
+        if err := db.First(&lesson, payload.LessonID).Error; err != nil {
+            return mail.Message{}, missing(err)
+        }
+        data.Lesson = lesson.Title
+        data.LessonURL = urls.Frontend(fmt.Sprintf("/courses/%d/lessons", course.ID))
+    }
+    if payload.ResultID != 0 {
+        var result models.QuizResult
+        if err := db.First(&result, payload.ResultID).Error; err != nil {
+            return mail.Message{}, missing(err)
+        }
+        data.Score = result.Score
+    }
+
+    email, err := render(payload.Template, data)
+    if err != nil {
+        return mail.Message{}, jobs.Permanent(err)
+    }
+    oneClick := urls.API("/unsubscribe?token=" + url.QueryEscape(UnsubscribeToken(s.key, user.ID, category)))
+    return mail.Message{
+        To:      user.Email,
+        Subject: email.Subject,
+        Text:    email.Text,
+        HTML:    email.HTML,
+        Header: map[string]string{
+            "List-Unsubscribe":      "<" + oneClick + ">",
+            "List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+        },
+    }, nil
+}
+
+// missing skips emails about records deleted since the email was queued
+func missing(err error) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return errSkipped
+    }
+    return err
+}
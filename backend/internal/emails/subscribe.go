@@ -0,0 +1,69 @@
+package emails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Subscribe makes bus queue the emails of enrollments, new lessons and quiz
+// results. The unique keys keep a retried subscriber from queueing an email
+// twice.
+func Subscribe(bus *events.Bus, db *gorm.DB) {
+    events.Subscribe(bus, "emails", func(ctx context.Context, event events.Enrolled) error {
+        return enqueue(ctx, db, fmt.Sprintf("email:enrolled:%d", event.EnrollmentID), SendPayload{
+            Template: Enrolled,
+            UserID:   event.UserID,
+            CourseID: event.CourseID,
+        })
+    })
+
+    events.Subscribe(bus, "emails", func(ctx context.Context, event events.LessonAdded) error {
+        // Peserta yang mematikan email ini tidak perlu dibuatkan job
+        var userIDs []uint
+        if err := db.WithContext(ctx).Model(&models.Enrollment{}).
+            Joins("LEFT JOIN profiles ON profiles.user_id = enrollments.user_id AND profiles.deleted_at IS NULL").
+            Where("enrollments.course_id = ? AND (profiles.id IS NULL OR profiles.email_new_lessons = ?)", event.CourseID, true).
+            Distinct().
+            Pluck("enrollments.user_id", &userIDs).Error; err != nil {
+            return err
+        }
+        for _, userID := range userIDs {
+            err := enqueue(ctx, db, fmt.Sprintf("email:lesson:%d:%d", event.LessonID, userID), SendPayload{
+                Template: NewLesson,
+                UserID:   userID,
+                CourseID: event.CourseID,
+                LessonID: event.LessonID,
+            })
+            if err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+
+    events.Subscribe(bus, "emails", func(ctx context.Context, event events.QuizSubmitted) error {
+        return enqueue(ctx, db, fmt.Sprintf("email:quiz:%d", event.ResultID), SendPayload{
+            Template: QuizResult,
+            UserID:   event.UserID,
+            CourseID: event.CourseID,
+            LessonID: event.LessonID,
+            ResultID: event.ResultID,
+        })
+    })
+}
+
+// enqueue queues an email once per key
+func enqueue(ctx context.Context, db *gorm.DB, key string, payload SendPayload) error {
+    _, err := Send.Enqueue(ctx, db, payload, jobs.UniqueKey(key))
+    if errors.Is(err, jobs.ErrDuplicate) {
+        return nil
+    }
+    return err
+}
@@ -0,0 +1,73 @@
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.html templates/*.txt
+var templateFS embed.FS
+
+// Every email has an HTML and a text template, both wrapped in the layout of
+// their kind. The text template defines the subject.
+var (
+    htmlTemplates = map[string]*htmltemplate.Template{}
+    textTemplates = map[string]*texttemplate.Template{}
+)
+
+func init() {
+    htmlLayout := htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html"))
+    textLayout := texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/layout.txt"))
+    for name := range categories {
+        htmlTemplates[name] = htmltemplate.Must(htmltemplate.Must(htmlLayout.Clone()).ParseFS(templateFS, "templates/"+name+".html"))
+        textTemplates[name] = texttemplate.Must(texttemplate.Must(textLayout.Clone()).ParseFS(templateFS, "templates/"+name+".txt"))
+    }
+}
+
+// templateData holds everything the templates may show
+type templateData struct {
+    Name           string // Nama penerima
+    Course         string
+    CourseURL      string
+    Lesson         string
+    LessonURL      string
+    Score          int
+    SettingsURL    string
+    UnsubscribeURL string
+}
+
+// rendered is an email ready to be sent
+type rendered struct {
+    Subject string
+    Text    string
+    HTML    string
+}
+
+// render executes the templates of an email
+func render(name string, data templateData) (rendered, error) {
+    html, ok := htmlTemplates[name]
+    if !ok {
+        return rendered{}, fmt.Errorf("unknown email template %q", name)
+    }
+    text := textTemplates[name]
+
+    var subject, textBody, htmlBody bytes.Buffer
+    if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+        return rendered{}, err
+    }
+    if err := text.ExecuteTemplate(&textBody, "layout.txt", data); err != nil {
+        return rendered{}, err
+    }
+    if err := html.ExecuteTemplate(&htmlBody, "layout.html", data); err != nil {
+        return rendered{}, err
+    }
+    return rendered{
+        Subject: strings.TrimSpace(subject.String()),
+        Text:    textBody.String(),
+        HTML:    htmlBody.String(),
+    }, nil
+}
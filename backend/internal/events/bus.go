@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go-learn-platform/internal/models"
+)
+
+var (
+    // ErrNoSubscriber is returned by Deliver for an unknown subscriber
+    ErrNoSubscriber = errors.New("no such subscriber")
+    // ErrInvalidPayload is returned by Deliver when the stored event cannot
+    // be decoded; retrying will not help
+    ErrInvalidPayload = errors.New("invalid event payload")
+)
+
+// Handler reacts to a stored event
+type Handler func(ctx context.Context, event models.OutboxEvent) error
+
+// Bus knows the subscribers of every event. Subscribers are named, and the
+// name must stay stable: it identifies the deliveries already queued.
+type Bus struct {
+    subscribers map[string]map[string]Handler // Nama event -> nama subscriber -> handler
+}
+
+// NewBus creates a bus without subscribers
+func NewBus() *Bus {
+    return &Bus{subscribers: map[string]map[string]Handler{}}
+}
+
+// Subscribe registers fn as the subscriber named subscriber of events of
+// type E. An error makes the delivery retry.
+func Subscribe[E Event](b *Bus, subscriber string, fn func(ctx context.Context, event E) error) {
+    var zero E
+    b.Handle(zero.EventName(), subscriber, func(ctx context.Context, event models.OutboxEvent) error {
+        var payload E
+        if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+            return fmt.Errorf("%s %d: %w: %v", event.Name, event.ID, ErrInvalidPayload, err)
+        }
+        return fn(ctx, payload)
+    })
+}
+
+// Handle registers fn as the subscriber named subscriber of the event name,
+// with the stored event instead of a decoded payload
+func (b *Bus) Handle(name, subscriber string, fn Handler) {
+    if b.subscribers[name] == nil {
+        b.subscribers[name] = map[string]Handler{}
+    }
+    b.subscribers[name][subscriber] = fn
+}
+
+// Subscribers returns the names of the subscribers of an event, sorted
+func (b *Bus) Subscribers(name string) []string {
+    names := make([]string, 0, len(b.subscribers[name]))
+    for subscriber := range b.subscribers[name] {
+        names = append(names, subscriber)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Deliver hands a stored event to one of its subscribers
+func (b *Bus) Deliver(ctx context.Context, event models.OutboxEvent, subscriber string) error {
+    fn, ok := b.subscribers[event.Name][subscriber]
+    if !ok {
+        return fmt.Errorf("%s of %s: %w", subscriber, event.Name, ErrNoSubscriber)
+    }
+    return fn(ctx, event)
+}
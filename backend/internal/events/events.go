@@ -0,0 +1,127 @@
+// Package events defines the domain events of the platform. Services record
+// them in the outbox table within the transaction of the change that caused
+// them, so an event exists exactly when the change was committed. The job
+// queue relays every event to each subscriber separately: a failing
+// subscriber is retried on its own and never blocks the others.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Event is a domain event; its name identifies the type in the outbox
+type Event interface {
+    EventName() string
+}
+
+// UserRegistered is recorded when a user logs in for the first time
+type UserRegistered struct {
+    UserID uint   `json:"user_id"`
+    Email  string `json:"email"`
+}
+
+// CourseCreated is recorded when an instructor creates a course
+type CourseCreated struct {
+    CourseID uint   `json:"course_id"`
+    UserID   uint   `json:"user_id"`
+    Title    string `json:"title"`
+}
+
+// CoursePublished is recorded when a course becomes visible in the catalog.
+// Courses have no draft state yet, so this happens when they are created.
+type CoursePublished struct {
+    CourseID uint   `json:"course_id"`
+    UserID   uint   `json:"user_id"`
+    Title    string `json:"title"`
+}
+
+// Enrolled is recorded when a user enrolls in a course
+type Enrolled struct {
+    EnrollmentID uint `json:"enrollment_id"`
+    UserID       uint `json:"user_id"`
+    CourseID     uint `json:"course_id"`
+}
+
+// EnrollmentCancelled is recorded when a user cancels an enrollment
+type EnrollmentCancelled struct {
+    EnrollmentID uint `json:"enrollment_id"`
+    UserID       uint `json:"user_id"`
+    CourseID     uint `json:"course_id"`
+}
+
+// LessonAdded is recorded when an instructor adds a lesson to a course
+type LessonAdded struct {
+    LessonID uint   `json:"lesson_id"`
+    CourseID uint   `json:"course_id"`
+    Title    string `json:"title"`
+}
+
+// LessonCompleted is recorded the first time a user completes a lesson, by
+// answering one of its quizzes or by watching its video
+type LessonCompleted struct {
+    UserID   uint `json:"user_id"`
+    LessonID uint `json:"lesson_id"`
+    CourseID uint `json:"course_id"`
+}
+
+// QuizSubmitted is recorded for every completed quiz
+type QuizSubmitted struct {
+    ResultID uint `json:"result_id"`
+    UserID   uint `json:"user_id"`
+    QuizID   uint `json:"quiz_id"`
+    LessonID uint `json:"lesson_id"`
+    CourseID uint `json:"course_id"`
+    Score    int  `json:"score"`
+}
+
+// CourseCompleted is recorded when the progress of an enrollment reaches 100%
+type CourseCompleted struct {
+    UserID   uint `json:"user_id"`
+    CourseID uint `json:"course_id"`
+}
+
+func (UserRegistered) EventName() string { return "user.registered" }
+func (CourseCreated) EventName() string { return "course.created" }
+func (CoursePublished) EventName() string { return "course.published" }
+func (Enrolled) EventName() string { return "enrollment.created" }
+func (EnrollmentCancelled) EventName() string { return "enrollment.cancelled" }
+func (LessonAdded) EventName() string { return "lesson.added" }
+func (LessonCompleted) EventName() string { return "lesson.completed" }
+func (QuizSubmitted) EventName() string { return "quiz.submitted" }
+func (CourseCompleted) EventName() string { return "course.completed" }
+
+// Names lists the names of all events
+var Names = []string{
+    UserRegistered{}.EventName(),
+    CourseCreated{}.EventName(),
+    CoursePublished{}.EventName(),
+    Enrolled{}.EventName(),
+    EnrollmentCancelled{}.EventName(),
+    LessonAdded{}.EventName(),
+    LessonCompleted{}.EventName(),
+    QuizSubmitted{}.EventName(),
+    CourseCompleted{}.EventName(),
+}
+
+// Record stores events in the outbox. Pass the transaction of the change
+// that caused them, so they are only published when it commits.
+func Record(ctx context.Context, db *gorm.DB, events ...Event) error {
+    rows := make([]models.OutboxEvent, 0, len(events))
+    for _, event := range events {
+        data, err := json.Marshal(event)
+        if err != nil {
+            return fmt.Errorf("encode %s: %w", event.EventName(), err)
+        }
+        rows = append(rows, models.OutboxEvent{Name: event.EventName(), Payload: string(data)})
+    }
+    if len(rows) == 0 {
+        return nil
+    }
+    return db.WithContext(ctx).Create(&rows).Error
+}
@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// relayBatch is the number of outbox events relayed per transaction
+const relayBatch = 100
+
+// DeliverPayload are the arguments of Deliver
+type DeliverPayload struct {
+    EventID    uint   `json:"event_id"`
+    Subscriber string `json:"subscriber"`
+}
+
+// Deliver hands one outbox event to one subscriber
+var Deliver = Type[DeliverPayload]{Name: "events.deliver"}
+
+// RelayEvents handles deliveries to the subscribers of bus and relays new
+// outbox events while the queue runs
+func RelayEvents(q *Queue, bus *events.Bus) {
+    Handle(q, Deliver, func(ctx context.Context, payload DeliverPayload) error {
+        var event models.OutboxEvent
+        if err := q.db.WithContext(ctx).First(&event, payload.EventID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return Permanent(fmt.Errorf("event %d: %w", payload.EventID, err))
+            }
+            return err
+        }
+
+        err := bus.Deliver(ctx, event, payload.Subscriber)
+        if errors.Is(err, events.ErrNoSubscriber) || errors.Is(err, events.ErrInvalidPayload) {
+            return Permanent(err)
+        }
+        return err
+    })
+    q.Loop(func(ctx context.Context) (bool, error) {
+        return relay(ctx, q, bus)
+    })
+}
+
+// relay turns a batch of unpublished outbox events into one Deliver job per
+// subscriber and marks the events as published, in one transaction. It
+// reports whether it found any events.
+func relay(ctx context.Context, q *Queue, bus *events.Bus) (bool, error) {
+    found := false
+    err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var pending []models.OutboxEvent
+        query := tx.Where("published_at IS NULL").Order("id").Limit(relayBatch)
+        if err := skipLocked(query).Find(&pending).Error; err != nil {
+            return err
+        }
+        if len(pending) == 0 {
+            return nil
+        }
+        found = true
+
+        now := q.now()
+        ids := make([]uint, 0, len(pending))
+        for _, event := range pending {
+            for _, subscriber := range bus.Subscribers(event.Name) {
+                // Relay yang terputus di tengah jalan tidak menggandakan pengiriman
+                key := fmt.Sprintf("event:%d:%s", event.ID, subscriber)
+                payload := DeliverPayload{EventID: event.ID, Subscriber: subscriber}
+                if _, err := Deliver.Enqueue(ctx, tx, payload, At(now), UniqueKey(key)); err != nil && !errors.Is(err, ErrDuplicate) {
+                    return err
+                }
+            }
+            ids = append(ids, event.ID)
+        }
+        return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("published_at", now).Error
+    })
+    return found, err
+}
@@ -0,0 +1,129 @@
+// Package jobs runs background work stored in the jobs table. Workers claim
+// due jobs with SELECT ... FOR UPDATE SKIP LOCKED on Postgres, so any number
+// of processes can work on the same queue. Failed jobs are retried with
+// exponential backoff and become dead after their last attempt; dead jobs
+// stay in the table until an admin retries them.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDuplicate is returned by Enqueue when a job with the same unique key
+// already exists
+var ErrDuplicate = errors.New("job with this unique key already exists")
+
+// Type is a kind of job whose payload is a T, stored as JSON
+type Type[T any] struct {
+    Name string
+}
+
+// Option changes a job before it is enqueued
+type Option func(*models.Job)
+
+// At delays the job until t
+func At(t time.Time) Option {
+    return func(job *models.Job) { job.RunAt = t }
+}
+
+// After delays the job by d
+func After(d time.Duration) Option {
+    return func(job *models.Job) { job.RunAt = time.Now().Add(d) }
+}
+
+// MaxAttempts overrides JOB_MAX_ATTEMPTS for the job
+func MaxAttempts(n int) Option {
+    return func(job *models.Job) { job.MaxAttempts = n }
+}
+
+// UniqueKey makes Enqueue fail with ErrDuplicate when a job with the same
+// key was already enqueued
+func UniqueKey(key string) Option {
+    return func(job *models.Job) { job.UniqueKey = &key }
+}
+
+// Enqueue stores a job of this type. Pass a transaction as db to enqueue the
+// job together with the change that caused it.
+func (t Type[T]) Enqueue(ctx context.Context, db *gorm.DB, payload T, opts ...Option) (models.Job, error) {
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return models.Job{}, fmt.Errorf("encode %s payload: %w", t.Name, err)
+    }
+
+    job := models.Job{Kind: t.Name, Payload: string(data), Status: models.JobPending, RunAt: time.Now()}
+    for _, opt := range opts {
+        opt(&job)
+    }
+
+    if job.UniqueKey == nil {
+        return job, db.WithContext(ctx).Create(&job).Error
+    }
+
+    // Hanya bentrok unique key yang diabaikan, sehingga dilaporkan sebagai duplikat
+    result := db.WithContext(ctx).
+        Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "unique_key"}}, DoNothing: true}).
+        Create(&job)
+    if result.Error != nil {
+        return job, result.Error
+    }
+    if result.RowsAffected == 0 {
+        return job, fmt.Errorf("%s %s: %w", t.Name, *job.UniqueKey, ErrDuplicate)
+    }
+    return job, nil
+}
+
+// handler runs a job with its raw payload
+type handler func(ctx context.Context, payload []byte) error
+
+// Handle registers fn as the handler of jobs of type t. An error makes the
+// job retry; wrap it with Permanent to give up at once.
+func Handle[T any](q *Queue, t Type[T], fn func(ctx context.Context, payload T) error) {
+    q.handlers[t.Name] = func(ctx context.Context, data []byte) error {
+        var payload T
+        if err := json.Unmarshal(data, &payload); err != nil {
+            return Permanent(fmt.Errorf("decode payload: %w", err))
+        }
+        return fn(ctx, payload)
+    }
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+    err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job becomes dead without further attempts
+func Permanent(err error) error {
+    return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+    var permanent permanentError
+    return errors.As(err, &permanent)
+}
+
+// Backoff returns the delay before the next attempt after the given number
+// of failed attempts: base, 2×base, 4×base… but never more than max
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+    delay := base
+    for i := 1; i < attempts && delay < max; i++ {
+        delay *= 2
+    }
+    if delay > max {
+        delay = max
+    }
+    return delay
+}
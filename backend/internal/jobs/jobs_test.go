@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type greeting struct {
+    Name string `json:"name"`
+}
+
+var greet = Type[greeting]{Name: "test.greet"}
+
+// newQueue creates a queue on a fresh SQLite database with a fake clock
+func newQueue(t *testing.T, now *time.Time) *Queue {
+    t.Helper()
+    db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "jobs.db")), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := models.Migrate(db); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() {
+        if sqlDB, err := db.DB(); err == nil {
+            sqlDB.Close()
+        }
+    })
+
+    q := New(db, config.JobsConfig{
+        Workers:      1,
+        PollInterval: time.Second,
+        LockTimeout:  time.Minute,
+        MaxAttempts:  3,
+        BackoffBase:  10 * time.Second,
+        BackoffMax:   time.Hour,
+    })
+    q.now = func() time.Time { return *now }
+    return q
+}
+
+// work runs one job and returns it as stored afterwards
+func work(t *testing.T, q *Queue, id uint) models.Job {
+    t.Helper()
+    if ran, err := q.Work(context.Background()); err != nil || !ran {
+        t.Fatalf("expected a job to run, got %v, %v", ran, err)
+    }
+    var job models.Job
+    q.db.First(&job, id)
+    return job
+}
+
+func TestWorkRunsTypedHandler(t *testing.T) {
+    now := time.Now()
+    q := newQueue(t, &now)
+    var got string
+    Handle(q, greet, func(ctx context.Context, payload greeting) error {
+        got = payload.Name
+        return nil
+    })
+
+    job, err := greet.Enqueue(context.Background(), q.db, greeting{Name: "Budi"}, At(now))
+    if err != nil {
+        t.Fatal(err)
+    }
+    job = work(t, q, job.ID)
+    if got != "Budi" || job.Status != models.JobDone || job.Attempts != 1 || job.FinishedAt == nil || job.LockedBy != "" {
+        t.Fatalf("expected a finished job, got %q and %+v", got, job)
+    }
+    if ran, _ := q.Work(context.Background()); ran {
+        t.Fatal("expected no job left")
+    }
+}
+
+func TestEnqueueConflicts(t *testing.T) {
+    now := time.Now()
+    q := newQueue(t, &now)
+    ctx := context.Background()
+
+    first, err := greet.Enqueue(ctx, q.db, greeting{Name: "Budi"}, UniqueKey("greet:budi"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := greet.Enqueue(ctx, q.db, greeting{Name: "Budi"}, UniqueKey("greet:budi")); !errors.Is(err, ErrDuplicate) {
+        t.Fatalf("expected ErrDuplicate, got %v", err)
+    }
+
+    // Bentrok lain tanpa unique key adalah error biasa, bukan duplikat
+    sameID := func(job *models.Job) { job.ID = first.ID }
+    if _, err := greet.Enqueue(ctx, q.db, greeting{Name: "Andi"}, sameID); err == nil || errors.Is(err, ErrDuplicate) {
+        t.Fatalf("expected a plain error, got %v", err)
+    }
+}
+
+func TestFailedJobsBackOffAndDie(t *testing.T) {
+    now := time.Now()
+    q := newQueue(t, &now)
+    Handle(q, greet, func(ctx context.Context, payload greeting) error {
+        return errors.New("smtp down")
+    })
+    job, _ := greet.Enqueue(context.Background(), q.db, greeting{Name: "Budi"}, At(now))
+
+    job = work(t, q, job.ID)
+    if job.Status != models.JobPending || job.LastError != "smtp down" || !job.RunAt.Equal(now.Add(10*time.Second)) {
+        t.Fatalf("expected a retry after 10s, got %+v", job)
+    }
+    if ran, _ := q.Work(context.Background()); ran {
+        t.Fatal("expected the retry to wait for its backoff")
+    }
+
+    now = now.Add(10 * time.Second)
+    job = work(t, q, job.ID)
+    if !job.RunAt.Equal(now.Add(20 * time.Second)) {
+        t.Fatalf("expected the backoff to double, got run_at %v", job.RunAt)
+    }
+
+    now = now.Add(20 * time.Second)
+    job = work(t, q, job.ID)
+    if job.Status != models.JobDead || job.Attempts != 3 || job.FinishedAt == nil {
+        t.Fatalf("expected a dead job after 3 attempts, got %+v", job)
+    }
+}
+
+func TestPermanentErrorsAndPanics(t *testing.T) {
+    now := time.Now()
+    q := newQueue(t, &now)
+    Handle(q, greet, func(ctx context.Context, payload greeting) error {
+        if payload.Name == "" {
+            return Permanent(errors.New("name is required"))
+        }
+        panic("boom")
+    })
+
+    invalid, _ := greet.Enqueue(context.Background(), q.db, greeting{}, At(now))
+    if job := work(t, q, invalid.ID); job.Status != models.JobDead {
+        t.Fatalf("expected a permanent error to kill the job, got %+v", job)
+    }
+    panicked, _ := greet.Enqueue(context.Background(), q.db, greeting{Name: "Budi"}, At(now))
+    if job := work(t, q, panicked.ID); job.Status != models.JobPending || job.LastError != "panic: boom" {
+        t.Fatalf("expected a panic to be retried, got %+v", job)
+    }
+}
+
+func TestStaleJobsAreReclaimed(t *testing.T) {
+    now := time.Now()
+    q := newQueue(t, &now)
+    Handle(q, greet, func(ctx context.Context, payload greeting) error { return nil })
+
+    // Worker lain mati saat menjalankan job ini
+    locked := now.Add(-2 * time.Minute)
+    job := models.Job{Kind: greet.Name, Payload: `{"name":"Budi"}`, Status: models.JobRunning, Attempts: 1, RunAt: locked, LockedAt: &locked, LockedBy: "other:1"}
+    q.db.Create(&job)
+
+    if job = work(t, q, job.ID); job.Status != models.JobDone || job.Attempts != 2 {
+        t.Fatalf("expected the stale job to be run again, got %+v", job)
+    }
+}
+
+func TestScheduleEnqueuesOncePerRun(t *testing.T) {
+    now := time.Date(2026, 10, 19, 3, 29, 30, 0, time.UTC)
+    q := newQueue(t, &now)
+    // Instance kedua dengan database yang sama
+    other := New(q.db, q.cfg)
+    other.now = q.now
+
+    for _, queue := range []*Queue{q, other} {
+        if err := Schedule(queue, "30 3 * * *", greet, greeting{Name: "cron"}); err != nil {
+            t.Fatal(err)
+        }
+        queue.schedules[0].last = now
+    }
+    q.tick(context.Background())
+
+    now = now.Add(time.Minute)
+    q.tick(context.Background())
+    other.tick(context.Background())
+    q.tick(context.Background())
+
+    var jobs []models.Job
+    q.db.Find(&jobs)
+    if len(jobs) != 1 || !jobs[0].RunAt.Equal(time.Date(2026, 10, 19, 3, 30, 0, 0, time.UTC)) {
+        t.Fatalf("expected one job at 03:30, got %+v", jobs)
+    }
+    if err := Schedule(q, "every day", greet, greeting{}); err == nil {
+        t.Fatal("expected an error for an invalid schedule")
+    }
+}
+
+func TestBackoff(t *testing.T) {
+    cases := map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 4: 80 * time.Second, 20: time.Hour}
+    for attempts, want := range cases {
+        if got := Backoff(attempts, 10*time.Second, time.Hour); got != want {
+            t.Errorf("Backoff(%d) = %v, want %v", attempts, got, want)
+        }
+    }
+}
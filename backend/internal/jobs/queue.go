@@ -0,0 +1,326 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/tracing"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Queue claims and runs jobs with the registered handlers
+type Queue struct {
+    db        *gorm.DB
+    cfg       config.JobsConfig
+    handlers  map[string]handler
+    schedules []*schedule
+    loops     []func(ctx context.Context) (bool, error)
+    worker    string           // Identitas proses di kolom locked_by
+    now       func() time.Time // Bisa diganti di test
+}
+
+// schedule enqueues a job every time its cron spec is due
+type schedule struct {
+    name    string
+    spec    cron.Schedule
+    last    time.Time
+    enqueue func(ctx context.Context, at time.Time) error
+}
+
+// New creates a queue without handlers
+func New(db *gorm.DB, cfg config.JobsConfig) *Queue {
+    host, _ := os.Hostname()
+    return &Queue{
+        db:       db,
+        cfg:      cfg,
+        handlers: map[string]handler{},
+        worker:   fmt.Sprintf("%s:%d", host, os.Getpid()),
+        now:      time.Now,
+    }
+}
+
+// Schedule enqueues a job of type t with payload whenever the cron spec is
+// due, e.g. "30 3 * * *". An empty spec does nothing. Every instance runs the
+// schedule, but a unique key per run makes sure the job is enqueued once.
+func Schedule[T any](q *Queue, spec string, t Type[T], payload T) error {
+    if spec == "" {
+        return nil
+    }
+    parsed, err := cron.ParseStandard(spec)
+    if err != nil {
+        return fmt.Errorf("schedule of %s: %w", t.Name, err)
+    }
+
+    q.schedules = append(q.schedules, &schedule{
+        name: t.Name,
+        spec: parsed,
+        enqueue: func(ctx context.Context, at time.Time) error {
+            key := fmt.Sprintf("cron:%s:%d", t.Name, at.Unix())
+            _, err := t.Enqueue(ctx, q.db, payload, At(at), UniqueKey(key))
+            if errors.Is(err, ErrDuplicate) {
+                return nil // Instance lain sudah memasukkan job ini
+            }
+            return err
+        },
+    })
+    return nil
+}
+
+// Schedules returns the names of the job types with a schedule, in the
+// order they were added
+func (q *Queue) Schedules() []string {
+    names := make([]string, 0, len(q.schedules))
+    for _, s := range q.schedules {
+        names = append(names, s.name)
+    }
+    return names
+}
+
+// Run starts cfg.Workers workers and the schedules, and blocks until ctx is
+// cancelled. Jobs already running are finished before Run returns.
+func (q *Queue) Run(ctx context.Context) {
+    slog.Info("Job workers started", "workers", q.cfg.Workers, "handlers", len(q.handlers), "schedules", len(q.schedules))
+
+    var wg sync.WaitGroup
+    for i := 0; i < q.cfg.Workers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            q.poll(ctx, q.work)
+        }()
+    }
+    for _, fn := range q.loops {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            q.poll(ctx, func(ctx context.Context) bool {
+                more, err := fn(ctx)
+                if err != nil && ctx.Err() == nil {
+                    slog.ErrorContext(ctx, "Job loop failed", "error", err)
+                }
+                return more
+            })
+        }()
+    }
+
+    start := q.now()
+    for _, s := range q.schedules {
+        s.last = start
+    }
+    q.poll(ctx, func(ctx context.Context) bool {
+        q.tick(ctx)
+        return false
+    })
+
+    wg.Wait()
+    slog.Info("Job workers stopped")
+}
+
+// Loop makes Run call fn alongside the workers: again at once while it
+// reports more work, otherwise after PollInterval
+func (q *Queue) Loop(fn func(ctx context.Context) (bool, error)) {
+    q.loops = append(q.loops, fn)
+}
+
+// Drain runs the loops and every due job until there is nothing left to do,
+// without waiting for jobs scheduled later. It is meant for tests and
+// one-off commands; Run is the way to process the queue.
+func (q *Queue) Drain(ctx context.Context) error {
+    for {
+        busy := false
+        for _, fn := range q.loops {
+            more, err := fn(ctx)
+            if err != nil {
+                return err
+            }
+            busy = busy || more
+        }
+        ran, err := q.Work(ctx)
+        if err != nil {
+            return err
+        }
+        if !busy && !ran {
+            return nil
+        }
+    }
+}
+
+// poll calls fn until ctx is cancelled, waiting PollInterval whenever fn
+// reports that there was nothing to do
+func (q *Queue) poll(ctx context.Context, fn func(ctx context.Context) bool) {
+    timer := time.NewTimer(0)
+    defer timer.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-timer.C:
+        }
+        if fn(ctx) {
+            timer.Reset(0)
+        } else {
+            timer.Reset(q.cfg.PollInterval)
+        }
+    }
+}
+
+// tick enqueues the jobs of every schedule that became due
+func (q *Queue) tick(ctx context.Context) {
+    now := q.now()
+    for _, s := range q.schedules {
+        next := s.spec.Next(s.last)
+        if next.After(now) {
+            continue
+        }
+        if err := s.enqueue(ctx, next); err != nil {
+            slog.ErrorContext(ctx, "Failed to enqueue scheduled job", "kind", s.name, "error", err)
+            continue
+        }
+        s.last = next
+    }
+}
+
+// work runs one due job, if any, and reports whether it found one
+func (q *Queue) work(ctx context.Context) bool {
+    ran, err := q.Work(ctx)
+    if err != nil && ctx.Err() == nil {
+        slog.ErrorContext(ctx, "Failed to claim job", "error", err)
+    }
+    return ran
+}
+
+// Work claims and runs one due job. It reports whether a job was run; the
+// outcome of the job itself is stored on the job.
+func (q *Queue) Work(ctx context.Context) (bool, error) {
+    job, err := q.claim(ctx)
+    if err != nil || job == nil {
+        return false, err
+    }
+
+    // Job yang sedang berjalan diselesaikan walau worker diminta berhenti
+    runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.cfg.LockTimeout)
+    defer cancel()
+    return true, q.run(runCtx, job)
+}
+
+// claim locks the next due job, or a running job whose worker stopped
+// responding, and marks it as running
+func (q *Queue) claim(ctx context.Context) (*models.Job, error) {
+    now := q.now()
+    var claimed *models.Job
+    err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        query := tx.Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+            models.JobPending, now, models.JobRunning, now.Add(-q.cfg.LockTimeout)).
+            Order("run_at, id").
+            Limit(1)
+
+        var job models.Job
+        result := skipLocked(query).Find(&job)
+        if result.Error != nil || result.RowsAffected == 0 {
+            return result.Error
+        }
+
+        job.Status = models.JobRunning
+        job.Attempts++
+        job.LockedAt = &now
+        job.LockedBy = q.worker
+        if err := tx.Model(&job).Select("status", "attempts", "locked_at", "locked_by").Updates(&job).Error; err != nil {
+            return err
+        }
+        claimed = &job
+        return nil
+    })
+    return claimed, err
+}
+
+// skipLocked locks the rows selected by query and skips those locked by
+// another transaction. SQLite has no row locks; its transactions already
+// run one at a time.
+func skipLocked(query *gorm.DB) *gorm.DB {
+    if query.Dialector.Name() != "postgres" {
+        return query
+    }
+    return query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+}
+
+// run runs a claimed job and stores the outcome
+func (q *Queue) run(ctx context.Context, job *models.Job) error {
+    ctx, span := tracing.Start(ctx, "job "+job.Kind,
+        attribute.Int64("job.id", int64(job.ID)),
+        attribute.Int("job.attempt", job.Attempts))
+    start := time.Now()
+
+    err := q.call(ctx, job)
+    tracing.End(span, err)
+
+    result := q.finish(job, err)
+    metrics.JobProcessed(job.Kind, result, time.Since(start))
+    log := slog.InfoContext
+    if err != nil {
+        log = slog.WarnContext
+    }
+    log(ctx, "Job finished", "job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts, "result", result, "error", err)
+
+    return q.db.WithContext(ctx).Model(job).
+        Where("locked_by = ?", q.worker).
+        Select("status", "run_at", "locked_at", "locked_by", "last_error", "finished_at").
+        Updates(job).Error
+}
+
+// call runs the handler of a job, turning a panic into an error
+func (q *Queue) call(ctx context.Context, job *models.Job) (err error) {
+    h, ok := q.handlers[job.Kind]
+    if !ok {
+        return Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+    }
+
+    defer func() {
+        if p := recover(); p != nil {
+            slog.ErrorContext(ctx, "Job panicked", "job_id", job.ID, "panic", p, "stack", string(debug.Stack()))
+            err = fmt.Errorf("panic: %v", p)
+        }
+    }()
+    return h(ctx, []byte(job.Payload))
+}
+
+// finish updates a job after a run and returns the result for the metrics:
+// done, retry or dead
+func (q *Queue) finish(job *models.Job, err error) string {
+    now := q.now()
+    job.LockedAt = nil
+    job.LockedBy = ""
+    if err == nil {
+        job.Status = models.JobDone
+        job.LastError = ""
+        job.FinishedAt = &now
+        return "done"
+    }
+
+    job.LastError = err.Error()
+    maxAttempts := job.MaxAttempts
+    if maxAttempts <= 0 {
+        maxAttempts = q.cfg.MaxAttempts
+    }
+    if job.Attempts >= maxAttempts || IsPermanent(err) {
+        job.Status = models.JobDead
+        job.FinishedAt = &now
+        return "dead"
+    }
+
+    job.Status = models.JobPending
+    job.RunAt = now.Add(Backoff(job.Attempts, q.cfg.BackoffBase, q.cfg.BackoffMax))
+    return "retry"
+}
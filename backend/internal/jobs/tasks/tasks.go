@@ -0,0 +1,67 @@
+// Package tasks defines the background jobs of the platform and registers
+// them on a queue. It lives apart from package jobs so the services can
+// enqueue jobs without depending on the code the jobs run.
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go-learn-platform/internal/admin"
+	"go-learn-platform/internal/emails"
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/webhooks"
+
+	"gorm.io/gorm"
+)
+
+// GCUploadsPayload are the arguments of GCUploads
+type GCUploadsPayload struct {
+    OlderThan time.Duration `json:"older_than"`
+}
+
+// Job types of the platform
+var (
+    // GCUploads deletes abandoned uploads and orphan files, like `gc-uploads`
+    GCUploads = jobs.Type[GCUploadsPayload]{Name: "uploads.gc"}
+    // Cleanup deletes finished jobs and published events older than
+    // JOB_RETENTION; dead jobs are kept
+    Cleanup = jobs.Type[struct{}]{Name: "jobs.cleanup"}
+)
+
+// Register adds the handlers and schedules of the platform's jobs to q, and
+// the delivery of domain events to the subscribers of bus, including the
+// webhooks and notification emails
+func Register(q *jobs.Queue, db *gorm.DB, cfg *config.Config, bus *events.Bus) error {
+    webhooks.Subscribe(bus, db, cfg.Webhooks)
+    webhooks.Register(q, db, cfg.Webhooks)
+    emails.Subscribe(bus, db)
+    emails.Register(q, db, cfg)
+    jobs.RelayEvents(q, bus)
+    jobs.Handle(q, GCUploads, func(ctx context.Context, payload GCUploadsPayload) error {
+        report, err := admin.GCUploads(db.WithContext(ctx), payload.OlderThan, false)
+        if err != nil {
+            return err
+        }
+        slog.InfoContext(ctx, "Collected uploads", "uploads", len(report.AbandonedUploads), "files", len(report.OrphanFiles), "bytes", report.FreedBytes)
+        return nil
+    })
+    jobs.Handle(q, Cleanup, func(ctx context.Context, _ struct{}) error {
+        cutoff := time.Now().Add(-cfg.Jobs.Retention)
+        db := db.WithContext(ctx)
+        if err := db.Where("status = ? AND finished_at < ?", models.JobDone, cutoff).
+            Delete(&models.Job{}).Error; err != nil {
+            return err
+        }
+        return db.Where("published_at < ?", cutoff).Delete(&models.OutboxEvent{}).Error
+    })
+
+    if err := jobs.Schedule(q, cfg.Jobs.GCUploadsSchedule, GCUploads, GCUploadsPayload{OlderThan: 24 * time.Hour}); err != nil {
+        return err
+    }
+    return jobs.Schedule(q, cfg.Jobs.CleanupSchedule, Cleanup, struct{}{})
+}
@@ -0,0 +1,57 @@
+package tasks_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/jobs/tasks"
+	"go-learn-platform/internal/pkg/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// schedules registers the tasks with cfg and returns the scheduled job types
+func schedules(t *testing.T, configure func(cfg *config.Config)) []string {
+    t.Helper()
+    db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tasks.db")), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    cfg := apitest.Config(t.TempDir())
+    configure(cfg)
+    q := jobs.New(db, cfg.Jobs)
+    if err := tasks.Register(q, db, cfg, events.NewBus()); err != nil {
+        t.Fatal(err)
+    }
+    return q.Schedules()
+}
+
+func TestRegisterSchedules(t *testing.T) {
+    got := schedules(t, func(cfg *config.Config) {
+        cfg.Jobs.GCUploadsSchedule = "30 3 * * *"
+        cfg.Jobs.CleanupSchedule = "0 4 * * *"
+    })
+    if want := []string{tasks.GCUploads.Name, tasks.Cleanup.Name}; !reflect.DeepEqual(got, want) {
+        t.Fatalf("schedules = %v, want %v", got, want)
+    }
+}
+
+func TestRegisterDisabledSchedules(t *testing.T) {
+    // Jadwal kosong (JOB_GC_UPLOADS_SCHEDULE=) menonaktifkan job-nya
+    got := schedules(t, func(cfg *config.Config) {
+        cfg.Jobs.GCUploadsSchedule = ""
+        cfg.Jobs.CleanupSchedule = ""
+    })
+    if len(got) != 0 {
+        t.Fatalf("schedules = %v, want none", got)
+    }
+}
@@ -1,42 +1,109 @@
 package middleware
 
 import (
+    "crypto/subtle"
+    "log/slog"
     "net/http"
+    "strings"
+
+    "go-learn-platform/internal/auth"
+    "go-learn-platform/internal/models"
+    "go-learn-platform/internal/pkg/logger"
+    "go-learn-platform/internal/pkg/response"
 
     "github.com/gin-gonic/gin"
-    "github.com/golang-jwt/jwt/v5"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+    "gorm.io/gorm"
 )
 
-var jwtKey = []byte("your_secret_key") // Harus sama dengan secret key di auth.go
-
-// AuthMiddleware verifies JWT and sets user_id in context
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware verifies JWT, rejects disabled accounts and sets userID and
+// userRole in context. The user ID is also added to the log attributes and
+// the trace span.
+func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
     return func(c *gin.Context) {
         tokenString := c.GetHeader("Authorization")
         if tokenString == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing token"})
-            c.Abort()
+            response.AbortCode(c, http.StatusUnauthorized, response.CodeUnauthorized, "Missing token")
             return
         }
 
         // Hapus "Bearer " dari token jika ada
-        tokenString = tokenString[len("Bearer "):]
+        tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
+        userID, err := auth.ParseJWT(tokenString)
+        if err != nil {
+            response.AbortCode(c, http.StatusUnauthorized, response.CodeInvalidToken, "Invalid token")
+            return
+        }
+        authenticate(c, db, userID)
+    }
+}
 
-        token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-            return jwtKey, nil
-        })
+// StreamAuthMiddleware is AuthMiddleware for event streams. EventSource in
+// browsers cannot send headers, so a stream token from
+// auth.GenerateStreamToken is also accepted in the token query parameter.
+func StreamAuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+    header := AuthMiddleware(db)
+    return func(c *gin.Context) {
+        tokenString := c.Query("token")
+        if tokenString == "" {
+            header(c)
+            return
+        }
 
-        if err != nil || !token.Valid {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-            c.Abort()
+        userID, err := auth.ParseStreamToken(tokenString)
+        if err != nil {
+            response.AbortCode(c, http.StatusUnauthorized, response.CodeInvalidToken, "Invalid token")
             return
         }
+        authenticate(c, db, userID)
+    }
+}
+
+// authenticate rejects disabled accounts and sets userID and userRole in
+// context for a user whose token was verified
+func authenticate(c *gin.Context, db *gorm.DB, userID uint) {
+    // Token tetap valid sampai kedaluwarsa, jadi status akun dicek di setiap request
+    var user models.User
+    if err := db.WithContext(c.Request.Context()).Select("id", "role", "disabled_at").First(&user, userID).Error; err != nil {
+        response.AbortCode(c, http.StatusUnauthorized, response.CodeInvalidToken, "User not found")
+        return
+    }
+    if user.DisabledAt != nil {
+        response.AbortCode(c, http.StatusForbidden, response.CodeAccountDisabled, "Account is disabled")
+        return
+    }
+
+    c.Set("userID", userID) // Simpan user_id di context
+    c.Set("userRole", user.Role)
+    c.Request = c.Request.WithContext(logger.WithAttrs(c.Request.Context(), slog.Uint64("user_id", uint64(userID))))
+    trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.Int64("enduser.id", int64(userID)))
 
-        // Ambil claims dari token
-        claims := token.Claims.(jwt.MapClaims)
-        userID := uint(claims["user_id"].(float64))
-        c.Set("userID", userID) // Simpan user_id di context
+    c.Next()
+}
+
+// RequireRole only lets users with the given role through. Use it after
+// AuthMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if c.GetString("userRole") != role {
+            response.AbortCode(c, http.StatusForbidden, response.CodeForbidden, "You are not allowed to access this resource")
+            return
+        }
+        c.Next()
+    }
+}
 
+// StaticToken only lets requests through that send the given bearer token,
+// e.g. Prometheus scraping /metrics
+func StaticToken(token string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+        if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+            response.AbortCode(c, http.StatusUnauthorized, response.CodeUnauthorized, "Missing or invalid token")
+            return
+        }
         c.Next()
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+    "fmt"
+    "io"
+    "log/slog"
+    "net/http"
+    "net/url"
+    "runtime/debug"
+    "strings"
+    "time"
+
+    "go-learn-platform/internal/pkg/config"
+    "go-learn-platform/internal/pkg/response"
+
+    "github.com/gin-gonic/gin"
+)
+
+// AccessLog logs every request after it was handled. With the structured
+// format a record goes through slog, including the request and user ID;
+// combined writes an Apache combined log line, followed by the duration in
+// milliseconds and the request ID, to out. Query parameters listed in
+// cfg.RedactParams are replaced by REDACTED.
+func AccessLog(cfg config.LogConfig, out io.Writer) gin.HandlerFunc {
+    if cfg.AccessLog == "off" {
+        return func(c *gin.Context) { c.Next() }
+    }
+
+    redact := make(map[string]bool, len(cfg.RedactParams))
+    for _, param := range cfg.RedactParams {
+        redact[strings.ToLower(param)] = true
+    }
+
+    return func(c *gin.Context) {
+        start := time.Now()
+        c.Next()
+        duration := time.Since(start)
+
+        path := c.Request.URL.Path
+        query := redactQuery(c.Request.URL.RawQuery, redact)
+
+        if cfg.AccessLog == "combined" {
+            target := path
+            if query != "" {
+                target += "?" + query
+            }
+            user := "-"
+            if userID, ok := c.Get("userID"); ok {
+                user = fmt.Sprint(userID)
+            }
+            fmt.Fprintf(out, "%s - %s [%s] %q %d %d %q %q %d %s\n",
+                c.ClientIP(), user, start.Format("02/Jan/2006:15:04:05 -0700"),
+                c.Request.Method+" "+target+" "+c.Request.Proto,
+                c.Writer.Status(), max(c.Writer.Size(), 0),
+                c.Request.Referer(), c.Request.UserAgent(),
+                duration.Milliseconds(), c.GetString(response.RequestIDKey))
+            return
+        }
+
+        // Level mengikuti status: 5xx error, 4xx warning
+        level := slog.LevelInfo
+        switch status := c.Writer.Status(); {
+        case status >= http.StatusInternalServerError:
+            level = slog.LevelError
+        case status >= http.StatusBadRequest:
+            level = slog.LevelWarn
+        }
+
+        attrs := []slog.Attr{
+            slog.String("method", c.Request.Method),
+            slog.String("path", path),
+            slog.String("route", c.FullPath()),
+            slog.Int("status", c.Writer.Status()),
+            slog.Int("bytes", max(c.Writer.Size(), 0)),
+            slog.Float64("duration_ms", float64(duration.Microseconds())/1000),
+            slog.String("client_ip", c.ClientIP()),
+            slog.String("user_agent", c.Request.UserAgent()),
+        }
+        if query != "" {
+            attrs = append(attrs, slog.String("query", query))
+        }
+        if len(c.Errors) > 0 {
+            attrs = append(attrs, slog.String("errors", c.Errors.String()))
+        }
+        slog.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+    }
+}
+
+// redactQuery hides the values of sensitive parameters while keeping the
+// order of the raw query
+func redactQuery(rawQuery string, redact map[string]bool) string {
+    if rawQuery == "" || len(redact) == 0 {
+        return rawQuery
+    }
+
+    pairs := strings.Split(rawQuery, "&")
+    for i, pair := range pairs {
+        key, _, _ := strings.Cut(pair, "=")
+        if name, err := url.QueryUnescape(key); err == nil && redact[strings.ToLower(name)] {
+            pairs[i] = key + "=REDACTED"
+        }
+    }
+    return strings.Join(pairs, "&")
+}
+
+// Recovery turns a panic into a 500 error envelope and logs it with the
+// stack trace
+func Recovery() gin.HandlerFunc {
+    return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err interface{}) {
+        slog.ErrorContext(c.Request.Context(), "panic recovered",
+            slog.Any("error", err),
+            slog.String("stack", string(debug.Stack())))
+        response.AbortCode(c, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+    })
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCombinedAccessLog(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    var out bytes.Buffer
+    cfg := config.LogConfig{AccessLog: "combined", RedactParams: []string{"token"}}
+
+    r := gin.New()
+    r.Use(RequestID(), AccessLog(cfg, &out))
+    r.GET("/media/*filepath", func(c *gin.Context) {
+        c.Set("userID", uint(7))
+        c.String(http.StatusOK, "ok")
+    })
+
+    req := httptest.NewRequest(http.MethodGet, "/media/a.png?token=rahasia&w=10", nil)
+    req.Header.Set(RequestIDHeader, "req-9")
+    req.Header.Set("User-Agent", "curl/8")
+    r.ServeHTTP(httptest.NewRecorder(), req)
+
+    line := out.String()
+    for _, want := range []string{
+        ` - 7 [`,
+        `"GET /media/a.png?token=REDACTED&w=10 HTTP/1.1" 200 2 "" "curl/8" `,
+        ` req-9` + "\n",
+    } {
+        if !strings.Contains(line, want) {
+            t.Fatalf("expected %q in access log line %q", want, line)
+        }
+    }
+}
+
+func TestRecoveryLogsPanics(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    var logs bytes.Buffer
+    previous := slog.Default()
+    slog.SetDefault(logger.New(&logs, config.LogConfig{Format: "json"}))
+    defer slog.SetDefault(previous)
+
+    r := gin.New()
+    r.Use(RequestID(), Recovery())
+    r.GET("/boom", func(c *gin.Context) { panic("kaboom") })
+
+    rec := httptest.NewRecorder()
+    r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+    if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"code":"internal_error"`) {
+        t.Fatalf("expected a 500 error envelope, got %d %s", rec.Code, rec.Body)
+    }
+    if !strings.Contains(logs.String(), `"msg":"panic recovered"`) || !strings.Contains(logs.String(), `"error":"kaboom"`) ||
+        !strings.Contains(logs.String(), `"request_id":"`) {
+        t.Fatalf("panic was not logged with the request ID: %s", logs.String())
+    }
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+    "log/slog"
+    "math"
+    "net/http"
+    "strconv"
+    "time"
+
+    "go-learn-platform/internal/pkg/metrics"
+    "go-learn-platform/internal/pkg/ratelimit"
+    "go-learn-platform/internal/pkg/response"
+
+    "github.com/gin-gonic/gin"
+)
+
+// RateLimit takes a token from the bucket of the client in group for every
+// request. Clients are the authenticated user when AuthMiddleware ran before,
+// otherwise the client IP. The budget is reported in X-RateLimit-* headers;
+// once it is used up the request is refused with 429 and Retry-After. When
+// the store fails the request is let through.
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        key := group + ":ip:" + c.ClientIP()
+        if userID := c.GetUint("userID"); userID != 0 {
+            key = group + ":user:" + strconv.FormatUint(uint64(userID), 10)
+        }
+
+        res, err := store.Take(c.Request.Context(), key, limit)
+        if err != nil {
+            slog.WarnContext(c.Request.Context(), "rate limit store failed", "group", group, "error", err)
+            c.Next()
+            return
+        }
+
+        c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+        c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+        c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+        if !res.Allowed {
+            metrics.RateLimited(group)
+            c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+            response.AbortCode(c, http.StatusTooManyRequests, response.CodeRateLimited, "Too many requests, please try again later")
+            return
+        }
+        c.Next()
+    }
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int {
+    return int(math.Ceil(d.Seconds()))
+}
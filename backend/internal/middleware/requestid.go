@@ -0,0 +1,49 @@
+package middleware
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "log/slog"
+
+    "go-learn-platform/internal/pkg/logger"
+    "go-learn-platform/internal/pkg/response"
+
+    "github.com/gin-gonic/gin"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID of a request between services
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID keeps the X-Request-ID sent by a proxy or generates a new one,
+// stores it in the context, the log attributes and the trace span of the
+// request and echoes it in the response
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id := c.GetHeader(RequestIDHeader)
+        if id == "" || len(id) > 128 {
+            id = newRequestID()
+        }
+
+        c.Set(response.RequestIDKey, id)
+
+        // Hubungkan log dengan trace jika request sedang di-trace
+        ctx := c.Request.Context()
+        attrs := []slog.Attr{slog.String("request_id", id)}
+        if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+            span.SetAttributes(attribute.String("http.request_id", id))
+            attrs = append(attrs, slog.String("trace_id", span.SpanContext().TraceID().String()))
+        }
+        c.Request = c.Request.WithContext(logger.WithAttrs(ctx, attrs...))
+        c.Header(RequestIDHeader, id)
+        c.Next()
+    }
+}
+
+// newRequestID generates a random 128 bit identifier
+func newRequestID() string {
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+
+    "go-learn-platform/internal/pkg/config"
+
+    "github.com/gin-contrib/cors"
+    "github.com/gin-gonic/gin"
+)
+
+// CORS answers preflight requests and sets the CORS headers for the origins,
+// methods and credentials of cfg. Origins may use a wildcard for subdomains,
+// e.g. https://*.example.com; "*" allows every origin.
+func CORS(cfg config.ServerConfig) gin.HandlerFunc {
+    corsConfig := cors.Config{
+        AllowMethods:     cfg.CORSMethods,
+        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept-Language", "Range", "Upload-Offset", "Upload-Checksum", "If-Match", "If-None-Match", RequestIDHeader},
+        ExposeHeaders:    []string{"Content-Length", "Content-Range", "Accept-Ranges", "Location", "Upload-Offset", "Upload-Length", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "ETag", RequestIDHeader},
+        AllowCredentials: cfg.CORSCredentials,
+        MaxAge:           cfg.CORSMaxAge,
+    }
+    if containsString(cfg.CORSOrigins, "*") {
+        corsConfig.AllowAllOrigins = true
+    } else {
+        corsConfig.AllowOriginFunc = func(origin string) bool {
+            return AllowedOrigin(cfg.CORSOrigins, origin)
+        }
+    }
+    return cors.New(corsConfig)
+}
+
+// AllowedOrigin reports whether origin matches one of the configured origins.
+// A pattern like https://*.example.com matches every subdomain of
+// example.com, but not example.com itself.
+func AllowedOrigin(allowed []string, origin string) bool {
+    origin = strings.ToLower(origin)
+    for _, pattern := range allowed {
+        pattern = strings.ToLower(strings.TrimRight(pattern, "/"))
+        prefix, suffix, wildcard := strings.Cut(pattern, "*")
+        if !wildcard {
+            if origin == pattern {
+                return true
+            }
+            continue
+        }
+
+        if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) || len(origin) <= len(prefix)+len(suffix) {
+            continue
+        }
+        // Bagian wildcard hanya boleh berisi label subdomain, bukan port atau path
+        subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+        if strings.Trim(subdomain, "abcdefghijklmnopqrstuvwxyz0123456789-.") == "" && !strings.HasPrefix(subdomain, ".") {
+            return true
+        }
+    }
+    return false
+}
+
+// SecurityHeaders sets the security headers of cfg on every response. HSTS is
+// only sent over HTTPS, directly or behind a proxy setting X-Forwarded-Proto.
+func SecurityHeaders(cfg config.SecurityConfig) gin.HandlerFunc {
+    hsts := ""
+    if cfg.HSTSMaxAge > 0 {
+        hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+        if cfg.HSTSIncludeSubdomains {
+            hsts += "; includeSubDomains"
+        }
+    }
+
+    return func(c *gin.Context) {
+        header := c.Writer.Header()
+        header.Set("X-Content-Type-Options", "nosniff")
+        header.Set("X-Frame-Options", cfg.FrameOptions)
+        header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+        if cfg.ContentSecurityPolicy != "" {
+            header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+        }
+        if hsts != "" && isHTTPS(c.Request) {
+            header.Set("Strict-Transport-Security", hsts)
+        }
+        c.Next()
+    }
+}
+
+// ContentSecurityPolicy replaces the policy set by SecurityHeaders for the
+// routes it is added to, e.g. uploaded files or the documentation page
+func ContentSecurityPolicy(policy string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if policy != "" {
+            c.Header("Content-Security-Policy", policy)
+        }
+        c.Next()
+    }
+}
+
+// isHTTPS reports whether the client connected over HTTPS
+func isHTTPS(r *http.Request) bool {
+    return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+    for _, value := range values {
+        if value == s {
+            return true
+        }
+    }
+    return false
+}
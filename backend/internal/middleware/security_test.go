@@ -0,0 +1,22 @@
+package middleware
+
+import "testing"
+
+func TestAllowedOrigin(t *testing.T) {
+    allowed := []string{"http://localhost:5173", "https://*.example.com"}
+    for origin, want := range map[string]bool{
+        "http://localhost:5173":            true,
+        "http://localhost:5174":            false,
+        "https://app.example.com":          true,
+        "https://PR-7.Staging.example.com": true,
+        "https://example.com":              false,
+        "http://app.example.com":           false,
+        "https://evil.com?.example.com":    false,
+        "https://app.example.com.evil":     false,
+        "https://.example.com":             false,
+    } {
+        if got := AllowedOrigin(allowed, origin); got != want {
+            t.Errorf("AllowedOrigin(%q) = %v, want %v", origin, got, want)
+        }
+    }
+}
@@ -4,16 +4,20 @@ import (
     "crypto/rand"
     "encoding/hex"
     "fmt"
+    "mime/multipart"
     "os"
     "path/filepath"
 
+    "go-learn-platform/internal/pkg/media"
+    "go-learn-platform/internal/pkg/metrics"
+    "go-learn-platform/internal/pkg/tracing"
+
     "github.com/gin-gonic/gin"
+    "go.opentelemetry.io/otel/attribute"
 )
 
 // UploadFile handles file uploads and stores them in the public folder
 func UploadFile(c *gin.Context, field string) (string, error) {
-    fmt.Println("In upload middleware")
-
     // Get the uploaded file
     file, err := c.FormFile(field)
     if err != nil {
@@ -21,31 +25,62 @@ func UploadFile(c *gin.Context, field string) (string, error) {
     }
 
     // Create the public directory if it doesn't exist
-    uploadDir := "./public"
+    uploadDir := media.PublicDir()
     if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
         if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
             return "", fmt.Errorf("failed to create upload directory: %w", err)
         }
     }
 
-    fmt.Println("Generate unique filename")
     // Generate a unique filename
     ext := filepath.Ext(file.Filename)
     uniqueName := fmt.Sprintf("%s%s", generateUniqueID(), ext)
     filePath := filepath.Join(uploadDir, uniqueName)
 
-    fmt.Println("Save uploaded file")
     // Save the file
-    if err := c.SaveUploadedFile(file, filePath); err != nil {
+    if err := saveFile(c, file, filePath, "public"); err != nil {
         return "", fmt.Errorf("failed to save file: %w", err)
     }
-
-    fmt.Println("Done upload middleware")
+    metrics.AddUploadBytes("image", file.Size)
 
     // Return the relative URL for the file
     return fmt.Sprintf("/public/%s", uniqueName), nil
 }
 
+// UploadPrivateFile handles file uploads that must not be world-readable. The
+// file is stored outside the public folder and can only be downloaded through
+// a signed /media URL.
+func UploadPrivateFile(c *gin.Context, field string) (string, error) {
+    file, err := c.FormFile(field)
+    if err != nil {
+        return "", fmt.Errorf("failed to retrieve file: %w", err)
+    }
+
+    uploadDir := media.PrivateDir()
+    if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+        return "", fmt.Errorf("failed to create upload directory: %w", err)
+    }
+
+    ext := filepath.Ext(file.Filename)
+    uniqueName := fmt.Sprintf("%s%s", generateUniqueID(), ext)
+    if err := saveFile(c, file, filepath.Join(uploadDir, uniqueName), "private"); err != nil {
+        return "", fmt.Errorf("failed to save file: %w", err)
+    }
+    metrics.AddUploadBytes("image", file.Size)
+
+    return fmt.Sprintf("/media/private/%s", uniqueName), nil
+}
+
+// saveFile writes an uploaded file to disk inside a storage span
+func saveFile(c *gin.Context, file *multipart.FileHeader, path, area string) error {
+    _, span := tracing.Start(c.Request.Context(), "storage.save",
+        attribute.String("storage.area", area),
+        attribute.Int64("file.size", file.Size))
+    err := c.SaveUploadedFile(file, path)
+    tracing.End(span, err)
+    return err
+}
+
 // generateUniqueID generates a unique identifier using random bytes
 func generateUniqueID() string {
     b := make([]byte, 16) // 16 bytes = 128 bits
@@ -0,0 +1,386 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// files holds the SQL migrations, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql where version is a UTC timestamp (YYYYMMDDHHMMSS)
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// DefaultDir is where `migrate create` writes new files, relative to backend/
+const DefaultDir = "internal/migrations/sql"
+
+// lockKey is the Postgres advisory lock key held while migrating, so only one
+// instance applies migrations when several boot at the same time
+const lockKey int64 = 0x676f6c6561726e // "golearn"
+
+// ErrPending is returned by EnsureUpToDate when migrations are not applied yet
+var ErrPending = errors.New("database schema is not up to date")
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change
+type Migration struct {
+    Version int64
+    Name    string
+    Up      string
+    Down    string
+}
+
+// Status describes a migration and when it was applied, if at all
+type Status struct {
+    Migration
+    AppliedAt *time.Time
+}
+
+// Migrator applies and rolls back migrations and records them in the
+// schema_migrations table
+type Migrator struct {
+    db         *sql.DB
+    postgres   bool
+    migrations []Migration
+    Logf       func(format string, args ...interface{})
+}
+
+// New creates a Migrator for the embedded migrations
+func New(db *gorm.DB) (*Migrator, error) {
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+
+    migrations, err := Load(files)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Migrator{
+        db:         sqlDB,
+        postgres:   db.Dialector.Name() == "postgres",
+        migrations: migrations,
+        Logf:       func(string, ...interface{}) {},
+    }, nil
+}
+
+// Load parses the migrations of a file system, sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+    entries, err := fs.ReadDir(fsys, "sql")
+    if err != nil {
+        return nil, err
+    }
+
+    byVersion := map[int64]*Migration{}
+    for _, entry := range entries {
+        match := fileName.FindStringSubmatch(entry.Name())
+        if match == nil {
+            return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+        }
+
+        version, _ := strconv.ParseInt(match[1], 10, 64)
+        body, err := fs.ReadFile(fsys, "sql/"+entry.Name())
+        if err != nil {
+            return nil, err
+        }
+
+        m, ok := byVersion[version]
+        if !ok {
+            m = &Migration{Version: version, Name: match[2]}
+            byVersion[version] = m
+        }
+        if m.Name != match[2] {
+            return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+        }
+        if match[3] == "up" {
+            m.Up = string(body)
+        } else {
+            m.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, m := range byVersion {
+        if m.Up == "" {
+            return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+        }
+        migrations = append(migrations, *m)
+    }
+    sort.Slice(migrations, func(i, j int) bool {
+        return migrations[i].Version < migrations[j].Version
+    })
+    return migrations, nil
+}
+
+// Up applies pending migrations in order. steps limits how many are applied;
+// zero applies all of them.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+    var done []Migration
+    err := m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := m.applied(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for _, migration := range m.migrations {
+            if _, ok := applied[migration.Version]; ok {
+                continue
+            }
+            if steps > 0 && len(done) == steps {
+                break
+            }
+
+            m.Logf("Applying migration %d_%s", migration.Version, migration.Name)
+            err := m.inTx(ctx, conn, migration.Up,
+                m.rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+                migration.Version, migration.Name, time.Now().UTC())
+            if err != nil {
+                return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+            }
+            done = append(done, migration)
+        }
+        return nil
+    })
+    return done, err
+}
+
+// Down rolls back the most recently applied migrations. steps defaults to one.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+    if steps <= 0 {
+        steps = 1
+    }
+
+    var done []Migration
+    err := m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := m.applied(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+            migration := m.migrations[i]
+            if _, ok := applied[migration.Version]; !ok {
+                continue
+            }
+            if migration.Down == "" {
+                return fmt.Errorf("migration %d_%s cannot be rolled back: no down file", migration.Version, migration.Name)
+            }
+
+            m.Logf("Rolling back migration %d_%s", migration.Version, migration.Name)
+            err := m.inTx(ctx, conn, migration.Down,
+                m.rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+            if err != nil {
+                return fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+            }
+            done = append(done, migration)
+        }
+        return nil
+    })
+    return done, err
+}
+
+// Status lists every known migration with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer conn.Close()
+
+    applied, err := m.applied(ctx, conn)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]Status, 0, len(m.migrations))
+    for _, migration := range m.migrations {
+        status := Status{Migration: migration}
+        if at, ok := applied[migration.Version]; ok {
+            at := at
+            status.AppliedAt = &at
+        }
+        statuses = append(statuses, status)
+    }
+    return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+    statuses, err := m.Status(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var pending []Migration
+    for _, status := range statuses {
+        if status.AppliedAt == nil {
+            pending = append(pending, status.Migration)
+        }
+    }
+    return pending, nil
+}
+
+// EnsureUpToDate returns an error wrapping ErrPending when the database is
+// missing migrations, so the server refuses to start on an old schema
+func EnsureUpToDate(ctx context.Context, db *gorm.DB) error {
+    migrator, err := New(db)
+    if err != nil {
+        return err
+    }
+
+    pending, err := migrator.Pending(ctx)
+    if err != nil {
+        return err
+    }
+    if len(pending) > 0 {
+        names := make([]string, 0, len(pending))
+        for _, migration := range pending {
+            names = append(names, fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+        }
+        return fmt.Errorf("%w: %d pending migration(s): %s", ErrPending, len(pending), strings.Join(names, ", "))
+    }
+    return nil
+}
+
+// Create writes an empty up/down migration pair into dir
+func Create(dir, name string) (string, string, error) {
+    name = strings.ToLower(strings.TrimSpace(name))
+    name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+    name = strings.Trim(name, "_")
+    if name == "" {
+        return "", "", errors.New("migration name is required")
+    }
+
+    if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+        return "", "", err
+    }
+
+    base := fmt.Sprintf("%s_%s", time.Now().UTC().Format("20060102150405"), name)
+    upPath := filepath.Join(dir, base+".up.sql")
+    downPath := filepath.Join(dir, base+".down.sql")
+
+    if err := os.WriteFile(upPath, []byte("-- Write the schema change here.\n"), 0o644); err != nil {
+        return "", "", err
+    }
+    if err := os.WriteFile(downPath, []byte("-- Write the statements that undo the up migration here.\n"), 0o644); err != nil {
+        return "", "", err
+    }
+    return upPath, downPath, nil
+}
+
+// withLock runs fn on a dedicated connection while holding the advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+
+    if m.postgres {
+        m.Logf("Waiting for migration lock")
+        if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+            return fmt.Errorf("failed to acquire migration lock: %w", err)
+        }
+        defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+    }
+
+    if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT PRIMARY KEY,
+        name TEXT NOT NULL,
+        applied_at TIMESTAMP NOT NULL
+    )`); err != nil {
+        return fmt.Errorf("failed to create schema_migrations table: %w", err)
+    }
+
+    return fn(conn)
+}
+
+// applied returns the applied versions; a missing table means none are applied
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+    applied := map[int64]time.Time{}
+
+    rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+    if err != nil {
+        if m.tableMissing(ctx, conn) {
+            return applied, nil
+        }
+        return nil, err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var version int64
+        var at time.Time
+        if err := rows.Scan(&version, &at); err != nil {
+            return nil, err
+        }
+        applied[version] = at
+    }
+    return applied, rows.Err()
+}
+
+// tableMissing reports whether the schema_migrations table does not exist
+func (m *Migrator) tableMissing(ctx context.Context, conn *sql.Conn) bool {
+    query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+    if m.postgres {
+        query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = 'schema_migrations'"
+    }
+
+    var count int
+    if err := conn.QueryRowContext(ctx, query).Scan(&count); err != nil {
+        return false
+    }
+    return count == 0
+}
+
+// inTx runs a migration body and its bookkeeping statement in one transaction
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, body string, record string, args ...interface{}) error {
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+
+    if _, err := tx.ExecContext(ctx, body); err != nil {
+        tx.Rollback()
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+        tx.Rollback()
+        return err
+    }
+    return tx.Commit()
+}
+
+// rebind converts ? placeholders to $n for Postgres
+func (m *Migrator) rebind(query string) string {
+    if !m.postgres {
+        return query
+    }
+
+    var b strings.Builder
+    n := 0
+    for _, r := range query {
+        if r == '?' {
+            n++
+            b.WriteString("$" + strconv.Itoa(n))
+            continue
+        }
+        b.WriteRune(r)
+    }
+    return b.String()
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS lesson_progress;
+DROP TABLE IF EXISTS upload_sessions;
+DROP TABLE IF EXISTS lesson_videos;
+DROP TABLE IF EXISTS quiz_results;
+DROP TABLE IF EXISTS quizzes;
+DROP TABLE IF EXISTS enrollments;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS courses;
+DROP TABLE IF EXISTS profiles;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,150 @@
+-- Skema awal, setara dengan hasil GORM AutoMigrate sebelumnya.
+-- IF NOT EXISTS dipakai supaya database lama yang dibuat oleh AutoMigrate
+-- bisa langsung diadopsi tanpa error.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    google_id text,
+    email text,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_google_id UNIQUE (google_id),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS profiles (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint,
+    name text,
+    image text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_profile FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT uni_profiles_user_id UNIQUE (user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_profiles_deleted_at ON profiles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS courses (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title text NOT NULL,
+    description text NOT NULL,
+    user_id bigint NOT NULL,
+    image text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_courses FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_courses_deleted_at ON courses (deleted_at);
+
+CREATE TABLE IF NOT EXISTS lessons (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    course_id bigint NOT NULL,
+    title text NOT NULL,
+    content text NOT NULL,
+    "order" bigint NOT NULL,
+    image text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_courses_lessons FOREIGN KEY (course_id) REFERENCES courses(id)
+);
+CREATE INDEX IF NOT EXISTS idx_lessons_deleted_at ON lessons (deleted_at);
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    course_id bigint NOT NULL,
+    progress decimal DEFAULT 0,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_courses_enrollments FOREIGN KEY (course_id) REFERENCES courses(id),
+    CONSTRAINT fk_users_enrollments FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_enrollments_deleted_at ON enrollments (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quizzes (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    lesson_id bigint NOT NULL,
+    question text NOT NULL,
+    options text NOT NULL,
+    answer text NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_lessons_quizzes FOREIGN KEY (lesson_id) REFERENCES lessons(id)
+);
+CREATE INDEX IF NOT EXISTS idx_quizzes_deleted_at ON quizzes (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quiz_results (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    quiz_id bigint NOT NULL,
+    score bigint NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_quizzes_results FOREIGN KEY (quiz_id) REFERENCES quizzes(id)
+);
+CREATE INDEX IF NOT EXISTS idx_quiz_results_deleted_at ON quiz_results (deleted_at);
+
+CREATE TABLE IF NOT EXISTS lesson_videos (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    lesson_id bigint NOT NULL,
+    path text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL,
+    duration_seconds decimal NOT NULL,
+    checksum text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_lessons_video FOREIGN KEY (lesson_id) REFERENCES lessons(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lesson_videos_lesson_id ON lesson_videos (lesson_id);
+CREATE INDEX IF NOT EXISTS idx_lesson_videos_deleted_at ON lesson_videos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id varchar(32),
+    created_at timestamptz,
+    updated_at timestamptz,
+    user_id bigint NOT NULL,
+    lesson_id bigint NOT NULL,
+    filename text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL,
+    "offset" bigint NOT NULL DEFAULT 0,
+    duration_seconds decimal,
+    checksum text,
+    completed_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_user_id ON upload_sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS lesson_progress (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    lesson_id bigint NOT NULL,
+    watched_seconds decimal NOT NULL DEFAULT 0,
+    last_position decimal NOT NULL DEFAULT 0,
+    last_heartbeat timestamptz,
+    completed_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lesson_progress_user_lesson ON lesson_progress (user_id, lesson_id);
+CREATE INDEX IF NOT EXISTS idx_lesson_progress_deleted_at ON lesson_progress (deleted_at);
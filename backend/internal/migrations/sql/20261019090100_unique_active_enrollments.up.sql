@@ -0,0 +1,16 @@
+-- Satu user hanya boleh punya satu enrollment aktif per kursus.
+-- Duplikat lama (hasil race saat enroll) di-soft delete, yang tertua dipertahankan.
+UPDATE enrollments AS e
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE e.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM enrollments AS older
+    WHERE older.user_id = e.user_id
+      AND older.course_id = e.course_id
+      AND older.deleted_at IS NULL
+      AND older.id < e.id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_active_user_course
+    ON enrollments (user_id, course_id)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,22 @@
+-- Antrian job latar belakang. Worker mengambil job dengan
+-- SELECT ... FOR UPDATE SKIP LOCKED; job yang gagal terus berstatus dead.
+CREATE TABLE IF NOT EXISTS jobs (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    kind text NOT NULL,
+    payload text NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    max_attempts bigint NOT NULL DEFAULT 0,
+    run_at timestamptz NOT NULL,
+    locked_at timestamptz,
+    locked_by text,
+    last_error text,
+    finished_at timestamptz,
+    unique_key text,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_jobs_unique_key UNIQUE (unique_key)
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_kind ON jobs (status, kind);
+CREATE INDEX IF NOT EXISTS idx_jobs_run_at ON jobs (run_at);
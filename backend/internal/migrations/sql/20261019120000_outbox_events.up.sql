@@ -0,0 +1,11 @@
+-- Outbox event domain. Event ditulis dalam transaksi yang sama dengan
+-- perubahannya, lalu relay meneruskannya ke subscriber lewat antrian job.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id bigserial,
+    created_at timestamptz,
+    name text NOT NULL,
+    payload text NOT NULL,
+    published_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at);
@@ -0,0 +1,40 @@
+-- Webhook keluar dan log pengirimannya
+CREATE TABLE IF NOT EXISTS webhooks (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    course_id bigint,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT fk_webhooks_course FOREIGN KEY (course_id) REFERENCES courses(id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_deleted_at ON webhooks (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_course_id ON webhooks (course_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial,
+    created_at timestamptz,
+    updated_at timestamptz,
+    webhook_id bigint NOT NULL,
+    event_id bigint NOT NULL,
+    event text NOT NULL,
+    payload text NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    status_code bigint,
+    response text,
+    error text,
+    duration_ms bigint,
+    delivered_at timestamptz,
+    redelivery_of bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
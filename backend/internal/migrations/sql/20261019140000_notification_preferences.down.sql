@@ -0,0 +1,3 @@
+ALTER TABLE profiles DROP COLUMN email_quiz_results;
+ALTER TABLE profiles DROP COLUMN email_new_lessons;
+ALTER TABLE profiles DROP COLUMN email_enrollments;
@@ -0,0 +1,3 @@
+ALTER TABLE profiles ADD COLUMN email_enrollments boolean NOT NULL DEFAULT true;
+ALTER TABLE profiles ADD COLUMN email_new_lessons boolean NOT NULL DEFAULT true;
+ALTER TABLE profiles ADD COLUMN email_quiz_results boolean NOT NULL DEFAULT true;
@@ -0,0 +1,13 @@
+-- Kotak masuk notifikasi in-app
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial,
+    created_at timestamptz,
+    user_id bigint NOT NULL,
+    dedupe_key text NOT NULL,
+    type text NOT NULL,
+    payload text NOT NULL,
+    read_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_dedupe_key ON notifications (user_id, dedupe_key);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_courses_seed_key;
+ALTER TABLE courses DROP COLUMN IF EXISTS seed_key;
@@ -0,0 +1,3 @@
+-- Kursus dari fixture seed dicocokkan lewat key-nya, bukan judul
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS seed_key text;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_courses_seed_key ON courses (seed_key);
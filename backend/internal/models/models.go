@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
 
 // Course represents the course table
 type Course struct {
@@ -9,6 +13,7 @@ type Course struct {
     Description string   `gorm:"not null"` // Deskripsi kursus
     UserID      uint     `gorm:"not null"` // ID pengguna (pembuat kursus)
     Image       string   // URL of the course image
+    SeedKey     *string  `gorm:"uniqueIndex:idx_courses_seed_key"` // Key fixture seed, kosong untuk kursus biasa
     Lessons     []Lesson `gorm:"foreignKey:CourseID"` // Relasi one-to-many dengan Lesson
     Enrollments []Enrollment `gorm:"foreignKey:CourseID"` // Relasi one-to-many dengan Enrollment
     User        User     `gorm:"foreignKey:UserID"` // Relasi ke User
@@ -22,14 +27,31 @@ type User struct {
     Profile  Profile  `gorm:"foreignKey:UserID"` // Relasi one-to-one dengan Profile
     Courses  []Course `gorm:"foreignKey:UserID"`  // Relasi one-to-many dengan Course (sebagai instruktur)
     Enrollments []Enrollment `gorm:"foreignKey:UserID"` // Tambahkan di struct User
+    Role        string     `gorm:"not null;default:user"` // user atau admin
+    DisabledAt  *time.Time // Diisi jika akun dinonaktifkan oleh admin
 }
 
+// User roles
+const (
+    RoleUser  = "user"
+    RoleAdmin = "admin"
+)
+
+// SeedGoogleIDPrefix marks users created from seed fixtures instead of a real
+// Google login. Google IDs are numeric, so the prefix never collides.
+const SeedGoogleIDPrefix = "seed:"
+
 // Profile represents the profile table
 type Profile struct {
     gorm.Model
     UserID uint   `gorm:"unique"` // Foreign key to User
     Name   string // Full name of the user
     Image  string // URL of the profile image
+
+    // Email notifikasi yang ingin diterima, semuanya aktif secara default
+    EmailEnrollments bool `gorm:"not null;default:true"`
+    EmailNewLessons  bool `gorm:"not null;default:true"`
+    EmailQuizResults bool `gorm:"not null;default:true"`
 }
 
 // Lesson represents the lesson table
@@ -41,13 +63,59 @@ type Lesson struct {
     Order    int    `gorm:"not null"`
     Image    string // URL of the lesson image
     Quizzes  []Quiz `gorm:"foreignKey:LessonID"`
+    Video    *LessonVideo `gorm:"foreignKey:LessonID"` // Video pelajaran (opsional)
+}
+
+// LessonVideo stores the metadata of the video attached to a lesson
+type LessonVideo struct {
+    gorm.Model
+    LessonID        uint    `gorm:"uniqueIndex;not null"`
+    Path            string  `gorm:"not null" json:"-"` // Lokasi file di storage
+    ContentType     string  `gorm:"not null"`
+    Size            int64   `gorm:"not null"`
+    DurationSeconds float64 `gorm:"not null"` // Durasi video dalam detik
+    Checksum        string  // SHA-256 (hex) dari file lengkap
+    URL             string  `gorm:"-"` // URL bertanda tangan untuk pemutaran, diisi saat response
+}
+
+// UploadSession tracks a resumable, chunked upload of a lesson video
+type UploadSession struct {
+    ID              string `gorm:"primaryKey;size:32"`
+    CreatedAt       time.Time
+    UpdatedAt       time.Time
+    UserID          uint    `gorm:"not null;index"`
+    LessonID        uint    `gorm:"not null"`
+    Filename        string  `gorm:"not null"`
+    ContentType     string  `gorm:"not null"`
+    Size            int64   `gorm:"not null"` // Total ukuran file dalam byte
+    Offset          int64   `gorm:"not null;default:0"` // Jumlah byte yang sudah diterima
+    DurationSeconds float64
+    Checksum        string  // SHA-256 (hex) yang diharapkan, opsional
+    CompletedAt     *time.Time
+}
+
+// LessonProgress tracks how far a user got through a lesson
+type LessonProgress struct {
+    gorm.Model
+    UserID         uint    `gorm:"not null;uniqueIndex:idx_lesson_progress_user_lesson"`
+    LessonID       uint    `gorm:"not null;uniqueIndex:idx_lesson_progress_user_lesson"`
+    WatchedSeconds float64 `gorm:"not null;default:0"` // Total detik video yang benar-benar ditonton
+    LastPosition   float64 `gorm:"not null;default:0"` // Posisi pemutaran terakhir (detik)
+    LastHeartbeat  *time.Time
+    CompletedAt    *time.Time
+}
+
+// TableName keeps the table name used by the progress queries
+func (LessonProgress) TableName() string {
+    return "lesson_progress"
 }
 
 // Enrollment represents the enrollment table
 type Enrollment struct {
     gorm.Model
-    UserID   uint    `gorm:"not null"`
-    CourseID uint    `gorm:"not null"`
+    // Satu enrollment aktif per user dan kursus, sama dengan index di migrasi
+    UserID   uint    `gorm:"not null;uniqueIndex:idx_enrollments_active_user_course,where:deleted_at IS NULL"`
+    CourseID uint    `gorm:"not null;uniqueIndex:idx_enrollments_active_user_course,where:deleted_at IS NULL"`
     Progress float64 `gorm:"default:0"` // Progress in percentage (0-100)
     Course   Course  `gorm:"foreignKey:CourseID"`
 }
@@ -70,9 +138,103 @@ type QuizResult struct {
     Score  int  `gorm:"not null"`
 }
 
-// Migrate runs database migrations for all models
-func Migrate(db *gorm.DB) error {
-    return db.AutoMigrate(
+// Job is a unit of background work in the queue. Payload holds the JSON
+// encoded arguments of the job kind.
+type Job struct {
+    ID          uint `gorm:"primaryKey"`
+    CreatedAt   time.Time
+    UpdatedAt   time.Time
+    Kind        string     `gorm:"not null;index:idx_jobs_status_kind,priority:2"`
+    Payload     string     `gorm:"not null"`
+    Status      string     `gorm:"not null;default:pending;index:idx_jobs_status_kind,priority:1"`
+    Attempts    int        `gorm:"not null;default:0"`
+    MaxAttempts int        `gorm:"not null;default:0"` // 0 = JOB_MAX_ATTEMPTS
+    RunAt       time.Time  `gorm:"not null;index"`     // Job tidak diambil sebelum waktu ini
+    LockedAt    *time.Time // Diisi selama worker menjalankan job
+    LockedBy    string
+    LastError   string
+    FinishedAt  *time.Time
+    UniqueKey   *string `gorm:"unique"` // Mencegah job ganda, mis. jadwal cron di beberapa instance
+}
+
+// Job statuses. Dead jobs failed every attempt and stay until retried.
+const (
+    JobPending = "pending"
+    JobRunning = "running"
+    JobDone    = "done"
+    JobDead    = "dead"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// that caused it. The relay hands it to the subscribers and sets PublishedAt.
+type OutboxEvent struct {
+    ID          uint `gorm:"primaryKey"`
+    CreatedAt   time.Time
+    Name        string     `gorm:"not null"` // Nama event, mis. course.created
+    Payload     string     `gorm:"not null"` // Data event dalam JSON
+    PublishedAt *time.Time `gorm:"index"`
+}
+
+// Webhook is an endpoint that receives the events it subscribed to. Admins
+// may register webhooks for all courses, course owners for their courses.
+type Webhook struct {
+    gorm.Model
+    UserID   uint   `gorm:"not null;index"` // Pembuat webhook
+    CourseID *uint  `gorm:"index"`          // nil = event dari semua kursus
+    URL      string `gorm:"not null"`
+    Secret   string `gorm:"not null" json:"-"` // Kunci HMAC untuk tanda tangan payload
+    Events   string `gorm:"not null"`          // Nama event dipisah koma
+    Active   bool   `gorm:"not null;default:true"`
+}
+
+// WebhookDelivery logs the delivery of one event to one webhook, with the
+// outcome of the last attempt
+type WebhookDelivery struct {
+    ID           uint `gorm:"primaryKey"`
+    CreatedAt    time.Time
+    UpdatedAt    time.Time
+    WebhookID    uint   `gorm:"not null;index"`
+    EventID      uint   `gorm:"not null"`
+    Event        string `gorm:"not null"`
+    Payload      string `gorm:"not null"` // Body yang dikirim
+    Status       string `gorm:"not null;default:pending"`
+    Attempts     int    `gorm:"not null;default:0"`
+    StatusCode   int    // Status HTTP dari percobaan terakhir, 0 jika tidak ada response
+    Response     string // Awal body response terakhir
+    Error        string
+    DurationMS   int64
+    DeliveredAt  *time.Time
+    RedeliveryOf *uint // Pengiriman asal jika ini pengiriman ulang
+}
+
+// Webhook delivery statuses
+const (
+    DeliveryPending   = "pending"
+    DeliverySucceeded = "succeeded"
+    DeliveryFailed    = "failed"
+)
+
+// Notification is a message in the in-app inbox of a user. Payload holds
+// the JSON data of its type, e.g. the course and lesson of a new lesson.
+type Notification struct {
+    ID        uint `gorm:"primaryKey"`
+    CreatedAt time.Time
+    UserID    uint   `gorm:"not null;uniqueIndex:idx_notifications_user_dedupe_key"`
+    DedupeKey string `gorm:"not null;uniqueIndex:idx_notifications_user_dedupe_key"` // Mencegah notifikasi ganda saat subscriber diulang
+    Type      string `gorm:"not null"`
+    Payload   string `gorm:"not null"`
+    ReadAt    *time.Time
+}
+
+// Notification types
+const (
+    NotificationLessonAdded = "lesson.added"
+    NotificationQuizGraded  = "quiz.graded"
+)
+
+// All lists every model stored in the database
+func All() []interface{} {
+    return []interface{}{
         &User{},
         &Profile{},
         &Course{},
@@ -80,5 +242,20 @@ func Migrate(db *gorm.DB) error {
         &Enrollment{},
         &Quiz{},
         &QuizResult{},
-    )
-}
\ No newline at end of file
+        &LessonVideo{},
+        &UploadSession{},
+        &LessonProgress{},
+        &Job{},
+        &OutboxEvent{},
+        &Webhook{},
+        &WebhookDelivery{},
+        &Notification{},
+    }
+}
+
+// Migrate creates the tables of all models with GORM AutoMigrate. Production
+// databases are managed by the versioned SQL files in internal/migrations;
+// this is only meant for throwaway databases such as tests.
+func Migrate(db *gorm.DB) error {
+    return db.AutoMigrate(All()...)
+}
@@ -0,0 +1,92 @@
+// Package cache keeps encoded values in process memory (LRU) or in a
+// Redis-compatible server shared by all instances.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps values for a limited time
+type Store interface {
+    // Get returns the value of key and whether it was found
+    Get(ctx context.Context, key string) ([]byte, bool, error)
+    Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+    Delete(ctx context.Context, keys ...string) error
+}
+
+// New creates the store selected by cfg.Cache.Store. Without a store
+// ("none") nothing is cached.
+func New(cfg *config.Config) (Store, error) {
+    switch cfg.Cache.Store {
+    case "memory":
+        return NewLRU(cfg.Cache.Size), nil
+    case "redis":
+        opts, err := redis.ParseURL(cfg.Redis.URL)
+        if err != nil {
+            return nil, fmt.Errorf("parse REDIS_URL: %w", err)
+        }
+        return NewRedis(redis.NewClient(opts)), nil
+    default:
+        return Nop{}, nil
+    }
+}
+
+// Fetch returns the cached value of key, or loads it and stores it for ttl.
+// Values are encoded with gob, so fields hidden from JSON such as storage
+// paths survive. A failing store only costs the cache, never the request.
+func Fetch[T any](ctx context.Context, store Store, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+    kind, _, _ := strings.Cut(key, ":")
+
+    if data, ok, err := store.Get(ctx, key); err != nil {
+        slog.WarnContext(ctx, "cache read failed", "key", key, "error", err)
+    } else if ok {
+        var value T
+        if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err == nil {
+            metrics.CacheLookup(kind, true)
+            return value, nil
+        }
+        // Format lama atau rusak, anggap tidak ada
+    }
+    metrics.CacheLookup(kind, false)
+
+    value, err := load()
+    if err != nil {
+        return value, err
+    }
+
+    var buf bytes.Buffer
+    if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+        slog.WarnContext(ctx, "cache encode failed", "key", key, "error", err)
+        return value, nil
+    }
+    if err := store.Set(ctx, key, buf.Bytes(), ttl); err != nil {
+        slog.WarnContext(ctx, "cache write failed", "key", key, "error", err)
+    }
+    return value, nil
+}
+
+// Nop is a store that never keeps anything
+type Nop struct{}
+
+func (Nop) Get(context.Context, string) ([]byte, bool, error) {
+    return nil, false, nil
+}
+
+func (Nop) Set(context.Context, string, []byte, time.Duration) error {
+    return nil
+}
+
+func (Nop) Delete(context.Context, ...string) error {
+    return nil
+}
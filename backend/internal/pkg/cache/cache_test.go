@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLRU(t *testing.T) {
+    ctx := context.Background()
+    now := time.Unix(1700000000, 0)
+    lru := NewLRU(2)
+    lru.now = func() time.Time { return now }
+
+    lru.Set(ctx, "a", []byte("1"), time.Minute)
+    lru.Set(ctx, "b", []byte("2"), time.Minute)
+    lru.Get(ctx, "a") // a jadi yang terakhir dipakai
+    lru.Set(ctx, "c", []byte("3"), time.Minute)
+    if _, ok, _ := lru.Get(ctx, "b"); ok {
+        t.Fatal("expected the least recently used entry to be evicted")
+    }
+    if value, ok, _ := lru.Get(ctx, "a"); !ok || string(value) != "1" {
+        t.Fatalf("expected a to stay, got %q", value)
+    }
+
+    now = now.Add(2 * time.Minute)
+    if _, ok, _ := lru.Get(ctx, "c"); ok || lru.Len() != 1 {
+        t.Fatalf("expected c to expire, %d entries left", lru.Len())
+    }
+    lru.Delete(ctx, "a", "missing")
+    if lru.Len() != 0 {
+        t.Fatalf("expected an empty cache, got %d entries", lru.Len())
+    }
+}
+
+func TestFetch(t *testing.T) {
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+
+    type course struct {
+        Title string
+        Path  string `json:"-"`
+    }
+    stores := map[string]Store{"memory": NewLRU(10), "redis": NewRedis(client)}
+    for name, store := range stores {
+        t.Run(name, func(t *testing.T) {
+            ctx := context.Background()
+            loads := 0
+            load := func() (course, error) {
+                loads++
+                return course{Title: "Golang Dasar", Path: "videos/1.mp4"}, nil
+            }
+
+            Fetch(ctx, store, "course:1", time.Minute, load)
+            got, err := Fetch(ctx, store, "course:1", time.Minute, load)
+            if err != nil || loads != 1 || got.Path != "videos/1.mp4" {
+                t.Fatalf("expected one load and the cached value, got %d loads, %+v, %v", loads, got, err)
+            }
+
+            store.Delete(ctx, "course:1")
+            Fetch(ctx, store, "course:1", time.Minute, load)
+            if loads != 2 {
+                t.Fatalf("expected a reload after delete, got %d loads", loads)
+            }
+
+            // Error tidak disimpan
+            failed := errors.New("database down")
+            fail := func() (course, error) { return course{}, failed }
+            if _, err := Fetch(ctx, store, "course:2", time.Minute, fail); !errors.Is(err, failed) {
+                t.Fatalf("expected the load error, got %v", err)
+            }
+            if _, ok, _ := store.Get(ctx, "course:2"); ok {
+                t.Fatal("expected failed loads not to be cached")
+            }
+        })
+    }
+}
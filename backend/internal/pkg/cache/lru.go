@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is a value in the LRU list
+type entry struct {
+    key     string
+    value   []byte
+    expires time.Time
+}
+
+// LRU keeps up to size values in process memory and drops the least
+// recently used one when full. Every instance has its own copy, so changes
+// made through another instance are only seen after the TTL.
+type LRU struct {
+    mu    sync.Mutex
+    size  int
+    order *list.List // Depan = paling baru dipakai
+    items map[string]*list.Element
+    now   func() time.Time // Bisa diganti di test
+}
+
+// NewLRU creates an empty LRU holding at most size values
+func NewLRU(size int) *LRU {
+    return &LRU{size: size, order: list.New(), items: map[string]*list.Element{}, now: time.Now}
+}
+
+func (l *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    element, ok := l.items[key]
+    if !ok {
+        return nil, false, nil
+    }
+    e := element.Value.(*entry)
+    if l.now().After(e.expires) {
+        l.remove(element)
+        return nil, false, nil
+    }
+    l.order.MoveToFront(element)
+    return e.value, true, nil
+}
+
+func (l *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    expires := l.now().Add(ttl)
+    if element, ok := l.items[key]; ok {
+        e := element.Value.(*entry)
+        e.value, e.expires = value, expires
+        l.order.MoveToFront(element)
+        return nil
+    }
+
+    l.items[key] = l.order.PushFront(&entry{key: key, value: value, expires: expires})
+    for l.order.Len() > l.size {
+        l.remove(l.order.Back())
+    }
+    return nil
+}
+
+func (l *LRU) Delete(ctx context.Context, keys ...string) error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    for _, key := range keys {
+        if element, ok := l.items[key]; ok {
+            l.remove(element)
+        }
+    }
+    return nil
+}
+
+// Len returns the number of stored values, including expired ones
+func (l *LRU) Len() int {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.order.Len()
+}
+
+func (l *LRU) remove(element *list.Element) {
+    l.order.Remove(element)
+    delete(l.items, element.Value.(*entry).key)
+}
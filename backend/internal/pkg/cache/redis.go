@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the cache keys in Redis
+const keyPrefix = "cache:"
+
+// Redis keeps values in a Redis-compatible server, so every instance sees
+// the same values and invalidations
+type Redis struct {
+    client redis.Cmdable
+}
+
+// NewRedis creates a store using client
+func NewRedis(client redis.Cmdable) *Redis {
+    return &Redis{client: client}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+    value, err := r.client.Get(ctx, keyPrefix+key).Bytes()
+    if errors.Is(err, redis.Nil) {
+        return nil, false, nil
+    }
+    if err != nil {
+        return nil, false, err
+    }
+    return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+    return r.client.Set(ctx, keyPrefix+key, value, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+    if len(keys) == 0 {
+        return nil
+    }
+    prefixed := make([]string, len(keys))
+    for i, key := range keys {
+        prefixed[i] = keyPrefix + key
+    }
+    return r.client.Del(ctx, prefixed...).Err()
+}
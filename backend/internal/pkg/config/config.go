@@ -2,23 +2,239 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Config holds the complete application configuration.
+//
+// Every field can be set from a YAML/TOML config file (using the yaml tag path,
+// e.g. database.max_open_conns), from a .env file or from the environment
+// (using the env tag). Precedence from low to high: default tag, config file,
+// .env file, process environment.
 type Config struct {
-    GoogleClientID     string
-    GoogleClientSecret string
-    RedirectURL        string
-    DBDsn              string
-}
-
-func LoadConfig() *Config {
-	RedirectURL := os.Getenv("REDIRECT_URL")
-    fmt.Println("Loaded REDIRECT_URL:", RedirectURL) // Debug line
-    return &Config{
-        GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-        GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-        RedirectURL:        os.Getenv("REDIRECT_URL"),
-        DBDsn:              os.Getenv("DB_DSN"),
+    Env       string          `yaml:"env" env:"APP_ENV" default:"development"` // development, test atau production
+    Server    ServerConfig    `yaml:"server"`
+    Database  DatabaseConfig  `yaml:"database"`
+    JWT       JWTConfig       `yaml:"jwt"`
+    Google    GoogleConfig    `yaml:"google"`
+    Storage   StorageConfig   `yaml:"storage"`
+    URLs      URLConfig       `yaml:"urls"`
+    Features  FeatureConfig   `yaml:"features"`
+    Log       LogConfig       `yaml:"log"`
+    Metrics   MetricsConfig   `yaml:"metrics"`
+    Tracing   TracingConfig   `yaml:"tracing"`
+    RateLimit RateLimitConfig `yaml:"rate_limit"`
+    Redis     RedisConfig     `yaml:"redis"`
+    Security  SecurityConfig  `yaml:"security"`
+    Cache     CacheConfig     `yaml:"cache"`
+    Jobs      JobsConfig      `yaml:"jobs"`
+    Webhooks  WebhooksConfig  `yaml:"webhooks"`
+    Mail      MailConfig      `yaml:"mail"`
+
+    Notifications NotificationsConfig `yaml:"notifications"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+    Port              int           `yaml:"port" env:"PORT" default:"8080"`
+    ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"10s"`
+    ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"20s"` // Batas waktu drain request saat shutdown
+    TrustedProxies    []string      `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"` // IP/CIDR reverse proxy yang boleh mengirim X-Forwarded-For
+
+    // CORS, origin boleh memakai wildcard subdomain mis. https://*.example.com
+    CORSOrigins     []string      `yaml:"cors_origins" env:"CORS_ALLOWED_ORIGINS"` // Default: URLs.Frontend
+    CORSMethods     []string      `yaml:"cors_methods" env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+    CORSCredentials bool          `yaml:"cors_credentials" env:"CORS_ALLOW_CREDENTIALS" default:"true"`
+    CORSMaxAge      time.Duration `yaml:"cors_max_age" env:"CORS_MAX_AGE" default:"12h"` // Lama browser boleh menyimpan hasil preflight
+}
+
+// DatabaseConfig configures the Postgres connection and its pool
+type DatabaseConfig struct {
+    DSN             string        `yaml:"dsn" env:"DB_DSN" secret:"dsn"`
+    MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+    MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"5"`
+    ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+    ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+    MigrateOnStart  bool          `yaml:"migrate_on_start" env:"DB_MIGRATE_ON_START" default:"false"` // Jalankan `migrate up` saat server start
+}
+
+// JWTConfig configures the tokens issued after login
+type JWTConfig struct {
+    Secret string        `yaml:"secret" env:"JWT_SECRET" secret:"true"`
+    TTL    time.Duration `yaml:"ttl" env:"JWT_TTL" default:"24h"`
+}
+
+// GoogleConfig configures Google OAuth login
+type GoogleConfig struct {
+    ClientID     string `yaml:"client_id" env:"GOOGLE_CLIENT_ID"`
+    ClientSecret string `yaml:"client_secret" env:"GOOGLE_CLIENT_SECRET" secret:"true"`
+    RedirectURL  string `yaml:"redirect_url" env:"REDIRECT_URL"`
+}
+
+// StorageConfig configures where uploads are stored and how media is served
+type StorageConfig struct {
+    PublicDir    string        `yaml:"public_dir" env:"STORAGE_PUBLIC_DIR" default:"./public"` // Thumbnail katalog
+    Dir          string        `yaml:"dir" env:"STORAGE_DIR" default:"./storage"`              // Media privat dan video
+    MaxVideoSize int64         `yaml:"max_video_size" env:"MAX_VIDEO_SIZE" default:"4294967296"`
+    MaxChunkSize int64         `yaml:"max_chunk_size" env:"MAX_UPLOAD_CHUNK_SIZE" default:"33554432"`
+    SigningKey   string        `yaml:"signing_key" env:"MEDIA_SIGNING_KEY" secret:"true"`
+    SignedURLTTL time.Duration `yaml:"signed_url_ttl" env:"SIGNED_URL_TTL" default:"15m"`
+}
+
+// URLConfig holds the public base URLs used to build links in responses
+type URLConfig struct {
+    PublicAPI string `yaml:"public_api" env:"PUBLIC_API_URL" default:"http://localhost:8080"`
+    Frontend  string `yaml:"frontend" env:"FRONTEND_URL" default:"http://localhost:5173"`
+    CDN       string `yaml:"cdn" env:"CDN_URL"` // Opsional, untuk file /public
+}
+
+// FeatureConfig toggles optional features
+type FeatureConfig struct {
+    VideoUploads bool `yaml:"video_uploads" env:"FEATURE_VIDEO_UPLOADS" default:"true"`
+    DevLogin     bool `yaml:"dev_login" env:"FEATURE_DEV_LOGIN" default:"false"` // Login tanpa OAuth untuk user seed, dilarang di production
+}
+
+// LogConfig configures application, access and query logs
+type LogConfig struct {
+    Level        string        `yaml:"level" env:"LOG_LEVEL" default:"info"`             // debug, info, warn atau error
+    Format       string        `yaml:"format" env:"LOG_FORMAT" default:"json"`           // json atau text
+    AccessLog    string        `yaml:"access_log" env:"ACCESS_LOG" default:"structured"` // structured, combined atau off
+    RedactParams []string      `yaml:"redact_params" env:"LOG_REDACT_PARAMS" default:"token,code,state,signature"`
+    SlowQuery    time.Duration `yaml:"slow_query" env:"LOG_SLOW_QUERY" default:"200ms"` // Query lebih lama dicatat sebagai warning
+}
+
+// MetricsConfig configures the Prometheus /metrics endpoint. With an address
+// it is served on that separate listener; without one it is served by the API
+// and requires the bearer token.
+type MetricsConfig struct {
+    Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED" default:"true"`
+    Addr    string `yaml:"addr" env:"METRICS_ADDR" default:":9090"` // Kosongkan untuk memakai port API
+    Token   string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. The env names follow the
+// OpenTelemetry conventions.
+type TracingConfig struct {
+    Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER" default:"none"` // none, stdout atau otlp
+    Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`         // OTLP/HTTP, mis. http://localhost:4318
+    ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"go-learn-platform"`
+    SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG" default:"1"` // Porsi trace baru yang disimpan, 0 sampai 1
+}
+
+// Enabled reports whether traces are exported
+func (t TracingConfig) Enabled() bool {
+    return t.Exporter != "" && t.Exporter != "none"
+}
+
+// RateLimitConfig configures the token bucket rate limits. Budgets are written
+// as requests per period, e.g. "20/1m": the bucket holds 20 requests and
+// refills at that pace.
+type RateLimitConfig struct {
+    Enabled bool   `yaml:"enabled" env:"RATE_LIMIT_ENABLED" default:"true"`
+    Store   string `yaml:"store" env:"RATE_LIMIT_STORE" default:"memory"`    // memory atau redis (REDIS_URL), redis untuk banyak instance
+    IP      string `yaml:"ip" env:"RATE_LIMIT_IP" default:"600/1m"`          // Semua request per IP
+    User    string `yaml:"user" env:"RATE_LIMIT_USER" default:"300/1m"`      // Route yang butuh login, per user
+    Auth    string `yaml:"auth" env:"RATE_LIMIT_AUTH" default:"20/1m"`       // Login dan OAuth callback, per IP
+    Actions string `yaml:"actions" env:"RATE_LIMIT_ACTIONS" default:"30/1m"` // Enroll dan submit quiz, per user
+}
+
+// SecurityConfig configures the security headers of every response. The API
+// only returns JSON, so its policy forbids loading anything; uploaded files
+// get their own policy that sandboxes them.
+type SecurityConfig struct {
+    ContentSecurityPolicy string `yaml:"content_security_policy" env:"SECURITY_CSP" default:"default-src 'none'; frame-ancestors 'none'"`
+    // Untuk /public dan /media: file tampil apa adanya tapi tidak bisa menjalankan script
+    FileContentSecurityPolicy string `yaml:"file_content_security_policy" env:"SECURITY_FILE_CSP" default:"default-src 'none'; img-src 'self'; media-src 'self'; style-src 'unsafe-inline'; sandbox"`
+
+    HSTSMaxAge            time.Duration `yaml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE" default:"8760h"` // Hanya dikirim lewat HTTPS, 0 = tanpa HSTS
+    HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+    FrameOptions          string        `yaml:"frame_options" env:"SECURITY_FRAME_OPTIONS" default:"DENY"` // DENY atau SAMEORIGIN
+    ReferrerPolicy        string        `yaml:"referrer_policy" env:"SECURITY_REFERRER_POLICY" default:"no-referrer"`
+}
+
+// CacheConfig configures the cache of catalog queries: course lists, course
+// detail trees and public profiles
+type CacheConfig struct {
+    Store string        `yaml:"store" env:"CACHE_STORE" default:"memory"` // none, memory (LRU per instance) atau redis (REDIS_URL, dibagi semua instance)
+    Size  int           `yaml:"size" env:"CACHE_SIZE" default:"1000"`     // Jumlah entri maksimum LRU
+    TTL   time.Duration `yaml:"ttl" env:"CACHE_TTL" default:"5m"`
+}
+
+// JobsConfig configures the background job queue stored in the jobs table.
+// Failed jobs are retried with exponential backoff starting at BackoffBase
+// and become dead after MaxAttempts. Schedules use the cron syntax, e.g.
+// "0 3 * * *"; an empty schedule disables the job.
+type JobsConfig struct {
+    Workers      int           `yaml:"workers" env:"JOB_WORKERS" default:"2"` // 0 = server tidak menjalankan job, pakai perintah `worker`
+    PollInterval time.Duration `yaml:"poll_interval" env:"JOB_POLL_INTERVAL" default:"1s"`
+    LockTimeout  time.Duration `yaml:"lock_timeout" env:"JOB_LOCK_TIMEOUT" default:"10m"` // Job yang berjalan lebih lama dianggap macet dan diambil ulang
+    MaxAttempts  int           `yaml:"max_attempts" env:"JOB_MAX_ATTEMPTS" default:"5"`
+    BackoffBase  time.Duration `yaml:"backoff_base" env:"JOB_BACKOFF_BASE" default:"10s"`
+    BackoffMax   time.Duration `yaml:"backoff_max" env:"JOB_BACKOFF_MAX" default:"1h"`
+    Retention    time.Duration `yaml:"retention" env:"JOB_RETENTION" default:"168h"` // Job selesai dan event terkirim dihapus setelah ini
+
+    GCUploadsSchedule string `yaml:"gc_uploads_schedule" env:"JOB_GC_UPLOADS_SCHEDULE" default:"30 3 * * *"`
+    CleanupSchedule   string `yaml:"cleanup_schedule" env:"JOB_CLEANUP_SCHEDULE" default:"0 4 * * *"` // Hapus job selesai yang melewati retention
+}
+
+// WebhooksConfig configures the delivery of outbound webhooks. Deliveries
+// are retried by the job queue with its backoff until MaxAttempts.
+type WebhooksConfig struct {
+    Timeout      time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" default:"10s"`
+    MaxAttempts  int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+    AllowPrivate bool          `yaml:"allow_private" env:"WEBHOOK_ALLOW_PRIVATE" default:"false"` // Izinkan alamat loopback/privat, mis. receiver lokal saat development
+}
+
+// MailConfig configures the SMTP server of the notification emails. Without
+// a host the emails are written to the log instead, e.g. during development.
+type MailConfig struct {
+    Host     string        `yaml:"host" env:"SMTP_HOST"`
+    Port     int           `yaml:"port" env:"SMTP_PORT" default:"587"`
+    Username string        `yaml:"username" env:"SMTP_USERNAME"` // Kosong = tanpa AUTH
+    Password string        `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+    TLS      string        `yaml:"tls" env:"SMTP_TLS" default:"starttls"` // starttls, tls (port 465) atau none, mis. Mailpit lokal
+    Timeout  time.Duration `yaml:"timeout" env:"SMTP_TIMEOUT" default:"10s"`
+    From     string        `yaml:"from" env:"MAIL_FROM" default:"Go Learn Platform <no-reply@localhost>"`
+
+    // Kunci token unsubscribe; kosong = diturunkan dari JWT_SECRET
+    UnsubscribeKey string `yaml:"unsubscribe_key" env:"MAIL_UNSUBSCRIBE_KEY" secret:"true"`
+}
+
+// NotificationsConfig configures the live stream of in-app notifications.
+// Each API process polls the notifications table for its connected users, so
+// notifications created by workers in other processes are streamed as well.
+type NotificationsConfig struct {
+    PollInterval time.Duration `yaml:"poll_interval" env:"NOTIFICATIONS_POLL_INTERVAL" default:"2s"`
+    KeepAlive    time.Duration `yaml:"keep_alive" env:"NOTIFICATIONS_KEEP_ALIVE" default:"25s"` // Komentar SSE agar proxy tidak menutup koneksi yang diam
+}
+
+// RedisConfig configures the optional Redis-compatible server shared by
+// instances
+type RedisConfig struct {
+    URL string `yaml:"url" env:"REDIS_URL" secret:"dsn"` // mis. redis://:password@localhost:6379/0
+}
+
+// ParseRate parses a rate limit budget like "20/1m" or "5/s"
+func ParseRate(s string) (int, time.Duration, error) {
+    count, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+    n, err := strconv.Atoi(count)
+    if !ok || err != nil || n < 1 {
+        return 0, 0, fmt.Errorf("invalid rate %q, expected e.g. 20/1m", s)
+    }
+    // "1m" atau cukup "m"
+    if period != "" && (period[0] < '0' || period[0] > '9') {
+        period = "1" + period
+    }
+    d, err := time.ParseDuration(period)
+    if err != nil || d <= 0 {
+        return 0, 0, fmt.Errorf("invalid rate %q, expected e.g. 20/1m", s)
     }
-}
\ No newline at end of file
+    return n, d, nil
+}
+
+// IsProduction reports whether the application runs in production
+func (c *Config) IsProduction() bool {
+    return c.Env == "production"
+}
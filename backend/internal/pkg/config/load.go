@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Options tells Load where to look for the optional config and .env files.
+// Empty paths fall back to the CONFIG_FILE and ENV_FILE variables, and the
+// .env file defaults to ".env" in the working directory. Serve is set by the
+// API server, the only command that needs the Google OAuth settings.
+type Options struct {
+    ConfigFile string
+    EnvFile    string
+    Serve      bool
+}
+
+// Error lists every problem found while loading or validating the config
+type Error struct {
+    Problems []string
+}
+
+func (e *Error) Error() string {
+    return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load builds the configuration from defaults, the config file, the .env
+// file and the environment, then validates it. All problems are reported at
+// once in an *Error. A variable that is set but empty (KEY=) is an explicit
+// value: it clears string and list fields, e.g. METRICS_ADDR= or
+// JOB_CLEANUP_SCHEDULE=, and is rejected for numbers, booleans and durations.
+func Load(opts Options) (*Config, error) {
+    var problems []string
+
+    // .env tidak menimpa variabel yang sudah ada di environment
+    env, err := readEnvFile(opts.EnvFile)
+    if err != nil {
+        problems = append(problems, err.Error())
+    }
+    for _, kv := range os.Environ() {
+        if key, value, found := strings.Cut(kv, "="); found {
+            env[key] = value
+        }
+    }
+
+    cfg := &Config{}
+    fields := collectFields(reflect.ValueOf(cfg).Elem(), "")
+
+    for _, f := range fields {
+        if f.def == "" {
+            continue
+        }
+        if err := setValue(f.value, f.def); err != nil {
+            problems = append(problems, fmt.Sprintf("default for %s: %v", f.path, err))
+        }
+    }
+
+    configFile := opts.ConfigFile
+    if configFile == "" {
+        configFile = env["CONFIG_FILE"]
+    }
+    if configFile != "" {
+        values, err := readConfigFile(configFile)
+        if err != nil {
+            problems = append(problems, err.Error())
+        }
+
+        known := make(map[string]bool, len(fields))
+        for _, f := range fields {
+            known[f.path] = true
+            if raw, ok := values[f.path]; ok {
+                if err := setValue(f.value, raw); err != nil {
+                    problems = append(problems, fmt.Sprintf("%s (%s): %v", f.path, configFile, err))
+                }
+            }
+        }
+        for _, key := range sortedKeys(values) {
+            if !known[key] {
+                problems = append(problems, fmt.Sprintf("unknown key %q in %s", key, configFile))
+            }
+        }
+    }
+
+    for _, f := range fields {
+        if f.env == "" {
+            continue
+        }
+        if raw, ok := env[f.env]; ok {
+            if err := setValue(f.value, raw); err != nil {
+                problems = append(problems, fmt.Sprintf("%s: %v", f.env, err))
+            }
+        }
+    }
+
+    if len(cfg.Server.CORSOrigins) == 0 && cfg.URLs.Frontend != "" {
+        cfg.Server.CORSOrigins = []string{strings.TrimRight(cfg.URLs.Frontend, "/")}
+    }
+
+    problems = append(problems, cfg.Validate()...)
+    if opts.Serve {
+        problems = append(problems, cfg.ValidateServe()...)
+    }
+    if len(problems) > 0 {
+        return cfg, &Error{Problems: problems}
+    }
+    return cfg, nil
+}
+
+// field is a settable leaf of the Config struct
+type field struct {
+    path   string // Path di config file, mis. database.dsn
+    env    string
+    def    string
+    secret string
+    value  reflect.Value
+}
+
+// collectFields walks the Config struct and returns its leaf fields
+func collectFields(v reflect.Value, prefix string) []field {
+    var fields []field
+    t := v.Type()
+    for i := 0; i < t.NumField(); i++ {
+        sf := t.Field(i)
+        path := sf.Tag.Get("yaml")
+        if prefix != "" {
+            path = prefix + "." + path
+        }
+
+        fv := v.Field(i)
+        if sf.Type.Kind() == reflect.Struct {
+            fields = append(fields, collectFields(fv, path)...)
+            continue
+        }
+
+        fields = append(fields, field{
+            path:   path,
+            env:    sf.Tag.Get("env"),
+            def:    sf.Tag.Get("default"),
+            secret: sf.Tag.Get("secret"),
+            value:  fv,
+        })
+    }
+    return fields
+}
+
+// setValue parses a raw string into a config field
+func setValue(v reflect.Value, raw string) error {
+    raw = strings.TrimSpace(raw)
+
+    switch v.Interface().(type) {
+    case time.Duration:
+        d, err := time.ParseDuration(raw)
+        if err != nil {
+            return fmt.Errorf("invalid duration %q", raw)
+        }
+        v.SetInt(int64(d))
+        return nil
+    case []string:
+        var list []string
+        for _, item := range strings.Split(raw, ",") {
+            if item = strings.TrimSpace(item); item != "" {
+                list = append(list, item)
+            }
+        }
+        v.Set(reflect.ValueOf(list))
+        return nil
+    }
+
+    switch v.Kind() {
+    case reflect.String:
+        v.SetString(raw)
+    case reflect.Int, reflect.Int64:
+        n, err := strconv.ParseInt(raw, 10, 64)
+        if err != nil {
+            return fmt.Errorf("invalid number %q", raw)
+        }
+        v.SetInt(n)
+    case reflect.Float64:
+        f, err := strconv.ParseFloat(raw, 64)
+        if err != nil {
+            return fmt.Errorf("invalid number %q", raw)
+        }
+        v.SetFloat(f)
+    case reflect.Bool:
+        b, err := strconv.ParseBool(raw)
+        if err != nil {
+            return fmt.Errorf("invalid boolean %q", raw)
+        }
+        v.SetBool(b)
+    default:
+        return fmt.Errorf("unsupported config type %s", v.Type())
+    }
+    return nil
+}
+
+// readEnvFile reads KEY=value pairs from the .env file if it exists
+func readEnvFile(path string) (map[string]string, error) {
+    explicit := path != "" || os.Getenv("ENV_FILE") != ""
+    if path == "" {
+        path = os.Getenv("ENV_FILE")
+    }
+    if path == "" {
+        path = ".env"
+    }
+
+    values, err := godotenv.Read(path)
+    if err != nil {
+        if errors.Is(err, os.ErrNotExist) && !explicit {
+            return map[string]string{}, nil
+        }
+        return map[string]string{}, fmt.Errorf("failed to read env file %s: %v", path, err)
+    }
+    return values, nil
+}
+
+// readConfigFile reads a YAML or TOML file into flattened dotted keys
+func readConfigFile(path string) (map[string]string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read config file: %v", err)
+    }
+
+    tree := map[string]interface{}{}
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, &tree)
+    case ".toml":
+        err = toml.Unmarshal(data, &tree)
+    default:
+        return nil, fmt.Errorf("unsupported config file type %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+    }
+
+    values := map[string]string{}
+    flatten(tree, "", values)
+    return values, nil
+}
+
+// flatten converts nested maps into dotted keys; lists become comma separated
+func flatten(tree map[string]interface{}, prefix string, out map[string]string) {
+    for key, value := range tree {
+        if prefix != "" {
+            key = prefix + "." + key
+        }
+
+        switch v := value.(type) {
+        case map[string]interface{}:
+            flatten(v, key, out)
+        case []interface{}:
+            items := make([]string, 0, len(v))
+            for _, item := range v {
+                items = append(items, fmt.Sprint(item))
+            }
+            out[key] = strings.Join(items, ",")
+        case nil:
+            out[key] = ""
+        default:
+            out[key] = fmt.Sprint(v)
+        }
+    }
+}
+
+// sortedKeys returns the keys of a map in a stable order
+func sortedKeys(m map[string]string) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
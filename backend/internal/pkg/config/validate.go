@@ -0,0 +1,336 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ValidateServe checks the settings only the API server needs, so CLI
+// commands such as migrate run without Google OAuth credentials
+func (c *Config) ValidateServe() []string {
+    var problems []string
+    if c.Google.ClientID == "" {
+        problems = append(problems, "GOOGLE_CLIENT_ID is required")
+    }
+    if c.Google.ClientSecret == "" {
+        problems = append(problems, "GOOGLE_CLIENT_SECRET is required")
+    }
+    if !isHTTPURL(c.Google.RedirectURL) {
+        problems = append(problems, "REDIRECT_URL must be an absolute http(s) URL")
+    }
+    return problems
+}
+
+// Validate checks the configuration and returns every problem found
+func (c *Config) Validate() []string {
+    var problems []string
+    add := func(format string, args ...interface{}) {
+        problems = append(problems, fmt.Sprintf(format, args...))
+    }
+
+    switch c.Env {
+    case "development", "test", "production":
+    default:
+        add("APP_ENV must be one of development, test or production, got %q", c.Env)
+    }
+
+    if c.Server.Port < 1 || c.Server.Port > 65535 {
+        add("PORT must be between 1 and 65535, got %d", c.Server.Port)
+    }
+    if c.Server.ReadHeaderTimeout <= 0 {
+        add("SERVER_READ_HEADER_TIMEOUT must be positive")
+    }
+    if c.Server.ShutdownTimeout <= 0 {
+        add("SERVER_SHUTDOWN_TIMEOUT must be positive")
+    }
+    for _, origin := range c.Server.CORSOrigins {
+        switch {
+        case origin == "*":
+            // Browser menolak wildcard penuh bersama credentials
+            if c.Server.CORSCredentials {
+                add("CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS")
+            }
+        case strings.Contains(origin, "*"):
+            if !isOriginPattern(origin) {
+                add("CORS_ALLOWED_ORIGINS contains an invalid pattern %q, expected e.g. https://*.example.com", origin)
+            }
+        case !isHTTPURL(origin):
+            add("CORS_ALLOWED_ORIGINS contains an invalid origin %q", origin)
+        }
+    }
+    for _, method := range c.Server.CORSMethods {
+        switch method {
+        case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS":
+        default:
+            add("CORS_ALLOWED_METHODS contains an unknown method %q", method)
+        }
+    }
+    if c.Server.CORSMaxAge < 0 {
+        add("CORS_MAX_AGE must not be negative")
+    }
+
+    if c.Database.DSN == "" {
+        add("DB_DSN is required")
+    }
+    if c.Database.MaxOpenConns < 0 {
+        add("DB_MAX_OPEN_CONNS must not be negative")
+    }
+    if c.Database.MaxIdleConns < 0 {
+        add("DB_MAX_IDLE_CONNS must not be negative")
+    }
+    if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+        add("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+    }
+    if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+        add("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative")
+    }
+
+    minSecret := 16
+    if c.IsProduction() {
+        minSecret = 32
+    }
+    if len(c.JWT.Secret) < minSecret {
+        add("JWT_SECRET must be at least %d characters", minSecret)
+    }
+    if c.JWT.TTL <= 0 {
+        add("JWT_TTL must be positive")
+    }
+    if c.Mail.UnsubscribeKey != "" && len(c.Mail.UnsubscribeKey) < minSecret {
+        add("MAIL_UNSUBSCRIBE_KEY must be at least %d characters", minSecret)
+    }
+
+    if c.Storage.PublicDir == "" || c.Storage.Dir == "" {
+        add("STORAGE_PUBLIC_DIR and STORAGE_DIR are required")
+    }
+    if c.Storage.MaxVideoSize <= 0 {
+        add("MAX_VIDEO_SIZE must be positive")
+    }
+    if c.Storage.MaxChunkSize <= 0 {
+        add("MAX_UPLOAD_CHUNK_SIZE must be positive")
+    }
+    if c.IsProduction() && len(c.Storage.SigningKey) < 32 {
+        add("MEDIA_SIGNING_KEY must be at least 32 characters in production")
+    }
+    if c.Storage.SignedURLTTL <= 0 {
+        add("SIGNED_URL_TTL must be positive")
+    }
+
+    if !isHTTPURL(c.URLs.PublicAPI) {
+        add("PUBLIC_API_URL must be an absolute http(s) URL")
+    }
+    if !isHTTPURL(c.URLs.Frontend) {
+        add("FRONTEND_URL must be an absolute http(s) URL")
+    }
+    if c.URLs.CDN != "" && !isHTTPURL(c.URLs.CDN) {
+        add("CDN_URL must be an absolute http(s) URL")
+    }
+
+    if c.Features.DevLogin && c.IsProduction() {
+        add("FEATURE_DEV_LOGIN must not be enabled in production")
+    }
+
+    switch strings.ToLower(c.Log.Level) {
+    case "debug", "info", "warn", "error":
+    default:
+        add("LOG_LEVEL must be one of debug, info, warn or error, got %q", c.Log.Level)
+    }
+    if c.Log.Format != "json" && c.Log.Format != "text" {
+        add("LOG_FORMAT must be json or text, got %q", c.Log.Format)
+    }
+    switch c.Log.AccessLog {
+    case "structured", "combined", "off":
+    default:
+        add("ACCESS_LOG must be one of structured, combined or off, got %q", c.Log.AccessLog)
+    }
+    if c.Log.SlowQuery < 0 {
+        add("LOG_SLOW_QUERY must not be negative")
+    }
+
+    if c.Metrics.Enabled && c.Metrics.Addr == "" && len(c.Metrics.Token) < 16 {
+        add("METRICS_TOKEN must be at least 16 characters when /metrics is served on the API port (METRICS_ADDR empty)")
+    }
+    if c.Metrics.Addr != "" && c.Metrics.Addr == fmt.Sprintf(":%d", c.Server.Port) {
+        add("METRICS_ADDR must not use the API port %d", c.Server.Port)
+    }
+
+    switch c.Tracing.Exporter {
+    case "none", "stdout", "otlp":
+    default:
+        add("OTEL_TRACES_EXPORTER must be one of none, stdout or otlp, got %q", c.Tracing.Exporter)
+    }
+    if c.Tracing.Endpoint != "" && !isHTTPURL(c.Tracing.Endpoint) {
+        add("OTEL_EXPORTER_OTLP_ENDPOINT must be an absolute http(s) URL")
+    }
+    if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+        add("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1")
+    }
+    if c.Tracing.Enabled() && c.Tracing.ServiceName == "" {
+        add("OTEL_SERVICE_NAME is required when tracing is enabled")
+    }
+
+    if c.RateLimit.Enabled {
+        budgets := []struct{ env, value string }{
+            {"RATE_LIMIT_IP", c.RateLimit.IP},
+            {"RATE_LIMIT_USER", c.RateLimit.User},
+            {"RATE_LIMIT_AUTH", c.RateLimit.Auth},
+            {"RATE_LIMIT_ACTIONS", c.RateLimit.Actions},
+        }
+        for _, budget := range budgets {
+            if _, _, err := ParseRate(budget.value); err != nil {
+                add("%s: %v", budget.env, err)
+            }
+        }
+        switch c.RateLimit.Store {
+        case "memory":
+        case "redis":
+            if c.Redis.URL == "" {
+                add("REDIS_URL is required when RATE_LIMIT_STORE is redis")
+            }
+        default:
+            add("RATE_LIMIT_STORE must be memory or redis, got %q", c.RateLimit.Store)
+        }
+    }
+    switch c.Cache.Store {
+    case "none":
+    case "memory":
+        if c.Cache.Size < 1 {
+            add("CACHE_SIZE must be at least 1, got %d", c.Cache.Size)
+        }
+    case "redis":
+        if c.Redis.URL == "" {
+            add("REDIS_URL is required when CACHE_STORE is redis")
+        }
+    default:
+        add("CACHE_STORE must be none, memory or redis, got %q", c.Cache.Store)
+    }
+    if c.Cache.Store != "none" && c.Cache.TTL <= 0 {
+        add("CACHE_TTL must be positive")
+    }
+    if c.Redis.URL != "" {
+        if u, err := url.Parse(c.Redis.URL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+            add("REDIS_URL must be a redis:// or rediss:// URL")
+        }
+    }
+    for _, proxy := range c.Server.TrustedProxies {
+        if net.ParseIP(proxy) == nil {
+            if _, _, err := net.ParseCIDR(proxy); err != nil {
+                add("TRUSTED_PROXIES contains an invalid IP or CIDR %q", proxy)
+            }
+        }
+    }
+
+    if c.Jobs.Workers < 0 {
+        add("JOB_WORKERS must not be negative, got %d", c.Jobs.Workers)
+    }
+    if c.Jobs.PollInterval <= 0 || c.Jobs.LockTimeout <= 0 {
+        add("JOB_POLL_INTERVAL and JOB_LOCK_TIMEOUT must be positive")
+    }
+    if c.Jobs.MaxAttempts < 1 {
+        add("JOB_MAX_ATTEMPTS must be at least 1, got %d", c.Jobs.MaxAttempts)
+    }
+    if c.Jobs.BackoffBase <= 0 || c.Jobs.BackoffMax < c.Jobs.BackoffBase {
+        add("JOB_BACKOFF_BASE must be positive and not larger than JOB_BACKOFF_MAX")
+    }
+    if c.Jobs.Retention <= 0 {
+        add("JOB_RETENTION must be positive")
+    }
+    schedules := []struct{ env, value string }{
+        {"JOB_GC_UPLOADS_SCHEDULE", c.Jobs.GCUploadsSchedule},
+        {"JOB_CLEANUP_SCHEDULE", c.Jobs.CleanupSchedule},
+    }
+    for _, schedule := range schedules {
+        if schedule.value == "" {
+            continue
+        }
+        if _, err := cron.ParseStandard(schedule.value); err != nil {
+            add("%s: %v", schedule.env, err)
+        }
+    }
+
+    if c.Webhooks.Timeout <= 0 {
+        add("WEBHOOK_TIMEOUT must be positive")
+    }
+    if c.Webhooks.MaxAttempts < 1 {
+        add("WEBHOOK_MAX_ATTEMPTS must be at least 1, got %d", c.Webhooks.MaxAttempts)
+    }
+
+    switch c.Mail.TLS {
+    case "starttls", "tls", "none":
+    default:
+        add("SMTP_TLS must be one of starttls, tls or none, got %q", c.Mail.TLS)
+    }
+    if c.Mail.Host != "" && (c.Mail.Port < 1 || c.Mail.Port > 65535) {
+        add("SMTP_PORT must be between 1 and 65535, got %d", c.Mail.Port)
+    }
+    if c.Mail.Timeout <= 0 {
+        add("SMTP_TIMEOUT must be positive")
+    }
+    if _, err := mail.ParseAddress(c.Mail.From); err != nil {
+        add("MAIL_FROM must be an email address like \"Go Learn <no-reply@example.com>\", got %q", c.Mail.From)
+    }
+
+    if c.Notifications.PollInterval <= 0 || c.Notifications.KeepAlive <= 0 {
+        add("NOTIFICATIONS_POLL_INTERVAL and NOTIFICATIONS_KEEP_ALIVE must be positive")
+    }
+
+    if c.Security.HSTSMaxAge < 0 {
+        add("SECURITY_HSTS_MAX_AGE must not be negative")
+    }
+    switch c.Security.FrameOptions {
+    case "DENY", "SAMEORIGIN":
+    default:
+        add("SECURITY_FRAME_OPTIONS must be DENY or SAMEORIGIN, got %q", c.Security.FrameOptions)
+    }
+
+    return problems
+}
+
+// String renders the configuration with secrets redacted, for logging
+func (c *Config) String() string {
+    var b strings.Builder
+    for _, f := range collectFields(reflect.ValueOf(c).Elem(), "") {
+        value := fmt.Sprint(f.value.Interface())
+        switch {
+        case f.secret == "dsn":
+            value = redactDSN(value)
+        case f.secret != "" && value != "":
+            value = "[REDACTED]"
+        }
+        fmt.Fprintf(&b, "  %s = %s\n", f.path, value)
+    }
+    return b.String()
+}
+
+// dsnPassword matches the password of a key=value Postgres DSN
+var dsnPassword = regexp.MustCompile(`(password=)(?:'[^']*'|\S+)`)
+
+// redactDSN hides the password of a URL or key=value style DSN
+func redactDSN(dsn string) string {
+    if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.User != nil {
+        if _, ok := u.User.Password(); ok {
+            u.User = url.UserPassword(u.User.Username(), "REDACTED")
+        }
+        return u.String()
+    }
+    return dsnPassword.ReplaceAllString(dsn, "${1}[REDACTED]")
+}
+
+// isOriginPattern reports whether s is an origin with a wildcard for the
+// subdomain, e.g. https://*.example.com
+func isOriginPattern(s string) bool {
+    scheme, host, ok := strings.Cut(s, "://*.")
+    return ok && (scheme == "http" || scheme == "https") && host != "" && !strings.ContainsAny(host, "*/")
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL
+func isHTTPURL(s string) bool {
+    u, err := url.Parse(s)
+    return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
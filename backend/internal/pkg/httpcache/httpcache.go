@@ -0,0 +1,131 @@
+// Package httpcache implements entity tags and conditional requests.
+//
+// An entity tag is derived from the versions (ID and UpdatedAt) of the records
+// shown in a response instead of the response body, so it can be checked
+// before the body is built. Responses that also depend on something else,
+// such as the access of the viewer or the expiry of signed media URLs, add a
+// variant to the tag. If-Match only compares the version part, so a tag stays
+// usable for updates after the variant changed.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-learn-platform/internal/pkg/response"
+	buildinfo "go-learn-platform/internal/pkg/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Revalidate is the Cache-Control policy of catalog responses: clients keep a
+// private copy but ask with If-None-Match before every use, so changes are
+// visible immediately
+const Revalidate = "private, no-cache"
+
+// Version hashes the versions of the records shown in a representation
+type Version struct {
+    h hash.Hash
+}
+
+// NewVersion starts a version of the given kind of representation. The
+// build version is included, so a deploy changing the response shape also
+// changes every tag.
+func NewVersion(kind string) *Version {
+    v := &Version{h: sha256.New()}
+    return v.Add(buildinfo.Version, kind)
+}
+
+// Add mixes values into the version
+func (v *Version) Add(values ...interface{}) *Version {
+    for _, value := range values {
+        fmt.Fprintf(v.h, "%v\x00", value)
+    }
+    return v
+}
+
+// Record mixes the version of one record into the version
+func (v *Version) Record(id uint, updatedAt time.Time) *Version {
+    return v.Add(id, updatedAt.UnixNano())
+}
+
+// String returns the version as a short hex string
+func (v *Version) String() string {
+    return hex.EncodeToString(v.h.Sum(nil)[:16])
+}
+
+// ETag returns the strong entity tag of a representation with the given
+// version. variant is empty when the response only depends on the records.
+func ETag(version, variant string) string {
+    if variant == "" {
+        return `"` + version + `"`
+    }
+    sum := sha256.Sum256([]byte(variant))
+    return `"` + version + "-" + hex.EncodeToString(sum[:4]) + `"`
+}
+
+// NotModified sets the ETag and Cache-Control headers of a response and
+// answers 304 when the If-None-Match header of the request lists the tag.
+// Handlers return without writing a body when it reports true.
+func NotModified(c *gin.Context, etag, cacheControl string) bool {
+    c.Header("ETag", etag)
+    c.Header("Cache-Control", cacheControl)
+    // Isi response bergantung pada user yang login
+    c.Writer.Header().Add("Vary", "Authorization")
+
+    for _, tag := range tags(c.GetHeader("If-None-Match")) {
+        // Perbandingan lemah: W/"x" cocok dengan "x"
+        if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+            c.Status(http.StatusNotModified)
+            c.Writer.WriteHeaderNow()
+            c.Abort()
+            return true
+        }
+    }
+    return false
+}
+
+// IfMatch checks the If-Match header of an update against the current
+// version of the resource. Without the header every update is allowed; when
+// no listed tag matches it answers 412 and reports false.
+func IfMatch(c *gin.Context, version string) bool {
+    header := c.GetHeader("If-Match")
+    if header == "" {
+        return true
+    }
+
+    for _, tag := range tags(header) {
+        // Perbandingan kuat, tag lemah tidak pernah cocok
+        if tag == "*" || tagVersion(tag) == version {
+            return true
+        }
+    }
+    response.AbortCode(c, http.StatusPreconditionFailed, response.CodePreconditionFailed,
+        "The resource was changed by someone else, reload it and try again")
+    return false
+}
+
+// tags splits an If-Match or If-None-Match header
+func tags(header string) []string {
+    var list []string
+    for _, tag := range strings.Split(header, ",") {
+        if tag = strings.TrimSpace(tag); tag != "" {
+            list = append(list, tag)
+        }
+    }
+    return list
+}
+
+// tagVersion returns the version part of a strong tag
+func tagVersion(tag string) string {
+    if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+        return ""
+    }
+    version, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+    return version
+}
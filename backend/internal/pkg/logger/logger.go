@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+// Init replaces the default slog logger, and with it the standard log
+// package, with one configured by cfg.Log writing to stdout
+func Init(cfg *config.Config) {
+    slog.SetDefault(New(os.Stdout, cfg.Log))
+}
+
+// New creates a JSON or text logger. Attributes added to a context with
+// WithAttrs are included in every record logged with that context.
+func New(w io.Writer, cfg config.LogConfig) *slog.Logger {
+    opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level)}
+
+    var handler slog.Handler
+    if cfg.Format == "text" {
+        handler = slog.NewTextHandler(w, opts)
+    } else {
+        handler = slog.NewJSONHandler(w, opts)
+    }
+    return slog.New(contextHandler{handler})
+}
+
+// ParseLevel converts debug, info, warn or error to a slog level. Unknown
+// values fall back to info.
+func ParseLevel(level string) slog.Level {
+    switch strings.ToLower(level) {
+    case "debug":
+        return slog.LevelDebug
+    case "warn":
+        return slog.LevelWarn
+    case "error":
+        return slog.LevelError
+    }
+    return slog.LevelInfo
+}
+
+// attrsKey is the context key of the attributes added with WithAttrs
+type attrsKey struct{}
+
+// WithAttrs returns a copy of ctx carrying attrs, e.g. the request ID or the
+// logged in user
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+    existing := Attrs(ctx)
+    merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+    merged = append(merged, existing...)
+    merged = append(merged, attrs...)
+    return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// Attrs returns the attributes added to ctx with WithAttrs
+func Attrs(ctx context.Context) []slog.Attr {
+    if ctx == nil {
+        return nil
+    }
+    attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+    return attrs
+}
+
+// contextHandler adds the attributes of the record's context
+type contextHandler struct {
+    slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+    if attrs := Attrs(ctx); len(attrs) > 0 {
+        record = record.Clone()
+        record.AddAttrs(attrs...)
+    }
+    return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+    return contextHandler{h.Handler.WithGroup(name)}
+}
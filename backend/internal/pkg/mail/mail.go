@@ -0,0 +1,159 @@
+// Package mail sends emails with a text and an HTML body over SMTP. Without
+// a configured host the emails are only logged.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+// Message is an email to one recipient
+type Message struct {
+    To      string
+    Subject string
+    Text    string
+    HTML    string            // Opsional, dikirim sebagai multipart/alternative
+    Header  map[string]string // Header tambahan, mis. List-Unsubscribe
+}
+
+// Sender delivers messages
+type Sender interface {
+    Send(ctx context.Context, msg Message) error
+}
+
+// New returns the SMTP sender of cfg, or a sender that only logs the
+// messages when no host is configured
+func New(cfg config.MailConfig) Sender {
+    if cfg.Host == "" {
+        return Log{}
+    }
+    return &SMTP{cfg: cfg}
+}
+
+// Log writes the recipient and subject of messages to the log instead of
+// sending them
+type Log struct{}
+
+// Send logs msg
+func (Log) Send(ctx context.Context, msg Message) error {
+    slog.InfoContext(ctx, "Email not sent, SMTP_HOST is not set", "to", msg.To, "subject", msg.Subject)
+    return nil
+}
+
+// Rejected reports whether the SMTP server refused a message for good with
+// a 5xx reply, so sending it again will not help
+func Rejected(err error) bool {
+    var reply *textproto.Error
+    return errors.As(err, &reply) && reply.Code >= 500
+}
+
+// Bytes renders msg as an RFC 5322 message from the given sender
+func (msg Message) Bytes(from string, date time.Time) ([]byte, error) {
+    sender, err := mail.ParseAddress(from)
+    if err != nil {
+        return nil, fmt.Errorf("from address: %w", err)
+    }
+    to, err := mail.ParseAddress(msg.To)
+    if err != nil {
+        return nil, fmt.Errorf("to address: %w", err)
+    }
+    id, err := messageID(sender.Address)
+    if err != nil {
+        return nil, err
+    }
+
+    // Header tidak boleh membawa baris baru dari data pengguna
+    oneLine := strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+    var buf bytes.Buffer
+    header := map[string]string{
+        "From":         sender.String(),
+        "To":           to.String(),
+        "Subject":      mime.QEncoding.Encode("utf-8", oneLine.Replace(msg.Subject)),
+        "Date":         date.Format(time.RFC1123Z),
+        "Message-ID":   id,
+        "MIME-Version": "1.0",
+    }
+    for key, value := range msg.Header {
+        header[textproto.CanonicalMIMEHeaderKey(key)] = value
+    }
+    keys := make([]string, 0, len(header))
+    for key := range header {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    for _, key := range keys {
+        fmt.Fprintf(&buf, "%s: %s\r\n", key, oneLine.Replace(header[key]))
+    }
+
+    if msg.HTML == "" {
+        buf.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+        if err := writeQuoted(&buf, msg.Text); err != nil {
+            return nil, err
+        }
+        return buf.Bytes(), nil
+    }
+
+    var body bytes.Buffer
+    parts := multipart.NewWriter(&body)
+    fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+    for _, part := range []struct{ contentType, content string }{
+        {"text/plain; charset=utf-8", msg.Text},
+        {"text/html; charset=utf-8", msg.HTML},
+    } {
+        w, err := parts.CreatePart(textproto.MIMEHeader{
+            "Content-Type":              {part.contentType},
+            "Content-Transfer-Encoding": {"quoted-printable"},
+        })
+        if err != nil {
+            return nil, err
+        }
+        if err := writeQuoted(w, part.content); err != nil {
+            return nil, err
+        }
+    }
+    if err := parts.Close(); err != nil {
+        return nil, err
+    }
+    buf.Write(body.Bytes())
+    return buf.Bytes(), nil
+}
+
+// writeQuoted writes s quoted-printable encoded with CRLF line endings
+func writeQuoted(w io.Writer, s string) error {
+    s = strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+    qp := quotedprintable.NewWriter(w)
+    if _, err := qp.Write([]byte(s)); err != nil {
+        return err
+    }
+    return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(address string) (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    _, domain, _ := strings.Cut(address, "@")
+    if domain == "" {
+        domain = "localhost"
+    }
+    return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
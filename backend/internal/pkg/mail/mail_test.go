@@ -0,0 +1,77 @@
+package mail_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/pkg/mail"
+	"go-learn-platform/internal/pkg/mail/mailtest"
+)
+
+func TestSMTPSendsTextAndHTML(t *testing.T) {
+    server := mailtest.NewServer(t)
+    sender := mail.New(server.Config())
+
+    err := sender.Send(context.Background(), mail.Message{
+        To:      "Andi <andi@example.com>",
+        Subject: "Selamat datang di kursus",
+        Text:    "Halo Andi,\nselamat belajar!",
+        HTML:    "<p>Halo Andi,<br>selamat belajar!</p>",
+        Header:  map[string]string{"List-Unsubscribe": "<http://api.test/unsubscribe?token=x>"},
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    messages := server.Messages()
+    if len(messages) != 1 {
+        t.Fatalf("expected 1 message, got %d", len(messages))
+    }
+    msg := messages[0]
+    if msg.From != "no-reply@example.com" || len(msg.To) != 1 || msg.To[0] != "andi@example.com" {
+        t.Fatalf("unexpected envelope %s -> %v", msg.From, msg.To)
+    }
+    if msg.Text != "Halo Andi,\nselamat belajar!" || msg.HTML != "<p>Halo Andi,<br>selamat belajar!</p>" {
+        t.Fatalf("unexpected bodies %q and %q", msg.Text, msg.HTML)
+    }
+    if msg.Header.Get("List-Unsubscribe") != "<http://api.test/unsubscribe?token=x>" || msg.Header.Get("Message-Id") == "" {
+        t.Fatalf("unexpected header %v", msg.Header)
+    }
+}
+
+func TestHeadersCannotBeInjected(t *testing.T) {
+    server := mailtest.NewServer(t)
+    sender := mail.New(server.Config())
+
+    err := sender.Send(context.Background(), mail.Message{
+        To:      "andi@example.com",
+        Subject: "Kuis\r\nBcc: korban@example.com",
+        Text:    "Halo",
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    msg := server.Messages()[0]
+    if msg.Header.Get("Bcc") != "" || strings.TrimSpace(msg.Text) != "Halo" {
+        t.Fatalf("expected the subject to stay one header, got %v", msg.Header)
+    }
+}
+
+func TestRejected(t *testing.T) {
+    server := mailtest.NewServer(t)
+    sender := mail.New(server.Config())
+    msg := mail.Message{To: "andi@example.com", Subject: "Halo", Text: "Halo"}
+
+    server.Reject(451)
+    if err := sender.Send(context.Background(), msg); err == nil || mail.Rejected(err) {
+        t.Fatalf("expected a temporary failure, got %v", err)
+    }
+    server.Reject(550)
+    if err := sender.Send(context.Background(), msg); !mail.Rejected(err) {
+        t.Fatalf("expected a rejection, got %v", err)
+    }
+    if len(server.Messages()) != 0 {
+        t.Fatal("expected no message to arrive")
+    }
+}
@@ -0,0 +1,194 @@
+// Package mailtest provides a local catch-all SMTP server for tests. It
+// accepts every message without TLS or authentication and keeps it in
+// memory.
+package mailtest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+// Message is a message received by the server
+type Message struct {
+    From   string
+    To     []string
+    Header mail.Header
+    Text   string // Bagian text/plain, sudah di-decode
+    HTML   string // Bagian text/html, sudah di-decode
+}
+
+// Server is a catch-all SMTP server listening on localhost
+type Server struct {
+    listener net.Listener
+    mu       sync.Mutex
+    messages []Message
+    reject   int
+}
+
+// NewServer starts a server that stops when the test ends
+func NewServer(t *testing.T) *Server {
+    t.Helper()
+    listener, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("mailtest: listen: %v", err)
+    }
+    s := &Server{listener: listener}
+    go s.serve()
+    t.Cleanup(func() { listener.Close() })
+    return s
+}
+
+// Config returns the mail configuration sending to this server
+func (s *Server) Config() config.MailConfig {
+    host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+    n, _ := strconv.Atoi(port)
+    return config.MailConfig{Host: host, Port: n, TLS: "none", Timeout: 5 * time.Second, From: "Go Learn Platform <no-reply@example.com>"}
+}
+
+// Reject makes the server answer RCPT TO with code, e.g. 550 for an unknown
+// mailbox or 451 for a temporary failure; 0 accepts messages again
+func (s *Server) Reject(code int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.reject = code
+}
+
+// Messages returns the messages received so far
+func (s *Server) Messages() []Message {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]Message(nil), s.messages...)
+}
+
+func (s *Server) serve() {
+    for {
+        conn, err := s.listener.Accept()
+        if err != nil {
+            return
+        }
+        go s.handle(conn)
+    }
+}
+
+// handle speaks just enough SMTP for net/smtp
+func (s *Server) handle(conn net.Conn) {
+    defer conn.Close()
+    text := textproto.NewConn(conn)
+    reply := func(format string, args ...interface{}) bool {
+        return text.PrintfLine(format, args...) == nil
+    }
+    reply("220 mailtest ready")
+
+    var msg Message
+    for {
+        line, err := text.ReadLine()
+        if err != nil {
+            return
+        }
+        verb, arg, _ := strings.Cut(line, " ")
+        switch strings.ToUpper(verb) {
+        case "EHLO", "HELO":
+            reply("250 mailtest")
+        case "MAIL":
+            msg = Message{From: address(arg)}
+            reply("250 OK")
+        case "RCPT":
+            s.mu.Lock()
+            code := s.reject
+            s.mu.Unlock()
+            if code != 0 {
+                reply("%d mailbox unavailable", code)
+                continue
+            }
+            msg.To = append(msg.To, address(arg))
+            reply("250 OK")
+        case "DATA":
+            reply("354 end data with <CR><LF>.<CR><LF>")
+            data, err := text.ReadDotBytes()
+            if err != nil {
+                return
+            }
+            if err := parse(&msg, data); err != nil {
+                reply("554 %s", err)
+                continue
+            }
+            s.mu.Lock()
+            s.messages = append(s.messages, msg)
+            s.mu.Unlock()
+            reply("250 OK")
+        case "RSET", "NOOP":
+            reply("250 OK")
+        case "QUIT":
+            reply("221 bye")
+            return
+        default:
+            reply("502 command not implemented")
+        }
+    }
+}
+
+// address returns the address of a MAIL FROM:<a> or RCPT TO:<a> argument
+func address(arg string) string {
+    _, value, _ := strings.Cut(arg, ":")
+    return strings.Trim(strings.TrimSpace(value), "<>")
+}
+
+// parse reads the header and the text and HTML bodies of a message
+func parse(msg *Message, data []byte) error {
+    parsed, err := mail.ReadMessage(bytes.NewReader(data))
+    if err != nil {
+        return err
+    }
+    msg.Header = parsed.Header
+    return readPart(msg, textproto.MIMEHeader(parsed.Header), parsed.Body)
+}
+
+func readPart(msg *Message, header textproto.MIMEHeader, body io.Reader) error {
+    mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+    if err != nil {
+        return err
+    }
+    if strings.HasPrefix(mediaType, "multipart/") {
+        parts := multipart.NewReader(body, params["boundary"])
+        for {
+            part, err := parts.NextRawPart()
+            if err == io.EOF {
+                return nil
+            }
+            if err != nil {
+                return err
+            }
+            if err := readPart(msg, part.Header, part); err != nil {
+                return err
+            }
+        }
+    }
+
+    if strings.EqualFold(header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+        body = quotedprintable.NewReader(body)
+    }
+    content, err := io.ReadAll(body)
+    if err != nil {
+        return err
+    }
+    switch mediaType {
+    case "text/plain":
+        msg.Text = strings.ReplaceAll(string(content), "\r\n", "\n")
+    case "text/html":
+        msg.HTML = strings.ReplaceAll(string(content), "\r\n", "\n")
+    }
+    return nil
+}
@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+// ErrNoStartTLS is returned when SMTP_TLS is starttls and the server does
+// not offer it
+var ErrNoStartTLS = errors.New("SMTP server does not support STARTTLS")
+
+// SMTP sends messages through the configured SMTP server, one connection
+// per message
+type SMTP struct {
+    cfg config.MailConfig
+}
+
+// Send delivers msg. The connection is closed after the message, so a
+// broken server never blocks more than one message.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+    data, err := msg.Bytes(s.cfg.From, time.Now())
+    if err != nil {
+        return err
+    }
+    from, _ := mail.ParseAddress(s.cfg.From) // Sudah diperiksa oleh Bytes
+    to, _ := mail.ParseAddress(msg.To)
+
+    client, err := s.dial(ctx)
+    if err != nil {
+        return err
+    }
+    defer client.Close()
+
+    if s.cfg.TLS == "starttls" {
+        if ok, _ := client.Extension("STARTTLS"); !ok {
+            return ErrNoStartTLS
+        }
+        if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+            return fmt.Errorf("starttls: %w", err)
+        }
+    }
+    if s.cfg.Username != "" {
+        if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+            return fmt.Errorf("auth: %w", err)
+        }
+    }
+
+    if err := client.Mail(from.Address); err != nil {
+        return fmt.Errorf("mail from: %w", err)
+    }
+    if err := client.Rcpt(to.Address); err != nil {
+        return fmt.Errorf("rcpt to: %w", err)
+    }
+    w, err := client.Data()
+    if err != nil {
+        return fmt.Errorf("data: %w", err)
+    }
+    if _, err := w.Write(data); err != nil {
+        return fmt.Errorf("data: %w", err)
+    }
+    if err := w.Close(); err != nil {
+        return fmt.Errorf("data: %w", err)
+    }
+    return client.Quit()
+}
+
+// dial connects to the server, with implicit TLS when SMTP_TLS is tls. The
+// whole conversation must finish within SMTP_TIMEOUT.
+func (s *SMTP) dial(ctx context.Context) (*smtp.Client, error) {
+    address := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+    dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+
+    var conn net.Conn
+    var err error
+    if s.cfg.TLS == "tls" {
+        tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}
+        conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+    } else {
+        conn, err = dialer.DialContext(ctx, "tcp", address)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    deadline := time.Now().Add(s.cfg.Timeout)
+    if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+        deadline = d
+    }
+    if err := conn.SetDeadline(deadline); err != nil {
+        conn.Close()
+        return nil, err
+    }
+
+    client, err := smtp.NewClient(conn, s.cfg.Host)
+    if err != nil {
+        conn.Close()
+        return nil, err
+    }
+    return client, nil
+}
@@ -0,0 +1,67 @@
+package media
+
+import (
+	"path/filepath"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+// storageSettings describes where uploaded files live and how large they may be
+type storageSettings struct {
+    publicDir    string
+    dir          string
+    maxVideoSize int64
+    maxChunkSize int64
+}
+
+var storage = storageSettings{
+    publicDir:    "./public",
+    dir:          "./storage",
+    maxVideoSize: 4 << 30,
+    maxChunkSize: 32 << 20,
+}
+
+// initStorage applies the storage section of the config
+func initStorage(cfg *config.Config) {
+    storage = storageSettings{
+        publicDir:    cfg.Storage.PublicDir,
+        dir:          cfg.Storage.Dir,
+        maxVideoSize: cfg.Storage.MaxVideoSize,
+        maxChunkSize: cfg.Storage.MaxChunkSize,
+    }
+}
+
+// PublicDir is the folder served under /public (catalog thumbnails, avatars)
+func PublicDir() string {
+    return storage.publicDir
+}
+
+// StorageDir is the root folder of private media served under /media
+func StorageDir() string {
+    return storage.dir
+}
+
+// PrivateDir holds private uploads such as lesson images
+func PrivateDir() string {
+    return filepath.Join(storage.dir, "private")
+}
+
+// VideoDir holds completed lesson videos
+func VideoDir() string {
+    return filepath.Join(storage.dir, "videos")
+}
+
+// TempDir holds the partial data of resumable uploads
+func TempDir() string {
+    return filepath.Join(storage.dir, "tmp")
+}
+
+// MaxVideoSize is the largest accepted video upload in bytes
+func MaxVideoSize() int64 {
+    return storage.maxVideoSize
+}
+
+// MaxChunkSize is the largest accepted chunk of a resumable upload in bytes
+func MaxChunkSize() int64 {
+    return storage.maxChunkSize
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// startKey stores the start time of a query in the GORM statement
+const startKey = "metrics:start"
+
+// GormPlugin records the duration and errors of every GORM query.
+// Register it with db.Use(metrics.GormPlugin{}).
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+    return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+    cb := db.Callback()
+    hooks := []struct {
+        operation string
+        before    func(name string, fn func(*gorm.DB)) error
+        after     func(name string, fn func(*gorm.DB)) error
+    }{
+        {"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+        {"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+        {"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+        {"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+        {"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+        {"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+    }
+
+    for _, hook := range hooks {
+        if err := hook.before("metrics:before_"+hook.operation, startQuery); err != nil {
+            return err
+        }
+        if err := hook.after("metrics:after_"+hook.operation, finishQuery(hook.operation)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// startQuery remembers when the query started
+func startQuery(db *gorm.DB) {
+    db.InstanceSet(startKey, time.Now())
+}
+
+// finishQuery observes the query duration and counts failures
+func finishQuery(operation string) func(*gorm.DB) {
+    return func(db *gorm.DB) {
+        value, ok := db.InstanceGet(startKey)
+        if !ok {
+            return
+        }
+        start, _ := value.(time.Time)
+
+        table := db.Statement.Table
+        if table == "" {
+            table = "unknown"
+        }
+        dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+
+        // Record tidak ditemukan adalah hasil biasa, bukan error
+        if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+            dbQueryErrors.WithLabelValues(operation, table).Inc()
+        }
+    }
+}
@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every application metric
+const namespace = "golearn"
+
+// Registry holds all metrics exposed at /metrics, including the Go runtime
+// and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+    httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "http_requests_total",
+        Help:      "HTTP requests by method, route template and status code.",
+    }, []string{"method", "route", "status"})
+
+    httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "http_request_duration_seconds",
+        Help:      "HTTP request latency by method and route template.",
+        Buckets:   prometheus.DefBuckets,
+    }, []string{"method", "route"})
+
+    httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+        Namespace: namespace,
+        Name:      "http_requests_in_flight",
+        Help:      "HTTP requests currently being served.",
+    })
+
+    dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "db_query_duration_seconds",
+        Help:      "GORM query latency by operation and table.",
+        Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+    }, []string{"operation", "table"})
+
+    dbQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "db_query_errors_total",
+        Help:      "Failed GORM queries by operation and table, not counting record not found.",
+    }, []string{"operation", "table"})
+
+    uploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "upload_bytes_total",
+        Help:      "Bytes received in uploads by kind (image or video).",
+    }, []string{"kind"})
+
+    enrollmentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "enrollments_created_total",
+        Help:      "Enrollments created.",
+    })
+
+    quizzesCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "quizzes_completed_total",
+        Help:      "Quizzes completed by learners.",
+    })
+
+    logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "logins_total",
+        Help:      "Login attempts by provider (google or dev) and result (success or failure).",
+    }, []string{"provider", "result"})
+
+    rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "rate_limited_total",
+        Help:      "Requests refused with 429 by rate limit group (ip, user, auth or actions).",
+    }, []string{"group"})
+
+    cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "cache_lookups_total",
+        Help:      "Cache lookups by key kind (course, courses or profile) and result (hit or miss).",
+    }, []string{"kind", "result"})
+
+    jobsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "jobs_processed_total",
+        Help:      "Background jobs run by kind and result (done, retry or dead).",
+    }, []string{"kind", "result"})
+
+    jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "job_duration_seconds",
+        Help:      "Background job run time by kind.",
+        Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+    }, []string{"kind"})
+
+    webhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "webhook_deliveries_total",
+        Help:      "Webhook delivery attempts by event and result (succeeded, retry or failed).",
+    }, []string{"event", "result"})
+
+    emailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "emails_total",
+        Help:      "Notification emails by template and result (sent, skipped, retry or failed).",
+    }, []string{"template", "result"})
+)
+
+func init() {
+    Registry.MustRegister(
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+        httpRequests, httpDuration, httpInFlight,
+        dbQueryDuration, dbQueryErrors,
+        uploadBytes, enrollmentsCreated, quizzesCompleted, logins, rateLimited, cacheLookups,
+        jobsProcessed, jobDuration, webhookDeliveries, emailsSent,
+    )
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format
+func Handler() http.Handler {
+    return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB exposes the connection pool statistics of db. Call it once per
+// process for the main database.
+func RegisterDB(db *sql.DB) {
+    Registry.MustRegister(collectors.NewDBStatsCollector(db, "main"))
+}
+
+// Middleware records the count and latency of every request. Requests are
+// labelled with the route template, e.g. /courses/:id, so IDs don't create
+// new series; unknown routes share the label "unmatched".
+func Middleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        start := time.Now()
+        httpInFlight.Inc()
+        defer httpInFlight.Dec()
+
+        c.Next()
+
+        route := c.FullPath()
+        if route == "" {
+            route = "unmatched"
+        }
+        httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+        httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+    }
+}
+
+// AddUploadBytes counts received upload bytes of the given kind
+func AddUploadBytes(kind string, n int64) {
+    if n > 0 {
+        uploadBytes.WithLabelValues(kind).Add(float64(n))
+    }
+}
+
+// EnrollmentCreated counts a new enrollment
+func EnrollmentCreated() {
+    enrollmentsCreated.Inc()
+}
+
+// QuizCompleted counts a completed quiz
+func QuizCompleted() {
+    quizzesCompleted.Inc()
+}
+
+// Login counts a login attempt with the given provider
+func Login(provider string, success bool) {
+    result := "failure"
+    if success {
+        result = "success"
+    }
+    logins.WithLabelValues(provider, result).Inc()
+}
+
+// RateLimited counts a request refused by the rate limit of group
+func RateLimited(group string) {
+    rateLimited.WithLabelValues(group).Inc()
+}
+
+// CacheLookup counts a cache lookup for a key of the given kind
+func CacheLookup(kind string, hit bool) {
+    result := "miss"
+    if hit {
+        result = "hit"
+    }
+    cacheLookups.WithLabelValues(kind, result).Inc()
+}
+
+// JobProcessed counts a run of a background job and records its duration
+func JobProcessed(kind, result string, duration time.Duration) {
+    jobsProcessed.WithLabelValues(kind, result).Inc()
+    jobDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
+
+// WebhookDelivered counts an attempt to deliver an event to a webhook
+func WebhookDelivered(event, result string) {
+    webhookDeliveries.WithLabelValues(event, result).Inc()
+}
+
+// EmailSent counts a notification email that was sent, skipped because of
+// the preferences of the user, or failed
+func EmailSent(template, result string) {
+    emailsSent.WithLabelValues(template, result).Inc()
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full, idle buckets are dropped from memory
+const sweepInterval = time.Minute
+
+// bucket is the state of one key
+type bucket struct {
+    tokens  float64
+    updated time.Time
+    limit   Limit
+}
+
+// MemoryStore keeps buckets in process memory. Every instance counts on its
+// own, so use the Redis store when running several instances.
+type MemoryStore struct {
+    mu        sync.Mutex
+    buckets   map[string]*bucket
+    lastSweep time.Time
+    now       func() time.Time // Bisa diganti di test
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+    return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := s.now()
+    s.sweep(now)
+
+    b, ok := s.buckets[key]
+    if !ok {
+        b = &bucket{tokens: float64(limit.Burst), updated: now}
+        s.buckets[key] = b
+    }
+    b.limit = limit
+    b.tokens = refill(b, now)
+    b.updated = now
+
+    allowed := b.tokens >= 1
+    if allowed {
+        b.tokens--
+    }
+    return result(limit, b.tokens, allowed), nil
+}
+
+// refill returns the tokens of b at now
+func refill(b *bucket, now time.Time) float64 {
+    elapsed := now.Sub(b.updated).Seconds()
+    if elapsed < 0 {
+        elapsed = 0
+    }
+    return math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+}
+
+// sweep drops buckets that are full again; they behave like new ones
+func (s *MemoryStore) sweep(now time.Time) {
+    if now.Sub(s.lastSweep) < sweepInterval {
+        return
+    }
+    s.lastSweep = now
+    for key, b := range s.buckets {
+        if refill(b, now) >= float64(b.limit.Burst) {
+            delete(s.buckets, key)
+        }
+    }
+}
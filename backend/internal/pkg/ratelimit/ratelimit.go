@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go-learn-platform/internal/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit is a token bucket: it holds Burst requests and refills Rate tokens
+// per second
+type Limit struct {
+    Burst int
+    Rate  float64
+}
+
+// Every returns a limit of n requests per period
+func Every(n int, period time.Duration) Limit {
+    return Limit{Burst: n, Rate: float64(n) / period.Seconds()}
+}
+
+// Parse reads a budget like "20/1m"
+func Parse(s string) (Limit, error) {
+    n, period, err := config.ParseRate(s)
+    if err != nil {
+        return Limit{}, err
+    }
+    return Every(n, period), nil
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+    Allowed    bool
+    Limit      int
+    Remaining  int
+    RetryAfter time.Duration // Kapan request berikutnya boleh dicoba, nol jika diizinkan
+    Reset      time.Duration // Kapan bucket penuh kembali
+}
+
+// Store keeps the buckets. Take removes one token from the bucket of key.
+type Store interface {
+    Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// NewStore creates the store selected by cfg.RateLimit.Store
+func NewStore(cfg *config.Config) (Store, error) {
+    if cfg.RateLimit.Store != "redis" {
+        return NewMemoryStore(), nil
+    }
+    opts, err := redis.ParseURL(cfg.Redis.URL)
+    if err != nil {
+        return nil, fmt.Errorf("parse REDIS_URL: %w", err)
+    }
+    return NewRedisStore(redis.NewClient(opts)), nil
+}
+
+// result derives the response of a bucket that has tokens left after the
+// request was counted or refused
+func result(limit Limit, tokens float64, allowed bool) Result {
+    res := Result{
+        Allowed:   allowed,
+        Limit:     limit.Burst,
+        Remaining: int(math.Floor(tokens)),
+        Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+    }
+    if !allowed {
+        res.RetryAfter = seconds((1 - tokens) / limit.Rate)
+    }
+    return res
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+    if s <= 0 {
+        return 0
+    }
+    return time.Duration(s * float64(time.Second))
+}
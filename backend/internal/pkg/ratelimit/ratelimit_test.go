@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParse(t *testing.T) {
+    limit, err := Parse("30/1m")
+    if err != nil || limit.Burst != 30 || limit.Rate != 0.5 {
+        t.Fatalf("unexpected limit %+v, %v", limit, err)
+    }
+    if _, err := Parse("30 per minute"); err == nil {
+        t.Fatal("expected an error for an invalid budget")
+    }
+}
+
+// testStores returns a memory and a Redis store sharing the fake clock now
+func testStores(t *testing.T, now *time.Time) map[string]Store {
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+
+    memory := NewMemoryStore()
+    memory.now = func() time.Time { return *now }
+    shared := NewRedisStore(client)
+    shared.now = func() time.Time { return *now }
+    return map[string]Store{"memory": memory, "redis": shared}
+}
+
+func TestTokenBucket(t *testing.T) {
+    now := time.Unix(1700000000, 0)
+    limit := Every(2, time.Minute)
+
+    for name, store := range testStores(t, &now) {
+        t.Run(name, func(t *testing.T) {
+            ctx := context.Background()
+            take := func() Result {
+                t.Helper()
+                res, err := store.Take(ctx, "auth:ip:"+name, limit)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                return res
+            }
+
+            if res := take(); !res.Allowed || res.Remaining != 1 || res.Limit != 2 {
+                t.Fatalf("unexpected first result %+v", res)
+            }
+            take()
+            res := take()
+            if res.Allowed || res.RetryAfter != 30*time.Second || res.Reset != time.Minute {
+                t.Fatalf("expected a refusal with retry after 30s, got %+v", res)
+            }
+
+            // Satu token kembali setiap 30 detik
+            now = now.Add(30 * time.Second)
+            if res := take(); !res.Allowed || res.Remaining != 0 {
+                t.Fatalf("expected a refilled token, got %+v", res)
+            }
+
+            // Bucket tidak pernah melebihi kapasitasnya
+            now = now.Add(time.Hour)
+            if res := take(); !res.Allowed || res.Remaining != 1 {
+                t.Fatalf("expected a full bucket, got %+v", res)
+            }
+        })
+    }
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+    now := time.Unix(1700000000, 0)
+    store := NewMemoryStore()
+    store.now = func() time.Time { return now }
+
+    store.Take(context.Background(), "ip:a", Every(5, time.Second))
+    now = now.Add(2 * sweepInterval)
+    store.Take(context.Background(), "ip:b", Every(5, time.Second))
+
+    if _, ok := store.buckets["ip:a"]; ok || len(store.buckets) != 1 {
+        t.Fatalf("expected the idle bucket to be dropped, got %v", store.buckets)
+    }
+}
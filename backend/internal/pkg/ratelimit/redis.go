@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the bucket keys in Redis
+const keyPrefix = "ratelimit:"
+
+// takeScript refills and takes from a bucket atomically. The bucket is a hash
+// with the token count and the time of the last update in milliseconds, and
+// expires once it would be full again.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+    tokens = burst
+    ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps buckets in a Redis-compatible server so all instances
+// share the same budget
+type RedisStore struct {
+    client redis.Scripter
+    now    func() time.Time
+}
+
+// NewRedisStore creates a store using client
+func NewRedisStore(client redis.Scripter) *RedisStore {
+    return &RedisStore{client: client, now: time.Now}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+    // Rate dalam token per milidetik, sama dengan satuan waktu di script
+    reply, err := takeScript.Run(ctx, s.client, []string{keyPrefix + key},
+        limit.Burst, limit.Rate/1000, s.now().UnixMilli()).Slice()
+    if err != nil {
+        return Result{}, fmt.Errorf("rate limit %s: %w", key, err)
+    }
+    if len(reply) != 2 {
+        return Result{}, fmt.Errorf("rate limit %s: unexpected reply %v", key, reply)
+    }
+
+    allowed, _ := reply[0].(int64)
+    raw, _ := reply[1].(string)
+    tokens, err := strconv.ParseFloat(raw, 64)
+    if err != nil {
+        return Result{}, fmt.Errorf("rate limit %s: unexpected token count %q", key, raw)
+    }
+    return result(limit, tokens, allowed == 1), nil
+}
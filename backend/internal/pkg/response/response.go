@@ -0,0 +1,165 @@
+// Package response writes the JSON envelopes shared by every API endpoint.
+//
+// Successful responses look like {"data": ..., "meta": {...}} and errors like
+// {"error": {"code": "...", "message": "...", "details": [...], "request_id": "..."}}.
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey is the gin context key holding the ID of the current request
+const RequestIDKey = "requestID"
+
+// Machine readable error codes
+const (
+    CodeBadRequest         = "bad_request"
+    CodeValidation         = "validation_failed"
+    CodeUnauthorized       = "unauthorized"
+    CodeInvalidToken       = "invalid_token"
+    CodeForbidden          = "forbidden"
+    CodeAccountDisabled    = "account_disabled"
+    CodeNotFound           = "not_found"
+    CodeConflict           = "conflict"
+    CodeAlreadyEnrolled    = "already_enrolled"
+    CodeNotEnrolled        = "not_enrolled"
+    CodeAlreadyCompleted   = "already_completed"
+    CodeInvalidSignature   = "invalid_signature"
+    CodeOffsetMismatch     = "upload_offset_mismatch"
+    CodeChecksumMismatch   = "checksum_mismatch"
+    CodePayloadTooLarge    = "payload_too_large"
+    CodeUnsupportedMedia   = "unsupported_media_type"
+    CodeUnprocessable      = "unprocessable"
+    CodeRateLimited        = "rate_limited"
+    CodePreconditionFailed = "precondition_failed"
+    CodeInternal           = "internal_error"
+)
+
+// Envelope wraps the payload of a successful response
+type Envelope struct {
+    Data    interface{} `json:"data"`
+    Meta    *Meta       `json:"meta,omitempty"`
+    Message string      `json:"message,omitempty"`
+}
+
+// Meta describes the page returned by a list endpoint
+type Meta struct {
+    Page       int   `json:"page"`
+    PerPage    int   `json:"per_page"`
+    Total      int64 `json:"total"`
+    TotalPages int   `json:"total_pages"`
+}
+
+// NewMeta builds the pagination meta of a list response
+func NewMeta(page, perPage int, total int64) *Meta {
+    pages := 0
+    if perPage > 0 {
+        pages = int((total + int64(perPage) - 1) / int64(perPage))
+    }
+    return &Meta{Page: page, PerPage: perPage, Total: total, TotalPages: pages}
+}
+
+// ErrorBody is the body of every error response
+type ErrorBody struct {
+    Error Error `json:"error"`
+}
+
+// Error describes what went wrong
+type Error struct {
+    Code      string       `json:"code"`
+    Message   string       `json:"message"`
+    Details   []FieldError `json:"details,omitempty"`
+    RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes an invalid input field
+type FieldError struct {
+    Field   string `json:"field"`
+    Code    string `json:"code"`
+    Message string `json:"message"`
+}
+
+// OK answers 200 with data
+func OK(c *gin.Context, data interface{}) {
+    c.JSON(http.StatusOK, Envelope{Data: data})
+}
+
+// Created answers 201 with the created resource
+func Created(c *gin.Context, data interface{}, message string) {
+    c.JSON(http.StatusCreated, Envelope{Data: data, Message: message})
+}
+
+// Updated answers 200 with the changed resource
+func Updated(c *gin.Context, data interface{}, message string) {
+    c.JSON(http.StatusOK, Envelope{Data: data, Message: message})
+}
+
+// Message answers 200 without data, e.g. after a delete
+func Message(c *gin.Context, message string) {
+    c.JSON(http.StatusOK, Envelope{Message: message})
+}
+
+// List answers 200 with a page of items
+func List(c *gin.Context, items interface{}, meta *Meta) {
+    c.JSON(http.StatusOK, Envelope{Data: items, Meta: meta})
+}
+
+// Fail answers with an error using the default code of the status
+func Fail(c *gin.Context, status int, message string) {
+    FailCode(c, status, CodeFor(status), message)
+}
+
+// FailCode answers with an error using a specific code
+func FailCode(c *gin.Context, status int, code, message string) {
+    c.JSON(status, ErrorBody{Error: Error{
+        Code:      code,
+        Message:   message,
+        RequestID: c.GetString(RequestIDKey),
+    }})
+}
+
+// AbortCode answers with an error and stops the handler chain
+func AbortCode(c *gin.Context, status int, code, message string) {
+    FailCode(c, status, code, message)
+    c.Abort()
+}
+
+// Invalid answers 400 with field level validation errors
+func Invalid(c *gin.Context, message string, details []FieldError) {
+    c.JSON(http.StatusBadRequest, ErrorBody{Error: Error{
+        Code:      CodeValidation,
+        Message:   message,
+        Details:   details,
+        RequestID: c.GetString(RequestIDKey),
+    }})
+}
+
+// CodeFor returns the default error code of an HTTP status
+func CodeFor(status int) string {
+    switch status {
+    case http.StatusBadRequest:
+        return CodeBadRequest
+    case http.StatusUnauthorized:
+        return CodeUnauthorized
+    case http.StatusForbidden:
+        return CodeForbidden
+    case http.StatusNotFound:
+        return CodeNotFound
+    case http.StatusConflict:
+        return CodeConflict
+    case http.StatusRequestEntityTooLarge:
+        return CodePayloadTooLarge
+    case http.StatusUnsupportedMediaType:
+        return CodeUnsupportedMedia
+    case http.StatusUnprocessableEntity:
+        return CodeUnprocessable
+    case http.StatusPreconditionFailed:
+        return CodePreconditionFailed
+    case http.StatusTooManyRequests:
+        return CodeRateLimited
+    default:
+        return CodeInternal
+    }
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey stores the span of a query in the GORM statement
+const spanKey = "tracing:span"
+
+// GormPlugin creates a client span for every GORM query, as a child of the
+// span in the statement context (use db.WithContext). Register it with
+// db.Use(tracing.GormPlugin{}).
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+    return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+    cb := db.Callback()
+    hooks := []struct {
+        operation string
+        before    func(name string, fn func(*gorm.DB)) error
+        after     func(name string, fn func(*gorm.DB)) error
+    }{
+        {"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+        // Span query ditutup setelah preload sehingga query Preload menjadi child-nya
+        {"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:after_query").Register},
+        {"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+        {"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+        {"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+        {"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+    }
+
+    for _, hook := range hooks {
+        if err := hook.before("tracing:before_"+hook.operation, startSpan(hook.operation)); err != nil {
+            return err
+        }
+        if err := hook.after("tracing:after_"+hook.operation, endSpan); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// startSpan opens the span of a query
+func startSpan(operation string) func(*gorm.DB) {
+    return func(db *gorm.DB) {
+        ctx := db.Statement.Context
+        if ctx == nil {
+            return
+        }
+
+        name := "gorm." + operation
+        if db.Statement.Table != "" {
+            name += " " + db.Statement.Table
+        }
+        ctx, span := Tracer().Start(ctx, name,
+            trace.WithSpanKind(trace.SpanKindClient),
+            trace.WithAttributes(
+                attribute.String("db.system", db.Dialector.Name()),
+                attribute.String("db.operation", operation),
+                attribute.String("db.sql.table", db.Statement.Table),
+            ))
+        db.Statement.Context = ctx
+        db.InstanceSet(spanKey, span)
+    }
+}
+
+// endSpan adds the executed SQL and the result to the span and ends it
+func endSpan(db *gorm.DB) {
+    value, ok := db.InstanceGet(spanKey)
+    if !ok {
+        return
+    }
+    span, ok := value.(trace.Span)
+    if !ok {
+        return
+    }
+
+    // SQL tanpa nilai parameter, jadi data pengguna tidak ikut terekspor
+    span.SetAttributes(
+        attribute.String("db.statement", db.Statement.SQL.String()),
+        attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+    )
+
+    // Record tidak ditemukan adalah hasil biasa, bukan error
+    err := db.Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        err = nil
+    }
+    End(span, err)
+}
@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/version"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this application
+const instrumentationName = "go-learn-platform"
+
+// Init installs the global tracer provider and the W3C trace context
+// propagator. With the exporter "none" spans are not recorded. The returned
+// function flushes pending spans and must be called on shutdown.
+func Init(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+    otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+    if !cfg.Tracing.Enabled() {
+        return func(context.Context) error { return nil }, nil
+    }
+
+    var exporter sdktrace.SpanExporter
+    var err error
+    switch cfg.Tracing.Exporter {
+    case "stdout":
+        exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+    case "otlp":
+        var opts []otlptracehttp.Option
+        if cfg.Tracing.Endpoint != "" {
+            // Endpoint adalah base URL collector, path trace ditambahkan seperti di spesifikasi OTLP
+            opts = append(opts, otlptracehttp.WithEndpointURL(strings.TrimRight(cfg.Tracing.Endpoint, "/")+"/v1/traces"))
+        }
+        exporter, err = otlptracehttp.New(ctx, opts...)
+    default:
+        err = fmt.Errorf("unknown trace exporter %q", cfg.Tracing.Exporter)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("create trace exporter: %w", err)
+    }
+
+    res, err := resource.New(ctx,
+        resource.WithFromEnv(),
+        resource.WithTelemetrySDK(),
+        resource.WithAttributes(
+            attribute.String("service.name", cfg.Tracing.ServiceName),
+            attribute.String("service.version", version.Get().Version),
+            attribute.String("deployment.environment", cfg.Env),
+        ),
+    )
+    if err != nil {
+        return nil, fmt.Errorf("create trace resource: %w", err)
+    }
+
+    provider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(res),
+        sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+    )
+    otel.SetTracerProvider(provider)
+    return provider.Shutdown, nil
+}
+
+// Tracer returns the application tracer of the global provider
+func Tracer() trace.Tracer {
+    return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+    return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+    if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+}
@@ -0,0 +1,87 @@
+package urls
+
+import (
+	"strings"
+
+	"go-learn-platform/internal/pkg/config"
+)
+
+// Builder builds absolute URLs for the API, the frontend and static assets
+type Builder struct {
+    APIBase      string // Base URL publik dari API, mis. https://api.example.com
+    FrontendBase string // Base URL aplikasi frontend
+    CDNBase      string // Base URL CDN untuk file /public, opsional
+}
+
+var defaultBuilder = &Builder{
+    APIBase:      "http://localhost:8080",
+    FrontendBase: "http://localhost:5173",
+}
+
+// New creates a Builder, trimming trailing slashes from the base URLs
+func New(apiBase, frontendBase, cdnBase string) *Builder {
+    return &Builder{
+        APIBase:      strings.TrimRight(apiBase, "/"),
+        FrontendBase: strings.TrimRight(frontendBase, "/"),
+        CDNBase:      strings.TrimRight(cdnBase, "/"),
+    }
+}
+
+// Init configures the package level builder used by the helpers below
+func Init(cfg *config.Config) {
+    defaultBuilder = New(cfg.URLs.PublicAPI, cfg.URLs.Frontend, cfg.URLs.CDN)
+}
+
+// Default returns the package level builder
+func Default() *Builder {
+    return defaultBuilder
+}
+
+// API returns the absolute URL of an API path
+func (b *Builder) API(path string) string {
+    return join(b.APIBase, path)
+}
+
+// Frontend returns the absolute URL of a frontend path
+func (b *Builder) Frontend(path string) string {
+    return join(b.FrontendBase, path)
+}
+
+// Asset returns the absolute URL of a stored file. Public catalog files are
+// served from the CDN when one is configured, everything else from the API.
+// Empty paths stay empty and absolute URLs are returned unchanged.
+func (b *Builder) Asset(path string) string {
+    if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+        return path
+    }
+    if b.CDNBase != "" && strings.HasPrefix(path, "/public/") {
+        return join(b.CDNBase, path)
+    }
+    return b.API(path)
+}
+
+// API returns the absolute URL of an API path using the default builder
+func API(path string) string {
+    return defaultBuilder.API(path)
+}
+
+// Frontend returns the absolute URL of a frontend path using the default builder
+func Frontend(path string) string {
+    return defaultBuilder.Frontend(path)
+}
+
+// Asset returns the absolute URL of a stored file using the default builder
+func Asset(path string) string {
+    return defaultBuilder.Asset(path)
+}
+
+// join concatenates a base URL and a path with exactly one slash
+func join(base, path string) string {
+    if path == "" {
+        return base
+    }
+    if !strings.HasPrefix(path, "/") {
+        path = "/" + path
+    }
+    return base + path
+}
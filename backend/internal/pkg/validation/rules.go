@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go-learn-platform/internal/models"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// existsModels lists the records the exists rule can look up, e.g.
+// `binding:"exists=course"`
+var existsModels = map[string]interface{}{
+    "course": &models.Course{},
+    "lesson": &models.Lesson{},
+    "quiz":   &models.Quiz{},
+}
+
+// personName allows letters from any script separated by spaces, dots,
+// apostrophes and hyphens
+var personName = regexp.MustCompile(`^\p{L}[\p{L}\p{M} .'-]*$`)
+
+// messages holds the translations of the custom rules and top level messages
+var messages = map[string]map[string]string{
+    "en": {
+        "exists":      "{0} does not exist",
+        "notblank":    "{0} must not be blank",
+        "personname":  "{0} may only contain letters, spaces, dots, apostrophes and hyphens",
+        "type":        "{0} has an invalid value",
+        "type_number": "{0} must be a number",
+        "invalid":     "Invalid input",
+        "malformed":   "Malformed request body",
+        "unavailable": "The request could not be validated, please try again",
+    },
+    "id": {
+        "exists":      "{0} tidak ditemukan",
+        "notblank":    "{0} tidak boleh kosong",
+        "personname":  "{0} hanya boleh berisi huruf, spasi, titik, apostrof dan tanda hubung",
+        "type":        "{0} memiliki nilai yang tidak valid",
+        "type_number": "{0} harus berupa angka",
+        "invalid":     "Input tidak valid",
+        "malformed":   "Body request tidak dapat dibaca",
+        "unavailable": "Request tidak dapat divalidasi, silakan coba lagi",
+    },
+}
+
+// registerRules adds the custom rules and their translations
+func registerRules(v *validator.Validate, translators ...ut.Translator) {
+    v.RegisterValidationCtx("exists", exists)
+    v.RegisterValidation("notblank", notBlank)
+    v.RegisterValidation("personname", func(fl validator.FieldLevel) bool {
+        return personName.MatchString(strings.TrimSpace(fl.Field().String()))
+    })
+
+    for _, trans := range translators {
+        for key, text := range messages[trans.Locale()] {
+            if err := trans.Add(key, text, true); err != nil {
+                panic(err)
+            }
+        }
+        for _, tag := range []string{"exists", "notblank", "personname"} {
+            tag := tag
+            v.RegisterTranslation(tag, trans, func(ut.Translator) error { return nil },
+                func(trans ut.Translator, fe validator.FieldError) string {
+                    text, _ := trans.T(tag, fe.Field())
+                    return text
+                })
+        }
+    }
+}
+
+// exists checks that the ID in the field belongs to a record that was not
+// deleted. An empty ID is left to the required rule. A failed lookup is
+// stored in the context for bind instead of being reported as invalid input.
+func exists(ctx context.Context, fl validator.FieldLevel) bool {
+    model, ok := existsModels[fl.Param()]
+    if !ok {
+        panic("validation: unknown model for exists rule: " + fl.Param())
+    }
+
+    field := fl.Field()
+    var id uint64
+    switch field.Kind() {
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        id = field.Uint()
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        id = uint64(field.Int())
+    default:
+        return false
+    }
+    if id == 0 {
+        return true
+    }
+
+    var count int64
+    if err := database.WithContext(ctx).Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+        if lookupErr, ok := ctx.Value(lookupKey{}).(*error); ok {
+            *lookupErr = err
+            return true
+        }
+        return false
+    }
+    return count > 0
+}
+
+// notBlank rejects strings made only of whitespace
+func notBlank(fl validator.FieldLevel) bool {
+    return strings.TrimSpace(fl.Field().String()) != ""
+}
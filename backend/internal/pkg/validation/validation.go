@@ -0,0 +1,248 @@
+// Package validation binds request bodies into input structs declared with
+// `binding` tags and answers invalid input with field level errors in the
+// language asked for by the Accept-Language header (English or Indonesian).
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-learn-platform/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+	"gorm.io/gorm"
+)
+
+var (
+    initOnce   sync.Once
+    translator *ut.UniversalTranslator
+    engine     *validator.Validate
+    database   *gorm.DB
+)
+
+// lookupKey holds the error of a failed database lookup of the exists rule
+// in the validation context
+type lookupKey struct{}
+
+// deferredValidator leaves validation to bind, which runs it with the
+// context of the request so database lookups are cancelled with it
+type deferredValidator struct {
+    binding.StructValidator
+}
+
+func (deferredValidator) ValidateStruct(interface{}) error {
+    return nil
+}
+
+// Init registers the custom rules and translations on gin's validator. The
+// database is used by the exists rule.
+func Init(db *gorm.DB) {
+    database = db
+
+    initOnce.Do(func() {
+        v, ok := binding.Validator.Engine().(*validator.Validate)
+        if !ok {
+            panic("validation: gin does not use go-playground/validator")
+        }
+        engine = v
+        binding.Validator = deferredValidator{binding.Validator}
+
+        // Pakai nama dari tag json/form agar pesan error cocok dengan input klien
+        v.RegisterTagNameFunc(fieldName)
+
+        english := en.New()
+        translator = ut.New(english, english, id.New())
+        enTrans, _ := translator.GetTranslator("en")
+        idTrans, _ := translator.GetTranslator("id")
+        if err := en_translations.RegisterDefaultTranslations(v, enTrans); err != nil {
+            panic(err)
+        }
+        if err := id_translations.RegisterDefaultTranslations(v, idTrans); err != nil {
+            panic(err)
+        }
+
+        registerRules(v, enTrans, idTrans)
+    })
+}
+
+// BindJSON decodes and validates a JSON body. It answers 400 and returns
+// false when the input is invalid.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+    return bind(c, obj, binding.JSON)
+}
+
+// BindForm decodes and validates a multipart or URL encoded form
+func BindForm(c *gin.Context, obj interface{}) bool {
+    return bind(c, obj, binding.Form)
+}
+
+// BindQuery decodes and validates query parameters
+func BindQuery(c *gin.Context, obj interface{}) bool {
+    return bind(c, obj, binding.Query)
+}
+
+func bind(c *gin.Context, obj interface{}, b binding.Binding) bool {
+    trans := Translator(c)
+
+    // Body kosong tetap divalidasi agar field wajib dilaporkan satu per satu
+    err := c.ShouldBindWith(obj, b)
+    if err == nil || errors.Is(err, io.EOF) {
+        var lookupErr error
+        ctx := context.WithValue(c.Request.Context(), lookupKey{}, &lookupErr)
+        err = engine.StructCtx(ctx, obj)
+        if lookupErr != nil {
+            // Database gagal, bukan input yang salah
+            slog.ErrorContext(ctx, "Validation lookup failed", "error", lookupErr)
+            response.Fail(c, http.StatusInternalServerError, message(trans, "unavailable"))
+            return false
+        }
+        if err == nil {
+            return true
+        }
+    }
+
+    var verrs validator.ValidationErrors
+    var typeErr *json.UnmarshalTypeError
+    var details []response.FieldError
+    switch {
+    case errors.As(err, &verrs):
+        for _, fe := range verrs {
+            details = append(details, response.FieldError{
+                Field:   fe.Field(),
+                Code:    fe.Tag(),
+                Message: fe.Translate(trans),
+            })
+        }
+    case errors.As(err, &typeErr):
+        details = append(details, typeError(trans, typeErr.Field, typeErr.Type.Kind()))
+    case b == binding.Form || b == binding.Query:
+        details = formTypeErrors(c, trans, obj, b == binding.Query)
+    }
+
+    if len(details) == 0 {
+        response.Invalid(c, message(trans, "malformed"), nil)
+        return false
+    }
+    response.Invalid(c, message(trans, "invalid"), details)
+    return false
+}
+
+// Translator returns the translator matching the Accept-Language header
+func Translator(c *gin.Context) ut.Translator {
+    for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+        tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+        lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+        if trans, found := translator.GetTranslator(lang); found {
+            return trans
+        }
+    }
+    trans, _ := translator.GetTranslator("en")
+    return trans
+}
+
+// Message returns a translated top level message such as "invalid"
+func Message(c *gin.Context, key string) string {
+    return message(Translator(c), key)
+}
+
+func message(trans ut.Translator, key string) string {
+    text, err := trans.T(key)
+    if err != nil {
+        return key
+    }
+    return text
+}
+
+// formTypeErrors finds form values that could not be parsed into numeric or
+// boolean fields, since gin's form binding does not report the field name
+func formTypeErrors(c *gin.Context, trans ut.Translator, obj interface{}, query bool) []response.FieldError {
+    var details []response.FieldError
+
+    t := reflect.TypeOf(obj).Elem()
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        name := strings.Split(field.Tag.Get("form"), ",")[0]
+        if name == "" || name == "-" {
+            continue
+        }
+
+        value := c.PostForm(name)
+        if query {
+            value = c.Query(name)
+        }
+        if value == "" {
+            continue
+        }
+
+        kind := field.Type.Kind()
+        if kind == reflect.Ptr {
+            kind = field.Type.Elem().Kind()
+        }
+        if !parses(value, kind) {
+            details = append(details, typeError(trans, name, kind))
+        }
+    }
+    return details
+}
+
+// parses reports whether a form value can be stored in a field of the kind
+func parses(value string, kind reflect.Kind) bool {
+    var err error
+    switch kind {
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        _, err = strconv.ParseInt(value, 10, 64)
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        _, err = strconv.ParseUint(value, 10, 64)
+    case reflect.Float32, reflect.Float64:
+        _, err = strconv.ParseFloat(value, 64)
+    case reflect.Bool:
+        _, err = strconv.ParseBool(value)
+    }
+    return err == nil
+}
+
+// typeError describes a value of the wrong type
+func typeError(trans ut.Translator, field string, kind reflect.Kind) response.FieldError {
+    key := "type"
+    switch kind {
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+        reflect.Float32, reflect.Float64:
+        key = "type_number"
+    }
+
+    text, err := trans.T(key, field)
+    if err != nil {
+        text = field + " has an invalid value"
+    }
+    return response.FieldError{Field: field, Code: "type", Message: text}
+}
+
+// fieldName returns the name clients use for a struct field
+func fieldName(field reflect.StructField) string {
+    for _, key := range []string{"json", "form"} {
+        name := strings.Split(field.Tag.Get(key), ",")[0]
+        if name == "-" {
+            return ""
+        }
+        if name != "" {
+            return name
+        }
+    }
+    return field.Name
+}
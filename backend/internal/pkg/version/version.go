@@ -0,0 +1,66 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, set at build time with:
+//
+//	go build -ldflags "-X go-learn-platform/internal/pkg/version.Version=v1.2.3 \
+//	    -X go-learn-platform/internal/pkg/version.Commit=$(git rev-parse --short HEAD) \
+//	    -X go-learn-platform/internal/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+    Version   = "dev"
+    Commit    = ""
+    BuildDate = ""
+)
+
+// Info describes the running build
+type Info struct {
+    Version   string `json:"version"`
+    Commit    string `json:"commit"`
+    BuildDate string `json:"build_date"`
+    GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information, falling back to the VCS data embedded
+// by the Go toolchain when the ldflags were not set
+func Get() Info {
+    info := Info{
+        Version:   Version,
+        Commit:    Commit,
+        BuildDate: BuildDate,
+        GoVersion: runtime.Version(),
+    }
+
+    if build, ok := debug.ReadBuildInfo(); ok {
+        for _, setting := range build.Settings {
+            switch setting.Key {
+            case "vcs.revision":
+                if info.Commit == "" && len(setting.Value) >= 7 {
+                    info.Commit = setting.Value[:7]
+                }
+            case "vcs.time":
+                if info.BuildDate == "" {
+                    info.BuildDate = setting.Value
+                }
+            }
+        }
+    }
+    return info
+}
+
+// String formats the build information on one line
+func (i Info) String() string {
+    commit := i.Commit
+    if commit == "" {
+        commit = "unknown"
+    }
+    date := i.BuildDate
+    if date == "" {
+        date = "unknown"
+    }
+    return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, commit, date, i.GoVersion)
+}
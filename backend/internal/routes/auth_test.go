@@ -0,0 +1,41 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/models"
+)
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+    s := apitest.New(t)
+
+    expectError(t, s.Get("/courses", nil), http.StatusUnauthorized, "Missing token")
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/courses",
+        Header: map[string]string{"Authorization": "Bearer not-a-jwt"},
+    })
+    expectError(t, res, http.StatusUnauthorized, "Invalid token")
+}
+
+func TestDeletedUserIsRejected(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("gone@example.com")
+
+    s.DB.Delete(&models.User{}, user.ID)
+
+    expectError(t, s.Get("/courses", &user), http.StatusUnauthorized, "User not found")
+}
+
+func TestDisabledUserIsRejected(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("blocked@example.com")
+
+    s.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("disabled_at", time.Now())
+
+    expectError(t, s.Get("/profile/me", &user), http.StatusForbidden, "Account is disabled")
+}
@@ -0,0 +1,240 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/admin"
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/seed"
+)
+
+// withCache enables the in-process catalog cache
+func withCache(cfg *config.Config) {
+    cfg.Cache = config.CacheConfig{Store: "memory", Size: 100, TTL: time.Minute}
+}
+
+func TestCourseDetailCache(t *testing.T) {
+    s := apitest.New(t, withCache)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    path := fmt.Sprintf("/courses/%d", f.Course.ID)
+    detail := func() dto.CourseDetail {
+        var detail dto.CourseDetail
+        s.Get(path, &instructor).ExpectStatus(http.StatusOK).Data(&detail)
+        return detail
+    }
+
+    detail()
+    // Perubahan langsung di database tidak terlihat selama entri masih di cache
+    s.DB.Model(&f.Course).Update("description", "Diubah di luar API")
+    if got := detail(); got.Description != "Belajar Go" {
+        t.Fatalf("expected the cached description, got %q", got.Description)
+    }
+
+    s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   path,
+        As:     &instructor,
+        Form:   map[string]string{"title": "Golang Lanjutan"},
+    }).ExpectStatus(http.StatusOK)
+    if got := detail(); got.Title != "Golang Lanjutan" || got.Description != "Diubah di luar API" {
+        t.Fatalf("expected a fresh course after the update, got %+v", got.Course)
+    }
+
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/lessons",
+        As:     &instructor,
+        Form:   map[string]string{"title": "Channel", "content": "Komunikasi", "order": "2", "course_id": fmt.Sprint(f.Course.ID)},
+    }).ExpectStatus(http.StatusCreated)
+    if got := detail(); len(got.Lessons) != 2 {
+        t.Fatalf("expected the new lesson, got %d lessons", len(got.Lessons))
+    }
+
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/quizzes",
+        As:     &instructor,
+        JSON:   map[string]interface{}{"lesson_id": f.Lessons[0].ID, "question": "Apa itu goroutine?", "options": "a, b", "answer": "a"},
+    }).ExpectStatus(http.StatusCreated)
+    if got := detail(); len(got.Lessons[0].Quizzes) != 2 {
+        t.Fatalf("expected the new quiz, got %d quizzes", len(got.Lessons[0].Quizzes))
+    }
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: fmt.Sprintf("/lesson/%d", f.Lessons[0].ID), As: &instructor}).
+        ExpectStatus(http.StatusOK)
+    if got := detail(); len(got.Lessons) != 1 || got.Lessons[0].Title != "Channel" {
+        t.Fatalf("expected the lesson to be gone, got %+v", got.Lessons)
+    }
+}
+
+func TestCourseListCache(t *testing.T) {
+    s := apitest.New(t, withCache)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 0)
+    total := func() int64 {
+        var list []dto.Course
+        return s.Get("/courses", &instructor).ExpectStatus(http.StatusOK).Data(&list).Total
+    }
+
+    total()
+    newCourse(t, s, instructor, 0)
+    if got := total(); got != 1 {
+        t.Fatalf("expected the cached page, got %d courses", got)
+    }
+
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/courses",
+        As:     &instructor,
+        Form:   map[string]string{"title": "Concurrency", "description": "Goroutine dan channel"},
+    }).ExpectStatus(http.StatusCreated)
+    if got := total(); got != 3 {
+        t.Fatalf("expected 3 courses after creating one, got %d", got)
+    }
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: fmt.Sprintf("/courses/%d", f.Course.ID), As: &instructor}).
+        ExpectStatus(http.StatusOK)
+    if got := total(); got != 2 {
+        t.Fatalf("expected 2 courses after deleting one, got %d", got)
+    }
+}
+
+func TestProfileCache(t *testing.T) {
+    s := apitest.New(t, withCache)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    profile := func(user apitest.User) dto.User {
+        var profile dto.User
+        s.Get(fmt.Sprintf("/profile/%d", user.ID), nil).ExpectStatus(http.StatusOK).Data(&profile)
+        return profile
+    }
+
+    if got := profile(student); len(got.EnrolledCourses) != 0 {
+        t.Fatalf("unexpected enrolled courses %+v", got.EnrolledCourses)
+    }
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}}).
+        ExpectStatus(http.StatusCreated)
+    if got := profile(student); len(got.EnrolledCourses) != 1 || *got.EnrolledCourses[0].Progress != 0 {
+        t.Fatalf("expected the new enrollment, got %+v", got.EnrolledCourses)
+    }
+
+    // Progress baru setelah quiz selesai tampil di profil
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 100},
+    }).ExpectStatus(http.StatusOK)
+    s.RunJobs()
+    if got := profile(student); *got.EnrolledCourses[0].Progress != 100 {
+        t.Fatalf("expected full progress, got %v", *got.EnrolledCourses[0].Progress)
+    }
+
+    // Nama instruktur tampil di profil dan detail kursus
+    profile(instructor)
+    coursePath := fmt.Sprintf("/courses/%d", f.Course.ID)
+    s.Get(coursePath, &student).ExpectStatus(http.StatusOK)
+    s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   "/profile/update",
+        As:     &instructor,
+        Form:   map[string]string{"name": "Budi Santoso"},
+    }).ExpectStatus(http.StatusOK)
+    if got := profile(instructor); got.Profile.Name != "Budi Santoso" {
+        t.Fatalf("expected the new name, got %q", got.Profile.Name)
+    }
+    var detail dto.CourseDetail
+    s.Get(coursePath, &student).ExpectStatus(http.StatusOK).Data(&detail)
+    if detail.Instructor == nil || detail.Instructor.Name != "Budi Santoso" {
+        t.Fatalf("expected the new instructor name, got %+v", detail.Instructor)
+    }
+}
+
+func TestRecomputeProgressRefreshesCache(t *testing.T) {
+    s := apitest.New(t, withCache)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+    progress := func() float64 {
+        var profile dto.User
+        s.Get(fmt.Sprintf("/profile/%d", student.ID), nil).ExpectStatus(http.StatusOK).Data(&profile)
+        return *profile.EnrolledCourses[0].Progress
+    }
+
+    progress()
+    // Lesson selesai tanpa event, mis. data yang diperbaiki langsung di database
+    now := s.DB.NowFunc()
+    s.Create(&models.LessonProgress{UserID: student.ID, LessonID: f.Lessons[0].ID, CompletedAt: &now})
+    if updated, err := admin.RecomputeProgress(s.DB, s.Services.Progress, f.Course.ID); err != nil || updated != 1 {
+        t.Fatalf("expected one enrollment recomputed, got %d, %v", updated, err)
+    }
+    if got := progress(); got != 100 {
+        t.Fatalf("expected the recomputed progress, got %v", got)
+    }
+}
+
+func TestSeedRefreshesCache(t *testing.T) {
+    s := apitest.New(t, withCache)
+    reader := s.CreateUser("sari@example.com")
+    total := func() int64 {
+        var list []dto.Course
+        return s.Get("/courses", &reader).ExpectStatus(http.StatusOK).Data(&list).Total
+    }
+
+    if got := total(); got != 0 {
+        t.Fatalf("expected no courses, got %d", got)
+    }
+    fixture, err := seed.Load(seed.DefaultFixture)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := seed.Apply(s.DB, fixture, s.Services.Catalog); err != nil {
+        t.Fatal(err)
+    }
+    if got := total(); got != int64(len(fixture.Courses)) {
+        t.Fatalf("expected the seeded courses, got %d", got)
+    }
+}
+
+func TestTransferCourseRefreshesCache(t *testing.T) {
+    s := apitest.New(t, withCache)
+    instructor := s.CreateUser("budi@example.com")
+    other := s.CreateUser("sari@example.com")
+    f := newCourse(t, s, instructor, 1)
+    profile := func(user apitest.User) dto.User {
+        var profile dto.User
+        s.Get(fmt.Sprintf("/profile/%d", user.ID), &user).ExpectStatus(http.StatusOK).Data(&profile)
+        return profile
+    }
+    owner := func() uint {
+        var detail dto.CourseDetail
+        s.Get(fmt.Sprintf("/courses/%d", f.Course.ID), &other).ExpectStatus(http.StatusOK).Data(&detail)
+        return detail.InstructorID
+    }
+
+    owner()
+    profile(instructor)
+    profile(other)
+    if _, err := admin.TransferCourse(s.DB, f.Course.ID, other.Email, s.Services.Catalog); err != nil {
+        t.Fatal(err)
+    }
+
+    if got := owner(); got != other.ID {
+        t.Fatalf("expected the new owner %d, got %d", other.ID, got)
+    }
+    if got := profile(instructor).CreatedCourses; len(got) != 0 {
+        t.Fatalf("expected no courses for the old owner, got %+v", got)
+    }
+    if got := profile(other).CreatedCourses; len(got) != 1 || got[0].ID != f.Course.ID {
+        t.Fatalf("expected the course for the new owner, got %+v", got)
+    }
+}
@@ -0,0 +1,118 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+)
+
+func TestCreateCourse(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("budi@example.com")
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/courses",
+        As:     &user,
+        Form:   map[string]string{"title": "Golang Dasar", "description": "Belajar Go"},
+        Files:  map[string][]byte{"image": []byte("png")},
+    }).ExpectStatus(http.StatusCreated)
+
+    var course dto.Course
+    res.Data(&course)
+    if course.Title != "Golang Dasar" || course.InstructorID != user.ID {
+        t.Fatalf("unexpected course %+v", course)
+    }
+    if !strings.HasPrefix(course.Image, "http://api.test/public/") {
+        t.Fatalf("expected an absolute image URL, got %q", course.Image)
+    }
+}
+
+func TestCreateCourseRequiresTitleAndDescription(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("budi@example.com")
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/courses",
+        As:     &user,
+        Form:   map[string]string{"title": "Tanpa deskripsi"},
+    })
+    expectFieldErrors(t, res, "description:required")
+}
+
+func TestListAndGetCourses(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 2)
+
+    var list []dto.Course
+    meta := s.Get("/courses", &student).ExpectStatus(http.StatusOK).Data(&list)
+    if len(list) != 1 || list[0].Instructor == nil || list[0].Instructor.ID != instructor.ID {
+        t.Fatalf("unexpected course list %+v", list)
+    }
+    if meta == nil || meta.Total != 1 || meta.Page != 1 {
+        t.Fatalf("unexpected pagination meta %+v", meta)
+    }
+
+    var detail dto.CourseDetail
+    s.Get(fmt.Sprintf("/courses/%d", f.Course.ID), &student).ExpectStatus(http.StatusOK).Data(&detail)
+    if detail.ID != f.Course.ID || len(detail.Lessons) != 2 || len(detail.Lessons[0].Quizzes) != 1 {
+        t.Fatalf("unexpected course %+v", detail)
+    }
+
+    expectError(t, s.Get("/courses/999", &student), http.StatusNotFound, "Course not found")
+    expectError(t, s.Get("/courses/abc", &student), http.StatusBadRequest, "Invalid course ID")
+}
+
+func TestUpdateCourse(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    other := s.CreateUser("sari@example.com")
+    f := newCourse(t, s, instructor, 0)
+    path := fmt.Sprintf("/courses/%d", f.Course.ID)
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   path,
+        As:     &other,
+        Form:   map[string]string{"title": "Dibajak"},
+    })
+    expectError(t, res, http.StatusForbidden, "You are not authorized to update this course")
+
+    s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   path,
+        As:     &instructor,
+        Form:   map[string]string{"title": "Golang Lanjutan"},
+    }).ExpectStatus(http.StatusOK)
+
+    var course models.Course
+    s.DB.First(&course, f.Course.ID)
+    if course.Title != "Golang Lanjutan" || course.Description != "Belajar Go" {
+        t.Fatalf("unexpected course after update %+v", course)
+    }
+
+    res = s.Do(apitest.Request{Method: http.MethodPut, Path: "/courses/999", As: &instructor, Form: map[string]string{}})
+    expectError(t, res, http.StatusNotFound, "Course not found")
+}
+
+func TestDeleteCourse(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    other := s.CreateUser("sari@example.com")
+    f := newCourse(t, s, instructor, 1)
+    path := fmt.Sprintf("/courses/%d", f.Course.ID)
+
+    res := s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &other})
+    expectError(t, res, http.StatusForbidden, "You are not authorized to delete this course")
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &instructor}).ExpectStatus(http.StatusOK)
+    expectError(t, s.Get(path, &instructor), http.StatusNotFound, "Course not found")
+}
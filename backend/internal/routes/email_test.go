@@ -0,0 +1,171 @@
+package routes_test
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/emails"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/mail/mailtest"
+)
+
+// withMail sends the emails of the server to smtp
+func withMail(smtp *mailtest.Server) func(*config.Config) {
+    return func(cfg *config.Config) {
+        cfg.Mail = smtp.Config()
+    }
+}
+
+// addLesson creates a lesson through the API
+func addLesson(s *apitest.Server, instructor *apitest.User, courseID uint, title string) {
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/lessons",
+        As:     instructor,
+        Form:   map[string]string{"title": title, "content": "Isi", "order": "5", "course_id": fmt.Sprint(courseID)},
+    }).ExpectStatus(http.StatusCreated)
+}
+
+func TestNotificationEmails(t *testing.T) {
+    smtp := mailtest.NewServer(t)
+    s := apitest.New(t, withMail(smtp))
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}}).
+        ExpectStatus(http.StatusCreated)
+    addLesson(s, &instructor, f.Course.ID, "Goroutine & <Channel>")
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 85},
+    }).ExpectStatus(http.StatusOK)
+    s.RunJobs()
+
+    messages := smtp.Messages()
+    if len(messages) != 3 {
+        t.Fatalf("expected 3 emails, got %d", len(messages))
+    }
+    subjects := make([]string, 0, len(messages))
+    for _, msg := range messages {
+        if len(msg.To) != 1 || msg.To[0] != student.Email || msg.From != "no-reply@example.com" {
+            t.Fatalf("unexpected envelope from %s to %v", msg.From, msg.To)
+        }
+        if !strings.HasPrefix(msg.Header.Get("List-Unsubscribe"), "<http://api.test/unsubscribe?token=") ||
+            msg.Header.Get("List-Unsubscribe-Post") != "List-Unsubscribe=One-Click" {
+            t.Fatalf("expected one-click unsubscribe headers, got %v", msg.Header)
+        }
+        if !strings.Contains(msg.Text, "http://app.test/unsubscribe?token=") || !strings.Contains(msg.HTML, "http://app.test/unsubscribe?token=") {
+            t.Fatalf("expected an unsubscribe link in both bodies")
+        }
+        subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+        subjects = append(subjects, subject)
+    }
+    want := []string{
+        "You are enrolled in " + f.Course.Title,
+        "New lesson in " + f.Course.Title + ": Goroutine & <Channel>",
+        "Your quiz result in " + f.Course.Title + ": 85",
+    }
+    for _, subject := range want {
+        if !slices.Contains(subjects, subject) {
+            t.Fatalf("expected an email %q, got %q", subject, subjects)
+        }
+    }
+    for _, msg := range messages {
+        if strings.Contains(msg.Text, "Goroutine") && !strings.Contains(msg.HTML, "Goroutine &amp; &lt;Channel&gt;") {
+            t.Fatalf("expected the lesson title to be escaped in HTML:\n%s", msg.HTML)
+        }
+    }
+
+    // Tidak ada email ganda saat job dijalankan lagi
+    s.RunJobs()
+    if got := len(smtp.Messages()); got != 3 {
+        t.Fatalf("expected no more emails, got %d", got)
+    }
+}
+
+func TestNotificationPreferences(t *testing.T) {
+    smtp := mailtest.NewServer(t)
+    s := apitest.New(t, withMail(smtp))
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    var settings dto.NotificationSettings
+    s.Get("/profile/notifications", &student).ExpectStatus(http.StatusOK).Data(&settings)
+    if !settings.Enrollments || !settings.NewLessons || !settings.QuizResults {
+        t.Fatalf("expected every email to be on by default, got %+v", settings)
+    }
+    s.Do(apitest.Request{Method: http.MethodPut, Path: "/profile/notifications", As: &student, JSON: map[string]bool{"new_lessons": false}}).
+        ExpectStatus(http.StatusOK).Data(&settings)
+    if !settings.Enrollments || settings.NewLessons || !settings.QuizResults {
+        t.Fatalf("expected only new lessons to be off, got %+v", settings)
+    }
+
+    addLesson(s, &instructor, f.Course.ID, "Tidak dikirim")
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 70},
+    }).ExpectStatus(http.StatusOK)
+    s.RunJobs()
+
+    messages := smtp.Messages()
+    if len(messages) != 1 || !strings.Contains(messages[0].Text, "Score: 70") {
+        t.Fatalf("expected only the quiz result email, got %d emails", len(messages))
+    }
+
+    // Unsubscribe satu klik dengan token dari header email, tanpa login
+    header := strings.Trim(messages[0].Header.Get("List-Unsubscribe"), "<>")
+    link, err := url.Parse(header)
+    if err != nil {
+        t.Fatal(err)
+    }
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/unsubscribe?" + link.RawQuery}).
+        ExpectStatus(http.StatusOK).Data(&settings)
+    if !settings.Enrollments || settings.QuizResults {
+        t.Fatalf("expected quiz results to be off, got %+v", settings)
+    }
+    s.Get("/profile/notifications", &student).ExpectStatus(http.StatusOK).Data(&settings)
+    if settings.QuizResults {
+        t.Fatal("expected the unsubscribe to be saved")
+    }
+
+    token := link.Query().Get("token")
+    forged := strings.Replace(token, fmt.Sprint(student.ID)+".", fmt.Sprint(instructor.ID)+".", 1)
+    expectError(t, s.Do(apitest.Request{Method: http.MethodPost, Path: "/unsubscribe?token=" + url.QueryEscape(forged)}),
+        http.StatusBadRequest, "Invalid unsubscribe link")
+    expectError(t, s.Do(apitest.Request{Method: http.MethodPost, Path: "/unsubscribe"}),
+        http.StatusBadRequest, "Invalid unsubscribe link")
+}
+
+func TestRejectedEmailsAreNotRetried(t *testing.T) {
+    smtp := mailtest.NewServer(t)
+    s := apitest.New(t, withMail(smtp))
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    smtp.Reject(550)
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}}).
+        ExpectStatus(http.StatusCreated)
+    s.RunJobs()
+
+    var job models.Job
+    s.DB.Where("kind = ?", emails.Send.Name).First(&job)
+    if job.Status != models.JobDead || job.Attempts != 1 || !strings.Contains(job.LastError, "550") {
+        t.Fatalf("expected the rejected email to die at once, got %+v", job)
+    }
+}
@@ -0,0 +1,87 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func TestEnrollUser(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    body := map[string]uint{"course_id": f.Course.ID}
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: body}).
+        ExpectStatus(http.StatusCreated)
+
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: body})
+    expectError(t, res, http.StatusBadRequest, "User is already enrolled in this course")
+
+    res = s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": 999}})
+    expectFieldErrors(t, res, "course_id:exists")
+
+    var list []dto.Enrollment
+    s.Get(fmt.Sprintf("/enrollments/%d", student.ID), &student).ExpectStatus(http.StatusOK).Data(&list)
+    if len(list) != 1 || list[0].Course == nil || list[0].Course.Title != f.Course.Title {
+        t.Fatalf("unexpected enrollments %+v", list)
+    }
+}
+
+func TestConcurrentEnrollOnlyOnce(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    // Request lain menyelesaikan enroll tepat setelah cek enrollment, sehingga
+    // hanya unique index yang bisa menolak insert ini
+    raced := false
+    err := s.DB.Callback().Query().After("gorm:query").Register("test:concurrent_enroll", func(tx *gorm.DB) {
+        if tx.Statement.Table != "enrollments" || raced {
+            return
+        }
+        raced = true
+        s.DB.Create(&models.Enrollment{UserID: student.ID, CourseID: f.Course.ID})
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}})
+    expectError(t, res, http.StatusBadRequest, "User is already enrolled in this course")
+    if !raced {
+        t.Fatal("expected the enrollment insert to race")
+    }
+
+    var count int64
+    s.DB.Model(&models.Enrollment{}).Where("user_id = ?", student.ID).Count(&count)
+    if count != 1 {
+        t.Fatalf("expected one enrollment, got %d", count)
+    }
+}
+
+func TestCancelEnrollment(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    other := s.CreateUser("rina@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enrollment := enroll(t, s, student, f.Course.ID)
+    path := fmt.Sprintf("/enroll/%d", enrollment.ID)
+
+    res := s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &other})
+    expectError(t, res, http.StatusForbidden, "You are not authorized to cancel this enrollment")
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &student}).ExpectStatus(http.StatusOK)
+
+    res = s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &student})
+    expectError(t, res, http.StatusNotFound, "Enrollment not found")
+}
@@ -0,0 +1,70 @@
+package routes_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/pkg/response"
+)
+
+func TestErrorEnvelope(t *testing.T) {
+    s := apitest.New(t)
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/courses",
+        Header: map[string]string{"X-Request-ID": "req-123"},
+    }).ExpectStatus(http.StatusUnauthorized)
+
+    apiErr := res.APIError()
+    if apiErr.Code != response.CodeUnauthorized || apiErr.RequestID != "req-123" {
+        t.Fatalf("unexpected error body %+v", apiErr)
+    }
+    if got := res.HTTP.Header.Get("X-Request-ID"); got != "req-123" {
+        t.Fatalf("expected the request ID to be echoed, got %q", got)
+    }
+
+    // Tanpa header, server membuat ID sendiri
+    apiErr = s.Get("/nowhere", nil).ExpectStatus(http.StatusNotFound).APIError()
+    if apiErr.Code != response.CodeNotFound || apiErr.RequestID == "" {
+        t.Fatalf("unexpected error body %+v", apiErr)
+    }
+}
+
+func TestListPagination(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    for i := 0; i < 3; i++ {
+        newCourse(t, s, instructor, 0)
+    }
+
+    var page []dto.Course
+    meta := s.Get("/courses?page=2&per_page=2", &instructor).ExpectStatus(http.StatusOK).Data(&page)
+    if len(page) != 1 {
+        t.Fatalf("expected 1 course on the second page, got %d", len(page))
+    }
+    if *meta != (response.Meta{Page: 2, PerPage: 2, Total: 3, TotalPages: 2}) {
+        t.Fatalf("unexpected pagination meta %+v", meta)
+    }
+
+    apiErr := s.Get("/courses?page=0&per_page=500", &instructor).ExpectStatus(http.StatusBadRequest).APIError()
+    if apiErr.Code != response.CodeValidation || len(apiErr.Details) != 2 {
+        t.Fatalf("expected field errors for page and per_page, got %+v", apiErr)
+    }
+}
+
+func TestResponsesHideInternalFields(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    newCourse(t, s, instructor, 1)
+
+    body := string(s.Get("/quizzes", &instructor).ExpectStatus(http.StatusOK).Body)
+    for _, field := range []string{"answer", "Answer", "DeletedAt", "deleted_at"} {
+        if strings.Contains(body, field) {
+            t.Fatalf("response leaks %s: %s", field, body)
+        }
+    }
+}
@@ -0,0 +1,145 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+)
+
+// conditionalGet sends a GET with If-None-Match
+func conditionalGet(s *apitest.Server, path string, as *apitest.User, etag string) *apitest.Response {
+    return s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   path,
+        As:     as,
+        Header: map[string]string{"If-None-Match": etag},
+    })
+}
+
+func TestCourseETag(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 2)
+    path := fmt.Sprintf("/courses/%d", f.Course.ID)
+
+    res := s.Get(path, &instructor).ExpectStatus(http.StatusOK)
+    etag := res.HTTP.Header.Get("ETag")
+    if !strings.HasPrefix(etag, `"`) || res.HTTP.Header.Get("Cache-Control") != "private, no-cache" {
+        t.Fatalf("expected a strong ETag and a revalidate policy, got %v", res.HTTP.Header)
+    }
+
+    res = conditionalGet(s, path, &instructor, etag).ExpectStatus(http.StatusNotModified)
+    if len(res.Body) != 0 || res.HTTP.Header.Get("ETag") != etag {
+        t.Fatalf("expected an empty 304 with the same ETag, got %q", res.Body)
+    }
+
+    // Perubahan quiz di dalam course mengganti ETag course
+    s.DB.Model(&f.Quizzes[1]).Update("question", "Apa itu goroutine?")
+    res = conditionalGet(s, path, &instructor, etag).ExpectStatus(http.StatusOK)
+    if res.HTTP.Header.Get("ETag") == etag {
+        t.Fatal("expected a new ETag after a quiz changed")
+    }
+}
+
+func TestCourseListETag(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    newCourse(t, s, instructor, 0)
+
+    etag := s.Get("/courses", &instructor).ExpectStatus(http.StatusOK).HTTP.Header.Get("ETag")
+    conditionalGet(s, "/courses", &instructor, etag).ExpectStatus(http.StatusNotModified)
+    conditionalGet(s, "/courses?per_page=5", &instructor, etag).ExpectStatus(http.StatusOK)
+
+    newCourse(t, s, instructor, 0)
+    conditionalGet(s, "/courses", &instructor, etag).ExpectStatus(http.StatusOK)
+}
+
+func TestLessonETagDependsOnAccess(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    s.DB.Model(&f.Lessons[0]).Update("image", "/media/private/lesson.png")
+    path := fmt.Sprintf("/lesson/%d", f.Lessons[0].ID)
+
+    etag := s.Get(path, &student).ExpectStatus(http.StatusOK).HTTP.Header.Get("ETag")
+    conditionalGet(s, path, &student, etag).ExpectStatus(http.StatusNotModified)
+
+    // Setelah mendaftar, response berisi URL media bertanda tangan
+    enroll(t, s, student, f.Course.ID)
+    res := conditionalGet(s, path, &student, etag).ExpectStatus(http.StatusOK)
+    var lesson struct {
+        Image string `json:"image"`
+    }
+    res.Data(&lesson)
+    if lesson.Image == "" || res.HTTP.Header.Get("ETag") == etag {
+        t.Fatalf("expected signed media and a new ETag, got %q", lesson.Image)
+    }
+}
+
+func TestUpdateCourseIfMatch(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    path := fmt.Sprintf("/courses/%d", f.Course.ID)
+    update := func(etag, title string) *apitest.Response {
+        return s.Do(apitest.Request{
+            Method: http.MethodPut,
+            Path:   path,
+            As:     &instructor,
+            Form:   map[string]string{"title": title},
+            Header: map[string]string{"If-Match": etag},
+        })
+    }
+
+    etag := s.Get(path, &instructor).ExpectStatus(http.StatusOK).HTTP.Header.Get("ETag")
+    update(etag, "Golang Lanjutan").ExpectStatus(http.StatusOK)
+
+    // Instruktur kedua masih memegang versi lama
+    res := update(etag, "Golang Menengah").ExpectStatus(http.StatusPreconditionFailed)
+    if code := res.APIError().Code; code != response.CodePreconditionFailed {
+        t.Fatalf("expected code %s, got %s", response.CodePreconditionFailed, code)
+    }
+    var course models.Course
+    s.DB.First(&course, f.Course.ID)
+    if course.Title != "Golang Lanjutan" {
+        t.Fatalf("expected the first update to survive, got %q", course.Title)
+    }
+
+    etag = s.Get(path, &instructor).ExpectStatus(http.StatusOK).HTTP.Header.Get("ETag")
+    update(etag, "Golang Menengah").ExpectStatus(http.StatusOK)
+    update("*", "Golang Mahir").ExpectStatus(http.StatusOK)
+    update(`W/`+etag, "Golang Pakar").ExpectStatus(http.StatusPreconditionFailed)
+}
+
+func TestUpdateLessonIfMatch(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    // Lesson dengan media: ETag memuat varian URL bertanda tangan
+    s.DB.Model(&f.Lessons[0]).Update("image", "/media/private/lesson.png")
+    path := fmt.Sprintf("/lesson/%d", f.Lessons[0].ID)
+    update := func(etag string) *apitest.Response {
+        return s.Do(apitest.Request{
+            Method: http.MethodPut,
+            Path:   path,
+            As:     &instructor,
+            Form: map[string]string{
+                "title":     "Instalasi Go",
+                "content":   "Pasang toolchain",
+                "order":     "1",
+                "course_id": fmt.Sprint(f.Course.ID),
+            },
+            Header: map[string]string{"If-Match": etag},
+        })
+    }
+
+    etag := s.Get(path, &instructor).ExpectStatus(http.StatusOK).HTTP.Header.Get("ETag")
+    update(etag).ExpectStatus(http.StatusOK)
+    update(etag).ExpectStatus(http.StatusPreconditionFailed)
+}
@@ -0,0 +1,125 @@
+package routes_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+)
+
+// eventNames returns the names of all outbox events in order
+func eventNames(s *apitest.Server) string {
+    var recorded []models.OutboxEvent
+    s.DB.Order("id").Find(&recorded)
+    names := make([]string, 0, len(recorded))
+    for _, event := range recorded {
+        names = append(names, event.Name)
+    }
+    return strings.Join(names, ",")
+}
+
+func TestLearningEvents(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+
+    var course dto.Course
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/courses",
+        As:     &instructor,
+        Form:   map[string]string{"title": "Golang Dasar", "description": "Belajar Go"},
+        Files:  map[string][]byte{"image": []byte("png")},
+    }).ExpectStatus(http.StatusCreated).Data(&course)
+    if got := eventNames(s); got != "course.created,course.published" {
+        t.Fatalf("unexpected events %s", got)
+    }
+
+    f := newCourse(t, s, instructor, 2)
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}}).
+        ExpectStatus(http.StatusCreated)
+    for _, quiz := range f.Quizzes {
+        s.Do(apitest.Request{
+            Method: http.MethodPost,
+            Path:   fmt.Sprintf("/quizzes/%d/complete", quiz.ID),
+            As:     &student,
+            JSON:   map[string]int{"score": 90},
+        }).ExpectStatus(http.StatusOK)
+    }
+
+    // Progress belum berubah sampai event diproses
+    var enrollment models.Enrollment
+    s.DB.Where("user_id = ? AND course_id = ?", student.ID, f.Course.ID).First(&enrollment)
+    if enrollment.Progress != 0 {
+        t.Fatalf("expected progress to wait for the subscriber, got %v", enrollment.Progress)
+    }
+
+    s.RunJobs()
+    s.DB.First(&enrollment, enrollment.ID)
+    if enrollment.Progress != 100 {
+        t.Fatalf("expected full progress, got %v", enrollment.Progress)
+    }
+    want := "course.created,course.published,enrollment.created," +
+        "quiz.submitted,lesson.completed,quiz.submitted,lesson.completed,course.completed"
+    if got := eventNames(s); got != want {
+        t.Fatalf("expected events %s, got %s", want, got)
+    }
+
+    // Setiap event diteruskan sekali ke tiap subscriber: progress untuk
+    // lesson.completed, webhooks untuk pendaftaran, kuis dan kursus selesai,
+    // emails untuk pendaftaran dan kuis, notifications untuk kuis
+    s.RunJobs()
+    var unpublished, deliveries int64
+    s.DB.Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&unpublished)
+    s.DB.Model(&models.Job{}).Where("kind = ? AND status = ?", "events.deliver", models.JobDone).Count(&deliveries)
+    if unpublished != 0 || deliveries != 11 {
+        t.Fatalf("expected all events published and 11 deliveries, got %d and %d", unpublished, deliveries)
+    }
+}
+
+func TestLessonCompletedOnce(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    // Lesson sudah selesai karena videonya ditonton
+    now := s.DB.NowFunc()
+    s.Create(&models.LessonProgress{UserID: student.ID, LessonID: f.Lessons[0].ID, CompletedAt: &now})
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 90},
+    }).ExpectStatus(http.StatusOK)
+
+    if got := eventNames(s); got != "quiz.submitted" {
+        t.Fatalf("expected only quiz.submitted, got %s", got)
+    }
+}
+
+func TestCourseCompletedOnce(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    now := s.DB.NowFunc()
+    s.Create(&models.LessonProgress{UserID: student.ID, LessonID: f.Lessons[0].ID, CompletedAt: &now})
+    // Perhitungan ulang yang berulang, mis. dari subscriber yang dijalankan lagi
+    for i := 0; i < 2; i++ {
+        if err := s.Services.Progress.Recalculate(context.Background(), student.ID, f.Course.ID); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if got := eventNames(s); got != "course.completed" {
+        t.Fatalf("expected course.completed once, got %s", got)
+    }
+}
@@ -0,0 +1,66 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+
+	"gorm.io/gorm"
+)
+
+// readiness is the body of /readyz
+type readiness struct {
+    Status string            `json:"status"`
+    Checks map[string]string `json:"checks"`
+}
+
+func getReadyz(t *testing.T, s *apitest.Server, status int) readiness {
+    t.Helper()
+    res := s.Get("/readyz", nil).ExpectStatus(status)
+    var body readiness
+    if err := json.Unmarshal(res.Body, &body); err != nil {
+        t.Fatalf("decode readiness: %v", err)
+    }
+    return body
+}
+
+func TestReadyz(t *testing.T) {
+    s := apitest.New(t)
+    // Probe hanya melakukan ping, tanpa query ke schema_migrations
+    var queries int
+    s.DB.Callback().Raw().Before("gorm:raw").Register("test:count_raw", func(*gorm.DB) { queries++ })
+    s.DB.Callback().Query().Before("gorm:query").Register("test:count_query", func(*gorm.DB) { queries++ })
+
+    body := getReadyz(t, s, http.StatusOK)
+    if body.Status != "ready" || body.Checks["database"] != "ok" || body.Checks["server"] != "ok" {
+        t.Fatalf("unexpected readiness %+v", body)
+    }
+    if _, ok := body.Checks["migrations"]; ok || queries != 0 {
+        t.Fatalf("expected no schema check per probe, got %+v and %d queries", body, queries)
+    }
+}
+
+func TestReadyzDatabaseDown(t *testing.T) {
+    s := apitest.New(t)
+    logs := captureLogs(t, s.Config.Log)
+    sqlDB, err := s.DB.DB()
+    if err != nil {
+        t.Fatal(err)
+    }
+    sqlDB.Close()
+
+    res := s.Get("/readyz", nil).ExpectStatus(http.StatusServiceUnavailable)
+    var body readiness
+    json.Unmarshal(res.Body, &body)
+    if body.Checks["database"] != "unavailable" {
+        t.Fatalf("unexpected readiness %+v", body)
+    }
+    // Penyebabnya hanya ada di log
+    records := logRecords(t, logs, "Readiness check failed")
+    if strings.Contains(string(res.Body), "closed") || len(records) != 1 || records[0]["check"] != "database" {
+        t.Fatalf("expected the error in the log only, got %s %v", res.Body, records)
+    }
+}
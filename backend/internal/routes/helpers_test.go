@@ -0,0 +1,75 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+)
+
+// fixture is a course owned by an instructor with one quiz per lesson
+type fixture struct {
+    Instructor apitest.User
+    Course     models.Course
+    Lessons    []models.Lesson
+    Quizzes    []models.Quiz
+}
+
+// newCourse creates a course with the given number of lessons directly in
+// the database
+func newCourse(t *testing.T, s *apitest.Server, instructor apitest.User, lessons int) fixture {
+    t.Helper()
+
+    f := fixture{Instructor: instructor}
+    f.Course = models.Course{Title: "Golang Dasar", Description: "Belajar Go", UserID: instructor.ID}
+    s.Create(&f.Course)
+
+    for i := 1; i <= lessons; i++ {
+        lesson := models.Lesson{CourseID: f.Course.ID, Title: fmt.Sprintf("Lesson %d", i), Content: "Isi", Order: i}
+        s.Create(&lesson)
+        quiz := models.Quiz{LessonID: lesson.ID, Question: "Q?", Options: "a, b", Answer: "a"}
+        s.Create(&quiz)
+        f.Lessons = append(f.Lessons, lesson)
+        f.Quizzes = append(f.Quizzes, quiz)
+    }
+    return f
+}
+
+// enroll enrolls a user directly in the database
+func enroll(t *testing.T, s *apitest.Server, user apitest.User, courseID uint) models.Enrollment {
+    t.Helper()
+    enrollment := models.Enrollment{UserID: user.ID, CourseID: courseID}
+    s.Create(&enrollment)
+    return enrollment
+}
+
+// expectError checks the status code and error message of a response
+func expectError(t *testing.T, res *apitest.Response, code int, message string) {
+    t.Helper()
+    res.ExpectStatus(code)
+    if got := res.ErrorMessage(); got != message {
+        t.Fatalf("expected error %q, got %q", message, got)
+    }
+}
+
+// expectFieldErrors checks a 400 validation_failed response and the field
+// codes it reports, as "field:code" pairs.
+func expectFieldErrors(t *testing.T, res *apitest.Response, fields ...string) {
+    t.Helper()
+    res.ExpectStatus(http.StatusBadRequest)
+    apiErr := res.APIError()
+    if apiErr.Code != response.CodeValidation {
+        t.Fatalf("expected code %q, got %q", response.CodeValidation, apiErr.Code)
+    }
+    got := make([]string, 0, len(apiErr.Details))
+    for _, detail := range apiErr.Details {
+        got = append(got, detail.Field+":"+detail.Code)
+    }
+    if strings.Join(got, ",") != strings.Join(fields, ",") {
+        t.Fatalf("expected field errors %v, got %v", fields, got)
+    }
+}
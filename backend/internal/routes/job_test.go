@@ -0,0 +1,60 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+)
+
+// newAdmin creates a user with the admin role
+func newAdmin(s *apitest.Server, email string) apitest.User {
+    admin := s.CreateUser(email)
+    s.DB.Model(&models.User{}).Where("id = ?", admin.ID).Update("role", models.RoleAdmin)
+    return admin
+}
+
+func TestAdminJobsRequireAdmin(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("andi@example.com")
+
+    expectError(t, s.Get("/admin/jobs", &user), http.StatusForbidden, "You are not allowed to access this resource")
+    expectError(t, s.Get("/admin/jobs", nil), http.StatusUnauthorized, "Missing token")
+}
+
+func TestListAndRetryJobs(t *testing.T) {
+    s := apitest.New(t)
+    admin := newAdmin(s, "admin@example.com")
+    now := time.Now()
+    dead := models.Job{Kind: "uploads.gc", Payload: `{"older_than":0}`, Status: models.JobDead, Attempts: 5, RunAt: now, LastError: "disk full", FinishedAt: &now}
+    done := models.Job{Kind: "jobs.cleanup", Payload: `{}`, Status: models.JobDone, Attempts: 1, RunAt: now, FinishedAt: &now}
+    s.Create(&dead)
+    s.Create(&done)
+
+    var list []dto.Job
+    meta := s.Get("/admin/jobs?status=dead", &admin).ExpectStatus(http.StatusOK).Data(&list)
+    if meta.Total != 1 || list[0].ID != dead.ID || list[0].LastError != "disk full" || string(list[0].Payload) != `{"older_than":0}` {
+        t.Fatalf("unexpected dead jobs %+v", list)
+    }
+    expectFieldErrors(t, s.Get("/admin/jobs?status=lost", &admin), "status:oneof")
+
+    retry := func(id uint) *apitest.Response {
+        return s.Do(apitest.Request{Method: http.MethodPost, Path: fmt.Sprintf("/admin/jobs/%d/retry", id), As: &admin})
+    }
+    var job dto.Job
+    retry(dead.ID).ExpectStatus(http.StatusOK).Data(&job)
+    if job.Status != models.JobPending || job.Attempts != 0 || job.FinishedAt != nil {
+        t.Fatalf("expected a fresh pending job, got %+v", job)
+    }
+    s.Get(fmt.Sprintf("/admin/jobs/%d", dead.ID), &admin).ExpectStatus(http.StatusOK).Data(&job)
+    if job.Status != models.JobPending {
+        t.Fatalf("expected the retried job to be pending, got %s", job.Status)
+    }
+
+    expectError(t, retry(done.ID), http.StatusConflict, "Only dead or pending jobs can be retried")
+    expectError(t, retry(999), http.StatusNotFound, "Job not found")
+}
@@ -0,0 +1,99 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+)
+
+func TestCreateAndUpdateLesson(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 0)
+
+    var created dto.Lesson
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/lessons",
+        As:     &instructor,
+        Form: map[string]string{
+            "title":     "Instalasi",
+            "content":   "Pasang Go",
+            "order":     "1",
+            "course_id": fmt.Sprint(f.Course.ID),
+        },
+        Files: map[string][]byte{"image": []byte("png")},
+    }).ExpectStatus(http.StatusCreated).Data(&created)
+
+    // Gambar lesson bersifat privat dan hanya bisa dibuka lewat URL bertanda tangan
+    if !strings.Contains(created.Image, "/media/private/") || !strings.Contains(created.Image, "signature=") {
+        t.Fatalf("expected a signed private image URL, got %q", created.Image)
+    }
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/lessons",
+        As:     &instructor,
+        Form:   map[string]string{"title": "x", "content": "x", "order": "satu", "course_id": fmt.Sprint(f.Course.ID)},
+    })
+    expectFieldErrors(t, res, "order:type")
+
+    var updated dto.Lesson
+    s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   fmt.Sprintf("/lesson/%d", created.ID),
+        As:     &instructor,
+        Form: map[string]string{
+            "title":     "Instalasi Go",
+            "content":   "Pasang toolchain",
+            "order":     "2",
+            "course_id": fmt.Sprint(f.Course.ID),
+        },
+    }).ExpectStatus(http.StatusOK).Data(&updated)
+    if updated.Title != "Instalasi Go" || updated.Order != 2 || updated.Image == "" {
+        t.Fatalf("unexpected lesson after update %+v", updated)
+    }
+}
+
+func TestGetLessonSignsMediaOnlyForEnrolledUsers(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    stranger := s.CreateUser("tamu@example.com")
+    f := newCourse(t, s, instructor, 1)
+    s.DB.Model(&f.Lessons[0]).Update("image", "/media/private/lesson.png")
+    enroll(t, s, student, f.Course.ID)
+    path := fmt.Sprintf("/lesson/%d", f.Lessons[0].ID)
+
+    var body dto.Lesson
+    s.Get(path, &student).ExpectStatus(http.StatusOK).Data(&body)
+    if !strings.Contains(body.Image, "signature=") {
+        t.Fatalf("expected a signed image URL for an enrolled user, got %q", body.Image)
+    }
+    if len(body.Quizzes) != 1 {
+        t.Fatalf("expected the lesson quizzes, got %+v", body.Quizzes)
+    }
+
+    s.Get(path, &stranger).ExpectStatus(http.StatusOK).Data(&body)
+    if body.Image != "" {
+        t.Fatalf("expected no image URL for a user who is not enrolled, got %q", body.Image)
+    }
+
+    expectError(t, s.Get("/lesson/999", &student), http.StatusNotFound, "Lesson not found")
+}
+
+func TestDeleteLesson(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    path := fmt.Sprintf("/lesson/%d", f.Lessons[0].ID)
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &instructor}).ExpectStatus(http.StatusOK)
+
+    res := s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &instructor})
+    expectError(t, res, http.StatusNotFound, "Lesson not found")
+}
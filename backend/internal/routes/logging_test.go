@@ -0,0 +1,86 @@
+package routes_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/logger"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test
+func captureLogs(t *testing.T, cfg config.LogConfig) *bytes.Buffer {
+    var buf bytes.Buffer
+    previous := slog.Default()
+    slog.SetDefault(logger.New(&buf, cfg))
+    t.Cleanup(func() { slog.SetDefault(previous) })
+    return &buf
+}
+
+// logRecords decodes JSON log lines with the given message
+func logRecords(t *testing.T, buf *bytes.Buffer, msg string) []map[string]interface{} {
+    t.Helper()
+    var records []map[string]interface{}
+    scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+    for scanner.Scan() {
+        var record map[string]interface{}
+        if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+            t.Fatalf("log line is not JSON: %s", scanner.Text())
+        }
+        if record["msg"] == msg {
+            records = append(records, record)
+        }
+    }
+    return records
+}
+
+func TestAccessLog(t *testing.T) {
+    s := apitest.New(t, func(cfg *config.Config) { cfg.Log.AccessLog = "structured" })
+    user := s.CreateUser("andi@example.com")
+    logs := captureLogs(t, s.Config.Log)
+
+    s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/courses?page=1&token=rahasia&Code=4%2F0Ab",
+        As:     &user,
+        Header: map[string]string{"X-Request-ID": "req-log-1"},
+    }).ExpectStatus(http.StatusOK)
+    s.Get("/courses/999", &user).ExpectStatus(http.StatusNotFound)
+
+    records := logRecords(t, logs, "http request")
+    if len(records) != 2 {
+        t.Fatalf("expected 2 access log records, got %d:\n%s", len(records), logs)
+    }
+
+    ok := records[0]
+    if ok["level"] != "INFO" || ok["route"] != "/courses" || ok["status"] != float64(200) {
+        t.Fatalf("unexpected access log record %v", ok)
+    }
+    if ok["query"] != "page=1&token=REDACTED&Code=REDACTED" {
+        t.Fatalf("sensitive query parameters were not redacted: %v", ok["query"])
+    }
+    if ok["request_id"] != "req-log-1" || ok["user_id"] != float64(user.ID) {
+        t.Fatalf("expected request and user ID in the record, got %v", ok)
+    }
+
+    // Route dicatat sebagai template, bukan path dengan ID
+    notFound := records[1]
+    if notFound["level"] != "WARN" || notFound["route"] != "/courses/:id" || notFound["request_id"] == "" {
+        t.Fatalf("unexpected access log record %v", notFound)
+    }
+}
+
+func TestAccessLogOff(t *testing.T) {
+    s := apitest.New(t)
+    logs := captureLogs(t, s.Config.Log)
+
+    s.Get("/healthz", nil).ExpectStatus(http.StatusOK)
+    if records := logRecords(t, logs, "http request"); len(records) != 0 {
+        t.Fatalf("expected no access log, got %v", records)
+    }
+}
@@ -0,0 +1,97 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/pkg/config"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+    const token = "metrics-token-0123456789"
+    s := apitest.New(t, func(cfg *config.Config) {
+        cfg.Metrics.Enabled = true
+        cfg.Metrics.Addr = ""
+        cfg.Metrics.Token = token
+    })
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}}).
+        ExpectStatus(http.StatusCreated)
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 90},
+    }).ExpectStatus(http.StatusOK)
+    s.Get(fmt.Sprintf("/courses/%d", f.Course.ID), &student).ExpectStatus(http.StatusOK)
+
+    // Tanpa token metrics tidak bisa dibaca, JWT biasa juga tidak cukup
+    s.Get("/metrics", nil).ExpectStatus(http.StatusUnauthorized)
+    s.Get("/metrics", &student).ExpectStatus(http.StatusUnauthorized)
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/metrics",
+        Header: map[string]string{"Authorization": "Bearer " + token},
+    }).ExpectStatus(http.StatusOK)
+
+    body := string(res.Body)
+    for _, want := range []string{
+        `golearn_http_requests_total{method="GET",route="/courses/:id",status="200"}`,
+        `golearn_http_request_duration_seconds_bucket{method="POST",route="/quizzes/:quiz_id/complete",`,
+        `golearn_db_query_duration_seconds_count{operation="query",table="courses"}`,
+        `golearn_http_requests_total{method="POST",route="/enroll",status="201"}`,
+        "golearn_enrollments_created_total ",
+        "golearn_quizzes_completed_total ",
+        "go_goroutines ",
+    } {
+        if !strings.Contains(body, want) {
+            t.Errorf("expected %q in /metrics", want)
+        }
+    }
+}
+
+func TestMetricsOnAPIPortFromEnvironment(t *testing.T) {
+    const token = "metrics-token-0123456789"
+    envFile := filepath.Join(t.TempDir(), ".env")
+    if err := os.WriteFile(envFile, []byte("METRICS_ADDR=:9090\n"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("DB_DSN", "postgres://app@localhost/app")
+    t.Setenv("JWT_SECRET", "test-secret-0123456789")
+    t.Setenv("METRICS_ENABLED", "true")
+    t.Setenv("METRICS_ADDR", "")
+    t.Setenv("METRICS_TOKEN", token)
+
+    // METRICS_ADDR kosong di environment menimpa default dan .env
+    loaded, err := config.Load(config.Options{EnvFile: envFile})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if loaded.Metrics.Addr != "" {
+        t.Fatalf("METRICS_ADDR = %q, want empty", loaded.Metrics.Addr)
+    }
+
+    s := apitest.New(t, func(cfg *config.Config) {
+        cfg.Metrics = loaded.Metrics
+    })
+    s.Get("/metrics", nil).ExpectStatus(http.StatusUnauthorized)
+    s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/metrics",
+        Header: map[string]string{"Authorization": "Bearer " + token},
+    }).ExpectStatus(http.StatusOK)
+}
+
+func TestMetricsDisabled(t *testing.T) {
+    s := apitest.New(t)
+    s.Get("/metrics", nil).ExpectStatus(http.StatusNotFound)
+}
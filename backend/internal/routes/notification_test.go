@@ -0,0 +1,298 @@
+package routes_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+)
+
+// streamEvent is an event read from the notification stream
+type streamEvent struct {
+    ID           string
+    Notification dto.Notification
+}
+
+// openStream connects to the notification stream of user and returns its
+// events. The stream is subscribed once the function returns.
+func openStream(t *testing.T, server *httptest.Server, user apitest.User, lastEventID string) <-chan streamEvent {
+    t.Helper()
+    ctx, cancel := context.WithCancel(context.Background())
+    t.Cleanup(cancel)
+
+    req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/notifications/stream", nil)
+    req.Header.Set("Authorization", "Bearer "+user.Token)
+    if lastEventID != "" {
+        req.Header.Set("Last-Event-ID", lastEventID)
+    }
+    res, err := server.Client().Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { res.Body.Close() })
+    if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+        t.Fatalf("expected an event stream, got %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+    }
+
+    out := make(chan streamEvent, 16)
+    go func() {
+        defer close(out)
+        var event streamEvent
+        scanner := bufio.NewScanner(res.Body)
+        for scanner.Scan() {
+            line := scanner.Text()
+            switch {
+            case strings.HasPrefix(line, "id: "):
+                event.ID = strings.TrimPrefix(line, "id: ")
+            case strings.HasPrefix(line, "data: "):
+                json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.Notification)
+            case line == "" && event.ID != "":
+                out <- event
+                event = streamEvent{}
+            }
+        }
+    }()
+    return out
+}
+
+func nextEvent(t *testing.T, events <-chan streamEvent) streamEvent {
+    t.Helper()
+    select {
+    case event, ok := <-events:
+        if !ok {
+            t.Fatal("stream closed")
+        }
+        return event
+    case <-time.After(5 * time.Second):
+        t.Fatal("no notification streamed")
+    }
+    return streamEvent{}
+}
+
+func TestNotificationInbox(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    other := s.CreateUser("sari@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    addLesson(s, &instructor, f.Course.ID, "Goroutine")
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 90},
+    }).ExpectStatus(http.StatusOK)
+    s.RunJobs()
+    // Subscriber yang dijalankan lagi tidak membuat notifikasi ganda
+    s.RunJobs()
+
+    var list []dto.Notification
+    meta := s.Get("/notifications", &student).ExpectStatus(http.StatusOK).Data(&list)
+    if meta == nil || meta.Total != 2 || len(list) != 2 {
+        t.Fatalf("expected 2 notifications, got %d", len(list))
+    }
+    // Terbaru lebih dulu
+    if list[0].Type != models.NotificationQuizGraded || list[1].Type != models.NotificationLessonAdded || list[0].Read {
+        t.Fatalf("unexpected notifications %+v", list)
+    }
+    var graded struct {
+        CourseTitle string `json:"course_title"`
+        Score       int    `json:"score"`
+    }
+    json.Unmarshal(list[0].Payload, &graded)
+    if graded.Score != 90 || graded.CourseTitle != f.Course.Title {
+        t.Fatalf("unexpected payload %s", list[0].Payload)
+    }
+
+    s.Get("/notifications", &other).ExpectStatus(http.StatusOK).Data(&list)
+    if len(list) != 0 {
+        t.Fatalf("expected no notifications for another user, got %d", len(list))
+    }
+    expectError(t, s.Do(apitest.Request{Method: http.MethodPost, Path: "/notifications/9999/read", As: &student}),
+        http.StatusNotFound, "Notification not found")
+
+    s.Get("/notifications?unread=true&per_page=1", &student).ExpectStatus(http.StatusOK).Data(&list)
+    first := list[0]
+    var read dto.Notification
+    s.Do(apitest.Request{Method: http.MethodPost, Path: fmt.Sprintf("/notifications/%d/read", first.ID), As: &student}).
+        ExpectStatus(http.StatusOK).Data(&read)
+    if !read.Read || read.ReadAt == nil {
+        t.Fatalf("expected the notification to be read, got %+v", read)
+    }
+    expectError(t, s.Do(apitest.Request{Method: http.MethodPost, Path: fmt.Sprintf("/notifications/%d/read", first.ID), As: &other}),
+        http.StatusNotFound, "Notification not found")
+
+    meta = s.Get("/notifications?unread=true", &student).ExpectStatus(http.StatusOK).Data(&list)
+    if meta.Total != 1 || list[0].ID == first.ID {
+        t.Fatalf("expected one other unread notification, got %+v", list)
+    }
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: "/notifications/read-all", As: &student}).ExpectStatus(http.StatusOK)
+    if msg := res.Map()["message"]; msg != "1 notifications marked as read" {
+        t.Fatalf("unexpected message %v", msg)
+    }
+    meta = s.Get("/notifications?unread=true", &student).ExpectStatus(http.StatusOK).Data(&list)
+    if meta.Total != 0 {
+        t.Fatalf("expected no unread notifications, got %d", meta.Total)
+    }
+}
+
+func TestNotificationStream(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    server := httptest.NewServer(s.Router)
+    t.Cleanup(server.Close)
+    events := openStream(t, server, student, "")
+
+    addLesson(s, &instructor, f.Course.ID, "Channel")
+    s.RunJobs()
+    lesson := nextEvent(t, events)
+    if lesson.Notification.Type != models.NotificationLessonAdded || lesson.ID != fmt.Sprint(lesson.Notification.ID) {
+        t.Fatalf("unexpected event %+v", lesson)
+    }
+
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 60},
+    }).ExpectStatus(http.StatusOK)
+    s.RunJobs()
+    if event := nextEvent(t, events); event.Notification.Type != models.NotificationQuizGraded {
+        t.Fatalf("unexpected event %+v", event)
+    }
+
+    // Client yang tersambung lagi menerima notifikasi yang terlewat
+    resumed := openStream(t, server, student, lesson.ID)
+    if event := nextEvent(t, resumed); event.Notification.Type != models.NotificationQuizGraded {
+        t.Fatalf("expected the missed notification, got %+v", event)
+    }
+
+    expectError(t, s.Do(apitest.Request{Method: http.MethodGet, Path: "/notifications/stream", As: &student, Header: map[string]string{"Last-Event-ID": "x"}}),
+        http.StatusBadRequest, "Invalid Last-Event-ID")
+}
+
+func TestNotificationStreamLateCommit(t *testing.T) {
+    s := apitest.New(t)
+    student := s.CreateUser("andi@example.com")
+
+    server := httptest.NewServer(s.Router)
+    t.Cleanup(server.Close)
+    events := openStream(t, server, student, "")
+
+    newer := models.Notification{ID: 100, UserID: student.ID, DedupeKey: "newer", Type: models.NotificationLessonAdded, Payload: "{}"}
+    s.DB.Create(&newer)
+    if event := nextEvent(t, events); event.Notification.ID != 100 {
+        t.Fatalf("unexpected event %+v", event)
+    }
+
+    // ID lebih kecil yang commit belakangan tetap dikirim, sekali saja
+    older := models.Notification{ID: 50, UserID: student.ID, DedupeKey: "older", Type: models.NotificationLessonAdded, Payload: "{}"}
+    s.DB.Create(&older)
+    event := nextEvent(t, events)
+    if event.Notification.ID != 50 || event.ID != "100" {
+        t.Fatalf("expected the late notification with the highest event ID, got %+v", event)
+    }
+    select {
+    case event := <-events:
+        t.Fatalf("expected every notification once, got %+v", event)
+    case <-time.After(200 * time.Millisecond):
+    }
+}
+
+func TestNotificationStreamCatchUpPages(t *testing.T) {
+    s := apitest.New(t)
+    student := s.CreateUser("andi@example.com")
+
+    // Lebih banyak dari satu halaman catch-up, terlalu lama untuk hub
+    created := time.Now().Add(-time.Hour)
+    missed := make([]models.Notification, 0, 251)
+    for i := 1; i <= 251; i++ {
+        missed = append(missed, models.Notification{
+            ID:        uint(i),
+            UserID:    student.ID,
+            DedupeKey: fmt.Sprintf("missed:%d", i),
+            Type:      models.NotificationLessonAdded,
+            Payload:   "{}",
+            CreatedAt: created,
+        })
+    }
+    s.Create(&missed)
+
+    server := httptest.NewServer(s.Router)
+    t.Cleanup(server.Close)
+    events := openStream(t, server, student, "1")
+    for want := uint(2); want <= 251; want++ {
+        if event := nextEvent(t, events); event.Notification.ID != want {
+            t.Fatalf("expected notification %d, got %+v", want, event)
+        }
+    }
+    select {
+    case event := <-events:
+        t.Fatalf("expected every notification once, got %+v", event)
+    case <-time.After(200 * time.Millisecond):
+    }
+}
+
+func TestNotificationStreamToken(t *testing.T) {
+    s := apitest.New(t)
+    student := s.CreateUser("andi@example.com")
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/notifications/stream-token"}).ExpectStatus(http.StatusUnauthorized)
+
+    var issued dto.StreamToken
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/notifications/stream-token", As: &student}).
+        ExpectStatus(http.StatusOK).Data(&issued)
+    if issued.Token == "" || time.Until(issued.ExpiresAt) > 2*time.Minute {
+        t.Fatalf("expected a short-lived token, got %+v", issued)
+    }
+
+    // Stream token bukan JWT login, dan JWT login tidak diterima di query
+    s.Get("/notifications", &apitest.User{ID: student.ID, Token: issued.Token}).ExpectStatus(http.StatusUnauthorized)
+    expectError(t, s.Get("/notifications/stream?token="+student.Token, nil), http.StatusUnauthorized, "Invalid token")
+
+    // EventSource: token dan Last-Event-ID lewat query, tanpa header
+    older := models.Notification{UserID: student.ID, DedupeKey: "older", Type: models.NotificationLessonAdded, Payload: "{}"}
+    missed := models.Notification{UserID: student.ID, DedupeKey: "missed", Type: models.NotificationQuizGraded, Payload: "{}"}
+    s.Create(&older)
+    s.Create(&missed)
+
+    server := httptest.NewServer(s.Router)
+    t.Cleanup(server.Close)
+    ctx, cancel := context.WithCancel(context.Background())
+    t.Cleanup(cancel)
+    path := fmt.Sprintf("%s/notifications/stream?token=%s&last_event_id=%d", server.URL, issued.Token, older.ID)
+    req, _ := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+    res, err := server.Client().Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer res.Body.Close()
+    if res.StatusCode != http.StatusOK {
+        t.Fatalf("expected the stream, got %d", res.StatusCode)
+    }
+
+    scanner := bufio.NewScanner(res.Body)
+    for scanner.Scan() {
+        if line := scanner.Text(); strings.HasPrefix(line, "id: ") {
+            if want := fmt.Sprintf("id: %d", missed.ID); line != want {
+                t.Fatalf("expected %q, got %q", want, line)
+            }
+            return
+        }
+    }
+    t.Fatal("no notification streamed")
+}
@@ -0,0 +1,63 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+)
+
+func TestMyProfile(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    var me dto.User
+    s.Get("/profile/me", &student).ExpectStatus(http.StatusOK).Data(&me)
+    if me.ID != student.ID || me.Email != student.Email || me.Role != "user" {
+        t.Fatalf("unexpected profile %+v", me)
+    }
+    if len(me.EnrolledCourses) != 1 || len(me.CreatedCourses) != 0 {
+        t.Fatalf("unexpected courses in profile %+v", me)
+    }
+}
+
+func TestPublicProfile(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    newCourse(t, s, instructor, 0)
+
+    // Profil publik bisa dibuka tanpa login
+    var profile dto.User
+    s.Get(fmt.Sprintf("/profile/%d", instructor.ID), nil).ExpectStatus(http.StatusOK).Data(&profile)
+    if profile.ID != instructor.ID || len(profile.CreatedCourses) != 1 {
+        t.Fatalf("unexpected profile %+v", profile)
+    }
+    if profile.Email != "" {
+        t.Fatalf("public profile must not expose the email, got %q", profile.Email)
+    }
+
+    expectError(t, s.Get("/profile/999", nil), http.StatusNotFound, "User not found")
+}
+
+func TestUpdateProfile(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("andi@example.com")
+
+    s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   "/profile/update",
+        As:     &user,
+        Form:   map[string]string{"name": "Andi Pratama"},
+    }).ExpectStatus(http.StatusOK)
+
+    var me dto.User
+    s.Get("/profile/me", &user).ExpectStatus(http.StatusOK).Data(&me)
+    if me.Profile.Name != "Andi Pratama" {
+        t.Fatalf("expected the new name, got %q", me.Profile.Name)
+    }
+}
@@ -0,0 +1,34 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+)
+
+func TestCourseProgress(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 4)
+    path := fmt.Sprintf("/courses/progress/%d", f.Course.ID)
+
+    expectError(t, s.Get(path, &student), http.StatusNotFound, "User is not enrolled in this course")
+
+    enroll(t, s, student, f.Course.ID)
+    s.Create(&models.QuizResult{UserID: student.ID, QuizID: f.Quizzes[0].ID, Score: 100})
+
+    // Lesson yang videonya sudah ditonton juga dihitung selesai
+    now := s.DB.NowFunc()
+    s.Create(&models.LessonProgress{UserID: student.ID, LessonID: f.Lessons[1].ID, CompletedAt: &now})
+
+    var body dto.CourseProgress
+    s.Get(path, &student).ExpectStatus(http.StatusOK).Data(&body)
+    if body.Progress != 50 {
+        t.Fatalf("expected progress 50, got %v", body.Progress)
+    }
+}
@@ -0,0 +1,135 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+)
+
+func TestCreateListAndDeleteQuiz(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    var created dto.Quiz
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/quizzes",
+        As:     &instructor,
+        JSON: map[string]interface{}{
+            "lesson_id": f.Lessons[0].ID,
+            "question":  "Apa itu goroutine?",
+            "options":   "thread ringan, proses, library",
+            "answer":    "thread ringan",
+        },
+    }).ExpectStatus(http.StatusCreated).Data(&created)
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/quizzes",
+        As:     &instructor,
+        JSON:   map[string]interface{}{"lesson_id": 999, "question": "?", "options": "a", "answer": "a"},
+    })
+    expectFieldErrors(t, res, "lesson_id:exists")
+
+    res = s.Do(apitest.Request{Method: http.MethodPost, Path: "/quizzes", As: &instructor, JSON: map[string]interface{}{}})
+    expectError(t, res, http.StatusBadRequest, "Invalid input")
+    expectFieldErrors(t, res, "lesson_id:required", "question:required", "options:required", "answer:required")
+
+    var list []dto.Quiz
+    s.Get("/quizzes", &instructor).ExpectStatus(http.StatusOK).Data(&list)
+    if len(list) != 2 {
+        t.Fatalf("expected 2 quizzes, got %d", len(list))
+    }
+
+    path := fmt.Sprintf("/quizzes/%d", created.ID)
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &instructor}).ExpectStatus(http.StatusOK)
+    expectError(t, s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &instructor}), http.StatusNotFound, "Quiz not found")
+}
+
+func TestCompleteQuiz(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 2)
+    enroll(t, s, student, f.Course.ID)
+    path := fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID)
+
+    s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]int{"score": 80}}).
+        ExpectStatus(http.StatusOK)
+
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]int{"score": 100}})
+    expectError(t, res, http.StatusBadRequest, "You have already completed this quiz")
+
+    res = s.Do(apitest.Request{Method: http.MethodPost, Path: "/quizzes/999/complete", As: &student, JSON: map[string]int{"score": 1}})
+    expectError(t, res, http.StatusNotFound, "Quiz not found")
+
+    // Pengguna yang tidak terdaftar tidak bisa menyelesaikan quiz
+    stranger := s.CreateUser("sari@example.com")
+    res = s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &stranger, JSON: map[string]int{"score": 100}})
+    if res.ExpectStatus(http.StatusForbidden).APIError().Code != response.CodeNotEnrolled {
+        t.Fatalf("expected strangers to be refused, got %s", res.Body)
+    }
+
+    // Progress di enrollment diperbarui oleh subscriber event
+    s.RunJobs()
+    var enrollment models.Enrollment
+    s.DB.Where("user_id = ? AND course_id = ?", student.ID, f.Course.ID).First(&enrollment)
+    if enrollment.Progress != 50 {
+        t.Fatalf("expected progress 50, got %v", enrollment.Progress)
+    }
+}
+
+func TestQuizResults(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    other := s.CreateUser("rina@example.com")
+    f := newCourse(t, s, instructor, 2)
+    submit := func(user apitest.User, score int) *apitest.Response {
+        return s.Do(apitest.Request{
+            Method: http.MethodPost,
+            Path:   "/quiz-results",
+            As:     &user,
+            JSON:   map[string]interface{}{"quiz_id": f.Quizzes[0].ID, "score": score},
+        })
+    }
+
+    // Sama seperti /quizzes/:quiz_id/complete: hanya peserta, sekali saja
+    if submit(student, 70).ExpectStatus(http.StatusForbidden).APIError().Code != response.CodeNotEnrolled {
+        t.Fatal("expected users who are not enrolled to be refused")
+    }
+    enroll(t, s, student, f.Course.ID)
+
+    var created dto.QuizResult
+    submit(student, 70).ExpectStatus(http.StatusCreated).Data(&created)
+    if created.UserID != student.ID || created.Score != 70 {
+        t.Fatalf("unexpected quiz result %+v", created)
+    }
+    expectError(t, submit(student, 100), http.StatusBadRequest, "You have already completed this quiz")
+
+    // Event dicatat, jadi progress ikut diperbarui
+    s.RunJobs()
+    var enrollment models.Enrollment
+    s.DB.Where("user_id = ? AND course_id = ?", student.ID, f.Course.ID).First(&enrollment)
+    if enrollment.Progress != 50 {
+        t.Fatalf("expected progress 50, got %v", enrollment.Progress)
+    }
+
+    var list []dto.QuizResult
+    s.Get("/quiz-results", &student).ExpectStatus(http.StatusOK).Data(&list)
+    if len(list) != 1 {
+        t.Fatalf("expected 1 quiz result, got %d", len(list))
+    }
+
+    path := fmt.Sprintf("/quiz-results/%d", created.ID)
+    res := s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &other})
+    expectError(t, res, http.StatusForbidden, "You are not authorized to delete this quiz result")
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: path, As: &student}).ExpectStatus(http.StatusOK)
+}
@@ -0,0 +1,106 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/response"
+)
+
+// withRateLimit enables rate limiting with generous budgets, except those
+// overridden by the test
+func withRateLimit(configure func(*config.RateLimitConfig)) func(*config.Config) {
+    return func(cfg *config.Config) {
+        cfg.RateLimit = config.RateLimitConfig{
+            Enabled: true,
+            Store:   "memory",
+            IP:      "1000/1m",
+            User:    "1000/1m",
+            Auth:    "1000/1m",
+            Actions: "1000/1m",
+        }
+        configure(&cfg.RateLimit)
+    }
+}
+
+func TestAuthRateLimit(t *testing.T) {
+    s := apitest.New(t, withRateLimit(func(rl *config.RateLimitConfig) { rl.Auth = "2/1m" }), func(cfg *config.Config) {
+        cfg.Features.DevLogin = true
+    })
+    user := models.User{GoogleID: models.SeedGoogleIDPrefix + "budi", Email: "budi@example.com", Role: models.RoleUser}
+    s.Create(&user)
+    login := func(ip string) *apitest.Response {
+        return s.Do(apitest.Request{
+            Method: http.MethodPost,
+            Path:   "/auth/dev/token",
+            JSON:   map[string]string{"email": user.Email},
+            Header: map[string]string{"X-Forwarded-For": ip},
+        })
+    }
+
+    for i := 0; i < 2; i++ {
+        login("203.0.113.7").ExpectStatus(http.StatusOK)
+    }
+
+    res := login("203.0.113.7").ExpectStatus(http.StatusTooManyRequests)
+    if code := res.APIError().Code; code != response.CodeRateLimited {
+        t.Fatalf("expected code %s, got %s", response.CodeRateLimited, code)
+    }
+    header := res.HTTP.Header
+    if header.Get("Retry-After") != "30" || header.Get("X-RateLimit-Limit") != "2" || header.Get("X-RateLimit-Remaining") != "0" {
+        t.Fatalf("unexpected rate limit headers %v", header)
+    }
+
+    // IP lain punya bucket sendiri
+    login("203.0.113.8").ExpectStatus(http.StatusOK)
+}
+
+func TestUserRateLimit(t *testing.T) {
+    s := apitest.New(t, withRateLimit(func(rl *config.RateLimitConfig) { rl.User = "3/1m" }))
+    budi := s.CreateUser("budi@example.com")
+    andi := s.CreateUser("andi@example.com")
+
+    res := s.Get("/courses", &budi).ExpectStatus(http.StatusOK)
+    if remaining := res.HTTP.Header.Get("X-RateLimit-Remaining"); remaining != "2" {
+        t.Fatalf("expected 2 requests remaining, got %q", remaining)
+    }
+    s.Get("/courses", &budi).ExpectStatus(http.StatusOK)
+    s.Get("/profile/me", &budi).ExpectStatus(http.StatusOK)
+    s.Get("/courses", &budi).ExpectStatus(http.StatusTooManyRequests)
+
+    // Semua request datang dari IP yang sama, tapi kuota dihitung per user
+    s.Get("/courses", &andi).ExpectStatus(http.StatusOK)
+}
+
+func TestActionRateLimit(t *testing.T) {
+    s := apitest.New(t, withRateLimit(func(rl *config.RateLimitConfig) { rl.Actions = "1/1m" }))
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": f.Course.ID}}).
+        ExpectStatus(http.StatusCreated)
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID),
+        As:     &student,
+        JSON:   map[string]int{"score": 90},
+    }).ExpectStatus(http.StatusTooManyRequests)
+
+    // Request baca tidak memakai kuota aksi
+    s.Get(fmt.Sprintf("/courses/%d", f.Course.ID), &student).ExpectStatus(http.StatusOK)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("budi@example.com")
+
+    res := s.Get("/courses", &user).ExpectStatus(http.StatusOK)
+    if res.HTTP.Header.Get("X-RateLimit-Limit") != "" {
+        t.Fatal("expected no rate limit headers when rate limiting is disabled")
+    }
+}
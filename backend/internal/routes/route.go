@@ -3,119 +3,335 @@ package routes
 import (
 	"go-learn-platform/internal/auth"
 	"go-learn-platform/internal/controllers"
+	"go-learn-platform/internal/docs"
+	"go-learn-platform/internal/emails"
 	"go-learn-platform/internal/middleware"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/cache"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/ratelimit"
+	"go-learn-platform/internal/pkg/response"
+	"go-learn-platform/internal/pkg/validation"
+	"go-learn-platform/internal/services"
+	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 )
 
-func Routes(r *gin.Engine, DB *gorm.DB) {
+// Routes registers the middleware and all routes on r and returns the
+// services behind them, for the job workers of the same process
+func Routes(r *gin.Engine, DB *gorm.DB, cfg *config.Config) *services.Services {
+    svc := NewServices(DB, cfg)
+    validation.Init(DB)
+    limit := rateLimiter(cfg)
+
+    // Setiap request mendapat ID untuk response error dan log, lalu dicatat
+    // di access log. Panic dijawab 500 dan ikut tercatat.
+    // Span per request, dinamai dengan template route
+    if cfg.Tracing.Enabled() {
+        r.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+    }
+    r.Use(middleware.RequestID())
+    if cfg.Metrics.Enabled {
+        r.Use(metrics.Middleware())
+    }
+    r.Use(middleware.AccessLog(cfg.Log, os.Stdout))
+    r.Use(middleware.Recovery())
+
+    // Security header untuk semua response, termasuk error dan preflight CORS
+    r.Use(middleware.SecurityHeaders(cfg.Security))
+    r.Use(middleware.CORS(cfg.Server))
+
     // Root route
     r.GET("/", func(ctx *gin.Context) {
-        ctx.JSON(http.StatusOK, gin.H{
-            "message": "Welcome to Go Learn Platform!",
-        })
+        response.Message(ctx, "Welcome to Go Learn Platform!")
+    })
+
+    // Route yang tidak dikenal tetap dijawab dengan format error standar
+    r.NoRoute(func(ctx *gin.Context) {
+        response.Fail(ctx, http.StatusNotFound, "Route not found")
+    })
+
+    // Liveness dan readiness probe untuk orchestrator
+    r.GET("/healthz", controllers.Healthz)
+    r.GET("/readyz", func(c *gin.Context) {
+        controllers.Readyz(c, DB)
     })
 
+    // Metrics Prometheus di port API hanya dengan token; biasanya disajikan
+    // di listener terpisah (METRICS_ADDR) oleh perintah serve
+    if cfg.Metrics.Enabled && cfg.Metrics.Addr == "" {
+        r.GET("/metrics", middleware.StaticToken(cfg.Metrics.Token), gin.WrapH(metrics.Handler()))
+    }
+
+    // Batas per IP untuk semua route yang didaftarkan setelah ini; probe dan
+    // metrics di atas tidak dibatasi
+    r.Use(limit("ip", cfg.RateLimit.IP))
+    authLimit := limit("auth", cfg.RateLimit.Auth)
+    actionLimit := limit("actions", cfg.RateLimit.Actions)
+
+    // Spesifikasi OpenAPI dan halaman dokumentasi API
+    r.GET("/openapi.json", docs.ServeSpec)
+    r.GET("/docs", middleware.ContentSecurityPolicy(docs.ContentSecurityPolicy), docs.ServeUI)
+
+    // Public catalog files dan media privat bertanda tangan. File upload
+    // disajikan dalam sandbox sehingga SVG/HTML tidak bisa menjalankan script.
+    files := r.Group("/", middleware.ContentSecurityPolicy(cfg.Security.FileContentSecurityPolicy))
+    files.GET("/public/*filepath", func(c *gin.Context) {
+        controllers.ServePublicFile(c, svc.Courses)
+    })
+    files.HEAD("/public/*filepath", func(c *gin.Context) {
+        controllers.ServePublicFile(c, svc.Courses)
+    })
+    files.GET("/media/*filepath", controllers.ServeSignedMedia)
+    files.HEAD("/media/*filepath", controllers.ServeSignedMedia)
+
     // Google OAuth routes
-    r.GET("/auth/google/login", auth.HandleGoogleLogin)
-    r.GET("/auth/google/callback", func(c *gin.Context) {
+    r.GET("/auth/google/login", authLimit, auth.HandleGoogleLogin)
+    r.GET("/auth/google/callback", authLimit, func(c *gin.Context) {
         auth.HandleGoogleCallback(c, DB)
     })
 
+    // Login tanpa OAuth untuk user seed (hanya development/QA)
+    if cfg.Features.DevLogin && !cfg.IsProduction() {
+        r.POST("/auth/dev/token", authLimit, func(c *gin.Context) {
+            auth.HandleDevToken(c, DB)
+        })
+        r.GET("/auth/dev/login", authLimit, func(c *gin.Context) {
+            auth.HandleDevLogin(c, DB)
+        })
+    }
+
     // Profile routes
     r.GET("/profile/:id", func(c *gin.Context) {
-        controllers.GetProfile(c, DB)
+        controllers.GetProfile(c, svc.Profiles)
+    })
+
+    // Link unsubscribe dari email notifikasi, tanpa login
+    unsubscribeKey := emails.UnsubscribeKey(cfg)
+    r.POST("/unsubscribe", authLimit, func(c *gin.Context) {
+        controllers.Unsubscribe(c, svc.Profiles, unsubscribeKey)
+    })
+
+    // Stream notifikasi juga menerima stream token di query untuk EventSource
+    userLimit := limit("user", cfg.RateLimit.User)
+    r.GET("/notifications/stream", middleware.StreamAuthMiddleware(DB), userLimit, func(c *gin.Context) {
+        controllers.StreamNotifications(c, svc.Notifications, cfg.Notifications.KeepAlive)
     })
 
     protected := r.Group("/")
     
-    protected.Use(middleware.AuthMiddleware())
+    protected.Use(middleware.AuthMiddleware(DB), userLimit)
     {
         //Get my profile
         protected.GET("/profile/me", func(c *gin.Context) {
-            controllers.GetMyProfile(c, DB)
+            controllers.GetMyProfile(c, svc.Profiles)
         })
         protected.PUT("/profile/update", func(c *gin.Context) {
-            controllers.UpdateProfile(c, DB)
+            controllers.UpdateProfile(c, svc.Profiles)
+        })
+        protected.GET("/profile/notifications", func(c *gin.Context) {
+            controllers.GetNotificationSettings(c, svc.Profiles)
+        })
+        protected.PUT("/profile/notifications", func(c *gin.Context) {
+            controllers.UpdateNotificationSettings(c, svc.Profiles)
         })
         
         // Course routes
         protected.POST("/courses", func(c *gin.Context) {
-            controllers.CreateCourse(c, DB)
+            controllers.CreateCourse(c, svc.Courses)
         })
         protected.GET("/courses", func(c *gin.Context) {
-            controllers.GetCourses(c, DB)
+            controllers.GetCourses(c, svc.Courses)
         })
         protected.GET("/courses/:id", func(c *gin.Context) {
-            controllers.GetCourse(c, DB)
+            controllers.GetCourse(c, svc.Courses)
         })
         protected.PUT("/courses/:id", func(c *gin.Context) {
-            controllers.UpdateCourse(c, DB)
+            controllers.UpdateCourse(c, svc.Courses)
         })
         protected.DELETE("/courses/:id", func(c *gin.Context) {
-            controllers.DeleteCourse(c, DB)
+            controllers.DeleteCourse(c, svc.Courses)
         })
 
         protected.GET("/courses/progress/:course_id", func(c *gin.Context) {
-            controllers.GetCourseProgress(c, DB)
+            controllers.GetCourseProgress(c, svc.Progress)
         })
 
 
         // Enrollment routes
-        protected.POST("/enroll", func(c *gin.Context) {
-            controllers.EnrollUser(c, DB)
+        protected.POST("/enroll", actionLimit, func(c *gin.Context) {
+            controllers.EnrollUser(c, svc.Enrollments)
         })
         protected.GET("/enrollments/:user_id", func(c *gin.Context) {
-            controllers.GetEnrollments(c, DB)
+            controllers.GetEnrollments(c, svc.Enrollments)
         })
         protected.DELETE("/enroll/:id", func(c *gin.Context) {
-            controllers.CancelEnrollment(c, DB)
+            controllers.CancelEnrollment(c, svc.Enrollments)
         })
 
         // Lesson routes
         protected.POST("/lessons", func(c *gin.Context) {
-            controllers.CreateLesson(c, DB)
+            controllers.CreateLesson(c, svc.Courses)
         })
         protected.GET("/lessons/:course_id", func(c *gin.Context) {
-            controllers.GetLesson(c, DB)
+            controllers.GetLesson(c, svc.Courses)
         })
         protected.GET("/lesson/:id", func(c *gin.Context) {
-            controllers.GetLesson(c, DB)
+            controllers.GetLesson(c, svc.Courses)
         })
         protected.PUT("/lesson/:id", func(c *gin.Context) {
-            controllers.UpdateLesson(c, DB)
+            controllers.UpdateLesson(c, svc.Courses)
         })
         protected.DELETE("/lesson/:id", func(c *gin.Context) {
-            controllers.DeleteLesson(c, DB)
+            controllers.DeleteLesson(c, svc.Courses)
         })
 
+        // Video routes (resumable upload, streaming dan heartbeat pemutaran)
+        if cfg.Features.VideoUploads {
+            protected.POST("/uploads", func(c *gin.Context) {
+                controllers.CreateUpload(c, svc.Uploads)
+            })
+            protected.HEAD("/uploads/:id", func(c *gin.Context) {
+                controllers.GetUpload(c, svc.Uploads)
+            })
+            protected.GET("/uploads/:id", func(c *gin.Context) {
+                controllers.GetUpload(c, svc.Uploads)
+            })
+            protected.PATCH("/uploads/:id", func(c *gin.Context) {
+                controllers.PatchUpload(c, svc.Uploads)
+            })
+            protected.DELETE("/uploads/:id", func(c *gin.Context) {
+                controllers.DeleteUpload(c, svc.Uploads)
+            })
+            protected.GET("/lesson/:id/video", func(c *gin.Context) {
+                controllers.StreamLessonVideo(c, svc.Courses)
+            })
+            protected.POST("/lesson/:id/video/heartbeat", func(c *gin.Context) {
+                controllers.VideoHeartbeat(c, svc.Progress)
+            })
+        }
+
         // Quiz routes
         protected.GET("/quizzes", func(c *gin.Context) {
-            controllers.GetAllQuizzes(c, DB)
+            controllers.GetAllQuizzes(c, svc.Quizzes)
         })
         protected.POST("/quizzes", func(c *gin.Context) {
-            controllers.CreateQuiz(c, DB)
+            controllers.CreateQuiz(c, svc.Quizzes)
         })
         protected.DELETE("/quizzes/:id", func(c *gin.Context) {
-            controllers.DeleteQuiz(c, DB)
+            controllers.DeleteQuiz(c, svc.Quizzes)
         })
         
         // Route untuk menyelesaikan quiz
-        protected.POST("/quizzes/:quiz_id/complete", func(c *gin.Context) {
-            controllers.CompleteQuiz(c, DB)
+        protected.POST("/quizzes/:quiz_id/complete", actionLimit, func(c *gin.Context) {
+            controllers.CompleteQuiz(c, svc.Quizzes)
         })
 
         // Quiz Result routes
         protected.GET("/quiz-results", func(c *gin.Context) {
-            controllers.GetQuizResults(c, DB)
+            controllers.GetQuizResults(c, svc.Quizzes)
         })
-        protected.POST("/quiz-results", func(c *gin.Context) {
-            controllers.CreateQuizResult(c, DB)
+        protected.POST("/quiz-results", actionLimit, func(c *gin.Context) {
+            controllers.CreateQuizResult(c, svc.Quizzes)
         })
         protected.DELETE("/quiz-results/:id", func(c *gin.Context) {
-            controllers.DeleteQuizResult(c, DB)
+            controllers.DeleteQuizResult(c, svc.Quizzes)
+        })
+
+        // Notifikasi in-app dan stream SSE-nya
+        protected.GET("/notifications", func(c *gin.Context) {
+            controllers.GetNotifications(c, svc.Notifications)
+        })
+        protected.POST("/notifications/read-all", func(c *gin.Context) {
+            controllers.MarkAllNotificationsRead(c, svc.Notifications)
+        })
+        protected.POST("/notifications/:id/read", func(c *gin.Context) {
+            controllers.MarkNotificationRead(c, svc.Notifications)
+        })
+        protected.POST("/notifications/stream-token", controllers.CreateStreamToken)
+
+        // Webhook routes: admin dan pemilik kursus
+        protected.GET("/webhooks", func(c *gin.Context) {
+            controllers.GetWebhooks(c, svc.Webhooks)
+        })
+        protected.POST("/webhooks", actionLimit, func(c *gin.Context) {
+            controllers.CreateWebhook(c, svc.Webhooks)
+        })
+        protected.GET("/webhooks/:id", func(c *gin.Context) {
+            controllers.GetWebhook(c, svc.Webhooks)
+        })
+        protected.PUT("/webhooks/:id", func(c *gin.Context) {
+            controllers.UpdateWebhook(c, svc.Webhooks)
+        })
+        protected.DELETE("/webhooks/:id", func(c *gin.Context) {
+            controllers.DeleteWebhook(c, svc.Webhooks)
+        })
+        protected.GET("/webhooks/:id/deliveries", func(c *gin.Context) {
+            controllers.GetWebhookDeliveries(c, svc.Webhooks)
+        })
+        protected.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", actionLimit, func(c *gin.Context) {
+            controllers.RedeliverWebhook(c, svc.Webhooks)
         })
+
+        // Admin routes: antrian job latar belakang
+        admin := protected.Group("/admin", middleware.RequireRole(models.RoleAdmin))
+        admin.GET("/jobs", func(c *gin.Context) {
+            controllers.GetJobs(c, svc.Jobs)
+        })
+        admin.GET("/jobs/:id", func(c *gin.Context) {
+            controllers.GetJob(c, svc.Jobs)
+        })
+        admin.POST("/jobs/:id/retry", func(c *gin.Context) {
+            controllers.RetryJob(c, svc.Jobs)
+        })
+    }
+    return svc
+}
+
+// NewServices wires the domain services with the configured catalog cache
+func NewServices(DB *gorm.DB, cfg *config.Config) *services.Services {
+    return services.New(DB, catalogCache(cfg), cfg.Cache.TTL, cfg.Webhooks, cfg.Notifications)
+}
+
+// catalogCache returns the cache store of the catalog services
+func catalogCache(cfg *config.Config) cache.Store {
+    store, err := cache.New(cfg)
+    if err != nil {
+        // Konfigurasi sudah divalidasi, jadi ini seharusnya tidak terjadi
+        slog.Error("cache store unavailable, caching disabled", "error", err)
+        return cache.Nop{}
+    }
+    return store
+}
+
+// rateLimiter returns a function creating the rate limit middleware of a
+// group with the given budget. All groups share one store; when rate limiting
+// is disabled the middleware does nothing.
+func rateLimiter(cfg *config.Config) func(group, budget string) gin.HandlerFunc {
+    noop := func(c *gin.Context) { c.Next() }
+    if !cfg.RateLimit.Enabled {
+        return func(string, string) gin.HandlerFunc { return noop }
+    }
+
+    store, err := ratelimit.NewStore(cfg)
+    if err != nil {
+        // Konfigurasi sudah divalidasi, jadi ini seharusnya tidak terjadi
+        slog.Error("rate limit store unavailable, using memory", "error", err)
+        store = ratelimit.NewMemoryStore()
+    }
+
+    return func(group, budget string) gin.HandlerFunc {
+        limit, err := ratelimit.Parse(budget)
+        if err != nil {
+            slog.Error("invalid rate limit, group not limited", "group", group, "error", err)
+            return noop
+        }
+        return middleware.RateLimit(store, group, limit)
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,110 @@
+package routes_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/pkg/config"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+    s := apitest.New(t)
+    user := s.CreateUser("budi@example.com")
+
+    header := s.Get("/courses", &user).ExpectStatus(http.StatusOK).HTTP.Header
+    expected := map[string]string{
+        "X-Content-Type-Options":  "nosniff",
+        "X-Frame-Options":         "DENY",
+        "Referrer-Policy":         "no-referrer",
+        "Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+    }
+    for name, value := range expected {
+        if got := header.Get(name); got != value {
+            t.Errorf("expected %s %q, got %q", name, value, got)
+        }
+    }
+    // HSTS hanya berarti lewat HTTPS
+    if header.Get("Strict-Transport-Security") != "" {
+        t.Error("expected no HSTS header over plain HTTP")
+    }
+
+    // Error juga membawa security header
+    res := s.Get("/courses", nil).ExpectStatus(http.StatusUnauthorized)
+    if res.HTTP.Header.Get("X-Content-Type-Options") != "nosniff" {
+        t.Error("expected security headers on error responses")
+    }
+
+    res = s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/courses",
+        As:     &user,
+        Header: map[string]string{"X-Forwarded-Proto": "https"},
+    })
+    if hsts := res.HTTP.Header.Get("Strict-Transport-Security"); hsts != "max-age=31536000" {
+        t.Errorf("expected HSTS behind an HTTPS proxy, got %q", hsts)
+    }
+}
+
+func TestPublicFilesAreSandboxed(t *testing.T) {
+    s := apitest.New(t)
+    if err := os.MkdirAll(s.Config.Storage.PublicDir, 0o755); err != nil {
+        t.Fatal(err)
+    }
+    svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+    if err := os.WriteFile(filepath.Join(s.Config.Storage.PublicDir, "logo.svg"), svg, 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    header := s.Get("/public/logo.svg", nil).ExpectStatus(http.StatusOK).HTTP.Header
+    if csp := header.Get("Content-Security-Policy"); csp != s.Config.Security.FileContentSecurityPolicy {
+        t.Fatalf("expected the file policy, got %q", csp)
+    }
+    if header.Get("X-Content-Type-Options") != "nosniff" {
+        t.Fatal("expected nosniff on public files")
+    }
+}
+
+func TestCORS(t *testing.T) {
+    s := apitest.New(t, func(cfg *config.Config) {
+        cfg.Server.CORSOrigins = []string{"http://app.test", "https://*.preview.example.com"}
+    })
+    user := s.CreateUser("budi@example.com")
+
+    for origin, allowed := range map[string]bool{
+        "http://app.test":                        true,
+        "https://pr-42.preview.example.com":      true,
+        "https://preview.example.com":            false,
+        "https://evil.test":                      false,
+        "https://evil.test/.preview.example.com": false,
+    } {
+        res := s.Do(apitest.Request{
+            Method: http.MethodOptions,
+            Path:   "/courses",
+            Header: map[string]string{
+                "Origin":                         origin,
+                "Access-Control-Request-Method":  http.MethodPost,
+                "Access-Control-Request-Headers": "Authorization, Content-Type",
+            },
+        })
+        got := res.HTTP.Header.Get("Access-Control-Allow-Origin")
+        if allowed && (res.Code != http.StatusNoContent || got != origin) {
+            t.Errorf("expected preflight from %s to be allowed, got %d %q", origin, res.Code, got)
+        }
+        if !allowed && (res.Code != http.StatusForbidden || got != "") {
+            t.Errorf("expected preflight from %s to be rejected, got %d %q", origin, res.Code, got)
+        }
+    }
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodGet,
+        Path:   "/courses",
+        As:     &user,
+        Header: map[string]string{"Origin": "http://app.test"},
+    }).ExpectStatus(http.StatusOK)
+    if res.HTTP.Header.Get("Access-Control-Allow-Credentials") != "true" {
+        t.Error("expected credentials to be allowed")
+    }
+}
@@ -0,0 +1,107 @@
+package routes_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider that keeps finished spans in memory
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+    exporter := tracetest.NewInMemoryExporter()
+    provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+    previous := otel.GetTracerProvider()
+    otel.SetTracerProvider(provider)
+    t.Cleanup(func() {
+        provider.Shutdown(context.Background())
+        otel.SetTracerProvider(previous)
+    })
+    return exporter
+}
+
+func TestRequestTracing(t *testing.T) {
+    spans := recordSpans(t)
+    s := apitest.New(t, func(cfg *config.Config) {
+        cfg.Tracing.Exporter = "stdout"
+        cfg.Tracing.ServiceName = "go-learn-test"
+    })
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 2)
+    spans.Reset()
+
+    s.Get(fmt.Sprintf("/courses/%d", f.Course.ID), &instructor).ExpectStatus(http.StatusOK)
+
+    var server sdktrace.ReadOnlySpan
+    for _, span := range spans.GetSpans().Snapshots() {
+        if span.SpanKind() == trace.SpanKindServer {
+            server = span
+        }
+    }
+    if server == nil || server.Name() != "/courses/:id" {
+        t.Fatalf("expected a server span named after the route template, got %v", spanNames(spans))
+    }
+
+    // Query GORM, termasuk Preload lesson dan quiz, berada di trace yang sama
+    queries := map[string]bool{}
+    for _, span := range spans.GetSpans().Snapshots() {
+        if span.SpanKind() != trace.SpanKindClient {
+            continue
+        }
+        if span.SpanContext().TraceID() != server.SpanContext().TraceID() {
+            t.Fatalf("query span %q is not part of the request trace", span.Name())
+        }
+        queries[span.Name()] = true
+    }
+    for _, name := range []string{"gorm.query courses", "gorm.query lessons", "gorm.query quizzes", "gorm.query users"} {
+        if !queries[name] {
+            t.Errorf("expected span %q, got %v", name, spanNames(spans))
+        }
+    }
+
+    attrs := map[string]string{}
+    for _, attr := range server.Attributes() {
+        attrs[string(attr.Key)] = attr.Value.Emit()
+    }
+    if attrs["http.request_id"] == "" || attrs["enduser.id"] != fmt.Sprint(instructor.ID) {
+        t.Fatalf("expected request and user ID on the server span, got %v", attrs)
+    }
+}
+
+func TestStorageSpans(t *testing.T) {
+    spans := recordSpans(t)
+    s := apitest.New(t, func(cfg *config.Config) { cfg.Tracing.Exporter = "stdout" })
+    user := s.CreateUser("budi@example.com")
+
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/courses",
+        As:     &user,
+        Form:   map[string]string{"title": "Belajar Go", "description": "Dasar"},
+        Files:  map[string][]byte{"image": []byte("png")},
+    }).ExpectStatus(http.StatusCreated)
+
+    for _, span := range spans.GetSpans().Snapshots() {
+        if span.Name() == "storage.save" && span.Parent().IsValid() {
+            return
+        }
+    }
+    t.Fatalf("expected a storage.save child span, got %v", spanNames(spans))
+}
+
+// spanNames lists the names of the recorded spans, for failure messages
+func spanNames(exporter *tracetest.InMemoryExporter) []string {
+    var names []string
+    for _, span := range exporter.GetSpans() {
+        names = append(names, span.Name)
+    }
+    return names
+}
@@ -0,0 +1,102 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+)
+
+func TestValidationMessagesAreLocalized(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+
+    request := apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/quizzes",
+        As:     &instructor,
+        JSON:   map[string]interface{}{"lesson_id": 999, "question": "   ", "options": "a", "answer": "a"},
+    }
+    res := s.Do(request)
+    expectFieldErrors(t, res, "lesson_id:exists", "question:notblank")
+    if got := res.APIError().Details[0].Message; got != "lesson_id does not exist" {
+        t.Fatalf("unexpected english message %q", got)
+    }
+
+    request.Header = map[string]string{"Accept-Language": "id-ID,id;q=0.9,en;q=0.8"}
+    apiErr := s.Do(request).ExpectStatus(http.StatusBadRequest).APIError()
+    if apiErr.Message != "Input tidak valid" {
+        t.Fatalf("unexpected indonesian message %q", apiErr.Message)
+    }
+    if got := apiErr.Details[0].Message; got != "lesson_id tidak ditemukan" {
+        t.Fatalf("unexpected indonesian field message %q", got)
+    }
+}
+
+func TestMultipartValidation(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    res := s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/lessons",
+        As:     &instructor,
+        Form:   map[string]string{"title": " ", "content": "Isi", "order": "0", "course_id": "999"},
+    })
+    expectFieldErrors(t, res, "title:notblank", "order:required", "course_id:exists")
+
+    res = s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   fmt.Sprintf("/courses/%d", f.Course.ID),
+        As:     &instructor,
+        Form:   map[string]string{"title": strings.Repeat("a", 201)},
+    })
+    expectFieldErrors(t, res, "title:max")
+
+    res = s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   "/profile/update",
+        As:     &instructor,
+        Form:   map[string]string{"name": "<script>"},
+    })
+    expectFieldErrors(t, res, "name:personname")
+}
+
+func TestJSONValidation(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 2)
+    enroll(t, s, student, f.Course.ID)
+    path := fmt.Sprintf("/quizzes/%d/complete", f.Quizzes[0].ID)
+
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]interface{}{"score": "tinggi"}})
+    expectFieldErrors(t, res, "score:type")
+
+    res = s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]int{"score": 101}})
+    expectFieldErrors(t, res, "score:max")
+
+    // Skor 0 tetap nilai yang sah
+    s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]int{"score": 0}}).
+        ExpectStatus(http.StatusOK)
+
+    res = s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]interface{}{}})
+    expectFieldErrors(t, res, "course_id:required")
+}
+
+func TestExistsLookupFailure(t *testing.T) {
+    s := apitest.New(t)
+    student := s.CreateUser("andi@example.com")
+    logs := captureLogs(t, s.Config.Log)
+
+    // Database gagal saat cek exists bukan kesalahan input
+    s.DB.Exec("ALTER TABLE courses RENAME TO courses_old")
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": 1}})
+    expectError(t, res, http.StatusInternalServerError, "The request could not be validated, please try again")
+    if records := logRecords(t, logs, "Validation lookup failed"); len(records) != 1 {
+        t.Fatalf("expected the lookup error in the log, got %v", records)
+    }
+}
@@ -0,0 +1,199 @@
+package routes_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/response"
+)
+
+// createUpload starts an upload of video for a lesson
+func createUpload(t *testing.T, s *apitest.Server, instructor *apitest.User, lessonID uint, video []byte, checksum string) dto.Upload {
+    t.Helper()
+    var upload dto.Upload
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/uploads",
+        As:     instructor,
+        JSON: map[string]interface{}{
+            "lesson_id":    lessonID,
+            "filename":     "intro.mp4",
+            "content_type": "video/mp4",
+            "size":         len(video),
+            "duration":     2,
+            "checksum":     checksum,
+        },
+    }).ExpectStatus(http.StatusCreated).Data(&upload)
+    return upload
+}
+
+// patchUpload sends a chunk at offset
+func patchUpload(s *apitest.Server, as *apitest.User, id string, offset int, chunk []byte, header map[string]string) *apitest.Response {
+    if header == nil {
+        header = map[string]string{}
+    }
+    header["Upload-Offset"] = fmt.Sprint(offset)
+    header["Content-Type"] = "application/offset+octet-stream"
+    return s.Do(apitest.Request{Method: http.MethodPatch, Path: "/uploads/" + id, As: as, Body: chunk, Header: header})
+}
+
+// uploadOffset asks the current offset of an upload with HEAD
+func uploadOffset(s *apitest.Server, as *apitest.User, id string) string {
+    res := s.Do(apitest.Request{Method: http.MethodHead, Path: "/uploads/" + id, As: as}).ExpectStatus(http.StatusOK)
+    return res.HTTP.Header.Get("Upload-Offset")
+}
+
+func chunkSum(chunk []byte) string {
+    sum := sha256.Sum256(chunk)
+    return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func fileSum(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+func TestResumableUpload(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    stranger := s.CreateUser("sari@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    video := []byte("0123456789abcdef")
+    upload := createUpload(t, s, &instructor, f.Lessons[0].ID, video, fileSum(video))
+    expectError(t, s.Get("/uploads/"+upload.ID, &stranger), http.StatusForbidden, "You are not authorized to access this upload")
+
+    patchUpload(s, &instructor, upload.ID, 0, video[:6], map[string]string{"Upload-Checksum": chunkSum(video[:6])}).
+        ExpectStatus(http.StatusNoContent)
+    if got := uploadOffset(s, &instructor, upload.ID); got != "6" {
+        t.Fatalf("expected offset 6, got %s", got)
+    }
+
+    // Chunk yang dikirim ulang dari offset lama ditolak dengan offset terkini
+    res := patchUpload(s, &instructor, upload.ID, 0, video[:6], nil).ExpectStatus(http.StatusConflict)
+    if res.APIError().Code != response.CodeOffsetMismatch || res.HTTP.Header.Get("Upload-Offset") != "6" {
+        t.Fatalf("expected an offset mismatch at 6, got %s %v", res.Body, res.HTTP.Header)
+    }
+
+    // Chunk rusak tidak memajukan offset
+    res = patchUpload(s, &instructor, upload.ID, 6, []byte("XXXXX"), map[string]string{"Upload-Checksum": chunkSum(video[6:11])}).
+        ExpectStatus(http.StatusBadRequest)
+    if res.APIError().Code != response.CodeChecksumMismatch {
+        t.Fatalf("expected a checksum mismatch, got %s", res.Body)
+    }
+    if got := uploadOffset(s, &instructor, upload.ID); got != "6" {
+        t.Fatalf("expected offset 6 after a bad chunk, got %s", got)
+    }
+
+    // Sisa file melanjutkan upload dan menyelesaikannya
+    res = patchUpload(s, &instructor, upload.ID, 6, video[6:], nil).ExpectStatus(http.StatusNoContent)
+    if res.HTTP.Header.Get("Upload-Offset") != fmt.Sprint(len(video)) {
+        t.Fatalf("expected the upload to be complete, got offset %s", res.HTTP.Header.Get("Upload-Offset"))
+    }
+    s.Get("/uploads/"+upload.ID, &instructor).ExpectStatus(http.StatusOK).Data(&upload)
+    if upload.CompletedAt == nil {
+        t.Fatal("expected the upload to be completed")
+    }
+    expectError(t, patchUpload(s, &instructor, upload.ID, len(video), nil, nil), http.StatusConflict, "Upload is already completed")
+    expectError(t, s.Do(apitest.Request{Method: http.MethodDelete, Path: "/uploads/" + upload.ID, As: &instructor}),
+        http.StatusConflict, "Upload is already completed")
+
+    // Video dapat di-seek dengan Range
+    path := fmt.Sprintf("/lesson/%d/video", f.Lessons[0].ID)
+    res = s.Do(apitest.Request{Method: http.MethodGet, Path: path, As: &student, Header: map[string]string{"Range": "bytes=4-9"}}).
+        ExpectStatus(http.StatusPartialContent)
+    if string(res.Body) != string(video[4:10]) || res.HTTP.Header.Get("Content-Range") != fmt.Sprintf("bytes 4-9/%d", len(video)) {
+        t.Fatalf("unexpected range response %q %v", res.Body, res.HTTP.Header)
+    }
+    res = s.Get(path, &instructor).ExpectStatus(http.StatusOK)
+    if string(res.Body) != string(video) || res.HTTP.Header.Get("Accept-Ranges") != "bytes" {
+        t.Fatalf("unexpected video response %q", res.Body)
+    }
+    s.Do(apitest.Request{Method: http.MethodGet, Path: path, As: &student, Header: map[string]string{"Range": "bytes=100-"}}).
+        ExpectStatus(http.StatusRequestedRangeNotSatisfiable)
+    res = s.Get(path, &stranger)
+    if res.ExpectStatus(http.StatusForbidden).APIError().Code != response.CodeNotEnrolled {
+        t.Fatalf("expected strangers to be refused, got %s", res.Body)
+    }
+}
+
+func TestUploadChecksumMismatchResets(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    video := []byte("0123456789")
+    upload := createUpload(t, s, &instructor, f.Lessons[0].ID, video, fileSum([]byte("lain")))
+    patchUpload(s, &instructor, upload.ID, 0, video[:4], nil).ExpectStatus(http.StatusNoContent)
+    res := patchUpload(s, &instructor, upload.ID, 4, video[4:], nil).ExpectStatus(http.StatusUnprocessableEntity)
+    if res.APIError().Code != response.CodeChecksumMismatch || res.HTTP.Header.Get("Upload-Offset") != "0" {
+        t.Fatalf("expected a checksum mismatch, got %s", res.Body)
+    }
+    if got := uploadOffset(s, &instructor, upload.ID); got != "0" {
+        t.Fatalf("expected the upload to start over, got offset %s", got)
+    }
+
+    var count int64
+    s.DB.Model(&models.LessonVideo{}).Count(&count)
+    if count != 0 {
+        t.Fatal("expected no video for a corrupt upload")
+    }
+
+    s.Do(apitest.Request{Method: http.MethodDelete, Path: "/uploads/" + upload.ID, As: &instructor}).ExpectStatus(http.StatusOK)
+    s.Get("/uploads/"+upload.ID, &instructor).ExpectStatus(http.StatusNotFound)
+}
+
+func TestVideoHeartbeatCompletesLesson(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    stranger := s.CreateUser("sari@example.com")
+    f := newCourse(t, s, instructor, 1)
+    enroll(t, s, student, f.Course.ID)
+
+    video := []byte("0123456789")
+    upload := createUpload(t, s, &instructor, f.Lessons[0].ID, video, "")
+    patchUpload(s, &instructor, upload.ID, 0, video, nil).ExpectStatus(http.StatusNoContent)
+
+    path := fmt.Sprintf("/lesson/%d/video/heartbeat", f.Lessons[0].ID)
+    res := s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &stranger, JSON: map[string]float64{"position": 0}})
+    if res.ExpectStatus(http.StatusForbidden).APIError().Code != response.CodeNotEnrolled {
+        t.Fatalf("expected strangers to be refused, got %s", res.Body)
+    }
+
+    // Melompat ke akhir video tidak dihitung sebagai menonton
+    var heartbeat dto.Heartbeat
+    for _, position := range []float64{0, 2} {
+        s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]float64{"position": position}}).
+            ExpectStatus(http.StatusOK).Data(&heartbeat)
+    }
+    if heartbeat.Completed || heartbeat.WatchedSeconds != 0 {
+        t.Fatalf("expected a jump not to count, got %+v", heartbeat)
+    }
+
+    for _, position := range []float64{0, 1, 2} {
+        s.Do(apitest.Request{Method: http.MethodPost, Path: path, As: &student, JSON: map[string]float64{"position": position}}).
+            ExpectStatus(http.StatusOK).Data(&heartbeat)
+    }
+    if !heartbeat.Completed || heartbeat.WatchedSeconds != 2 {
+        t.Fatalf("expected the lesson to be completed, got %+v", heartbeat)
+    }
+
+    s.RunJobs()
+    var progress struct {
+        Progress float64 `json:"progress"`
+    }
+    s.Get(fmt.Sprintf("/courses/progress/%d", f.Course.ID), &student).ExpectStatus(http.StatusOK).Data(&progress)
+    if progress.Progress != 100 {
+        t.Fatalf("expected the watched lesson to complete the course, got %v", progress.Progress)
+    }
+}
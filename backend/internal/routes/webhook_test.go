@@ -0,0 +1,204 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-learn-platform/internal/apitest"
+	"go-learn-platform/internal/dto"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/webhooks"
+)
+
+// receivedHook is a request caught by a receiver
+type receivedHook struct {
+    Header http.Header
+    Body   []byte
+}
+
+// receiver is a local webhook endpoint answering with status
+type receiver struct {
+    *httptest.Server
+    mu       sync.Mutex
+    status   int
+    received []receivedHook
+}
+
+func newReceiver(t *testing.T) *receiver {
+    r := &receiver{status: http.StatusOK}
+    r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        body, _ := io.ReadAll(req.Body)
+        r.mu.Lock()
+        defer r.mu.Unlock()
+        r.received = append(r.received, receivedHook{Header: req.Header.Clone(), Body: body})
+        w.WriteHeader(r.status)
+        fmt.Fprint(w, "ok")
+    }))
+    t.Cleanup(r.Close)
+    return r
+}
+
+func (r *receiver) answer(status int) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.status = status
+}
+
+func (r *receiver) requests() []receivedHook {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return append([]receivedHook(nil), r.received...)
+}
+
+func TestCreateWebhookPermissions(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    other := s.CreateUser("citra@example.com")
+    admin := newAdmin(s, "admin@example.com")
+    f := newCourse(t, s, instructor, 1)
+
+    create := func(as *apitest.User, body map[string]interface{}) *apitest.Response {
+        return s.Do(apitest.Request{Method: http.MethodPost, Path: "/webhooks", As: as, JSON: body})
+    }
+    events := []string{"enrollment.created"}
+
+    expectError(t, create(&instructor, map[string]interface{}{"url": "https://example.com/hook", "events": events}),
+        http.StatusForbidden, "Only admins can register webhooks for all courses")
+    expectError(t, create(&other, map[string]interface{}{"url": "https://example.com/hook", "events": events, "course_id": f.Course.ID}),
+        http.StatusForbidden, "You are not authorized to register webhooks for this course")
+    expectError(t, create(&instructor, map[string]interface{}{"url": "https://example.com/hook", "events": events, "course_id": 999}),
+        http.StatusNotFound, "Course not found")
+    expectFieldErrors(t, create(&instructor, map[string]interface{}{"url": "ftp://example.com", "events": []string{"user.registered"}}),
+        "url:http_url", "events[0]:oneof")
+
+    var hook dto.Webhook
+    create(&instructor, map[string]interface{}{"url": "https://example.com/hook", "events": events, "course_id": f.Course.ID}).
+        ExpectStatus(http.StatusCreated).Data(&hook)
+    if hook.Secret == "" || !hook.Active || hook.CourseID == nil || *hook.CourseID != f.Course.ID {
+        t.Fatalf("unexpected webhook %+v", hook)
+    }
+    create(&admin, map[string]interface{}{"url": "https://example.com/all", "events": events}).ExpectStatus(http.StatusCreated)
+
+    // Secret hanya dikirim saat webhook dibuat
+    var got dto.Webhook
+    s.Get(fmt.Sprintf("/webhooks/%d", hook.ID), &instructor).ExpectStatus(http.StatusOK).Data(&got)
+    if got.Secret != "" {
+        t.Fatalf("expected the secret to be hidden, got %q", got.Secret)
+    }
+    expectError(t, s.Get(fmt.Sprintf("/webhooks/%d", hook.ID), &other), http.StatusForbidden, "You are not authorized to manage this webhook")
+
+    var list []dto.Webhook
+    if meta := s.Get("/webhooks", &instructor).ExpectStatus(http.StatusOK).Data(&list); meta.Total != 1 {
+        t.Fatalf("expected the instructor to see 1 webhook, got %d", meta.Total)
+    }
+    if meta := s.Get("/webhooks", &admin).ExpectStatus(http.StatusOK).Data(&list); meta.Total != 2 {
+        t.Fatalf("expected the admin to see 2 webhooks, got %d", meta.Total)
+    }
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+    s := apitest.New(t)
+    instructor := s.CreateUser("budi@example.com")
+    student := s.CreateUser("andi@example.com")
+    f := newCourse(t, s, instructor, 1)
+    other := newCourse(t, s, instructor, 1)
+    r := newReceiver(t)
+
+    var hook dto.Webhook
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   "/webhooks",
+        As:     &instructor,
+        JSON:   map[string]interface{}{"url": r.URL, "events": []string{"enrollment.created"}, "course_id": f.Course.ID},
+    }).ExpectStatus(http.StatusCreated).Data(&hook)
+
+    // Hanya pendaftaran di kursus webhook yang dikirim
+    for _, courseID := range []uint{f.Course.ID, other.Course.ID} {
+        s.Do(apitest.Request{Method: http.MethodPost, Path: "/enroll", As: &student, JSON: map[string]uint{"course_id": courseID}}).
+            ExpectStatus(http.StatusCreated)
+    }
+    s.RunJobs()
+
+    received := r.requests()
+    if len(received) != 1 {
+        t.Fatalf("expected 1 delivery, got %d", len(received))
+    }
+    req := received[0]
+    if err := webhooks.Verify(hook.Secret, req.Header.Get(webhooks.SignatureHeader), req.Body, time.Minute, time.Now()); err != nil {
+        t.Fatalf("expected a valid signature: %v", err)
+    }
+    if err := webhooks.Verify("whsec_other", req.Header.Get(webhooks.SignatureHeader), req.Body, time.Minute, time.Now()); err == nil {
+        t.Fatal("expected another secret to fail")
+    }
+    var body struct {
+        Event string `json:"event"`
+        Data  struct {
+            UserID   uint `json:"user_id"`
+            CourseID uint `json:"course_id"`
+        } `json:"data"`
+    }
+    if err := json.Unmarshal(req.Body, &body); err != nil {
+        t.Fatal(err)
+    }
+    if body.Event != "enrollment.created" || req.Header.Get(webhooks.EventHeader) != body.Event ||
+        body.Data.UserID != student.ID || body.Data.CourseID != f.Course.ID {
+        t.Fatalf("unexpected delivery %s", req.Body)
+    }
+
+    var deliveries []dto.WebhookDelivery
+    s.Get(fmt.Sprintf("/webhooks/%d/deliveries", hook.ID), &instructor).ExpectStatus(http.StatusOK).Data(&deliveries)
+    if len(deliveries) != 1 || deliveries[0].Status != models.DeliverySucceeded || deliveries[0].Attempts != 1 ||
+        deliveries[0].StatusCode != http.StatusOK || deliveries[0].Response != "ok" || deliveries[0].DeliveredAt == nil {
+        t.Fatalf("unexpected delivery log %+v", deliveries)
+    }
+    if got := req.Header.Get(webhooks.DeliveryHeader); got != fmt.Sprint(deliveries[0].ID) {
+        t.Fatalf("expected delivery header %d, got %s", deliveries[0].ID, got)
+    }
+
+    // Receiver yang gagal: pengiriman tetap pending untuk dicoba lagi
+    r.answer(http.StatusServiceUnavailable)
+    var redelivery dto.WebhookDelivery
+    s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", hook.ID, deliveries[0].ID),
+        As:     &instructor,
+    }).ExpectStatus(http.StatusCreated).Data(&redelivery)
+    if redelivery.RedeliveryOf == nil || *redelivery.RedeliveryOf != deliveries[0].ID || redelivery.EventID != deliveries[0].EventID {
+        t.Fatalf("unexpected redelivery %+v", redelivery)
+    }
+    s.RunJobs()
+
+    received = r.requests()
+    if len(received) != 2 || string(received[1].Body) != string(req.Body) {
+        t.Fatalf("expected the same body to be sent again, got %d requests", len(received))
+    }
+    s.Get(fmt.Sprintf("/webhooks/%d/deliveries", hook.ID), &instructor).ExpectStatus(http.StatusOK).Data(&deliveries)
+    if len(deliveries) != 2 || deliveries[0].ID != redelivery.ID || deliveries[0].Status != models.DeliveryPending ||
+        deliveries[0].StatusCode != http.StatusServiceUnavailable || deliveries[0].Error == "" {
+        t.Fatalf("expected a pending failed attempt first, got %+v", deliveries)
+    }
+    var job models.Job
+    s.DB.Where("kind = ?", webhooks.Send.Name).Order("id DESC").First(&job)
+    if job.Status != models.JobPending || !job.RunAt.After(time.Now()) {
+        t.Fatalf("expected the send to be retried later, got %+v", job)
+    }
+
+    // Webhook yang dinonaktifkan tidak bisa dikirim ulang
+    s.Do(apitest.Request{
+        Method: http.MethodPut,
+        Path:   fmt.Sprintf("/webhooks/%d", hook.ID),
+        As:     &instructor,
+        JSON:   map[string]interface{}{"url": r.URL, "events": []string{"enrollment.created"}, "active": false},
+    }).ExpectStatus(http.StatusOK)
+    expectError(t, s.Do(apitest.Request{
+        Method: http.MethodPost,
+        Path:   fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", hook.ID, deliveries[1].ID),
+        As:     &instructor,
+    }), http.StatusConflict, "Webhook is disabled")
+}
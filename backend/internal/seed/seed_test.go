@@ -0,0 +1,90 @@
+package seed
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go-learn-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDB opens a fresh SQLite database with the schema of the models
+func newDB(t *testing.T) *gorm.DB {
+    t.Helper()
+    db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "seed.db")), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := models.Migrate(db); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() {
+        if sqlDB, err := db.DB(); err == nil {
+            sqlDB.Close()
+        }
+    })
+    return db
+}
+
+func TestApplyMatchesCoursesByKey(t *testing.T) {
+    db := newDB(t)
+    f, err := Load(DefaultFixture)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := Apply(db, f, nil); err != nil {
+        t.Fatal(err)
+    }
+
+    // Judul yang diganti meng-update kursus yang sama
+    f.Courses[0].Title = "Golang Dasar (Edisi Baru)"
+    report, err := Apply(db, f, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if report.Courses != 0 {
+        t.Fatalf("expected no new courses after a rename, got %d", report.Courses)
+    }
+
+    var course models.Course
+    db.Where("seed_key = ?", f.Courses[0].Key).First(&course)
+    if course.Title != f.Courses[0].Title {
+        t.Fatalf("expected the renamed course, got %q", course.Title)
+    }
+    var total int64
+    db.Model(&models.Course{}).Count(&total)
+    if total != int64(len(f.Courses)) {
+        t.Fatalf("expected %d courses, got %d", len(f.Courses), total)
+    }
+}
+
+func TestApplyAdoptsCoursesWithoutKey(t *testing.T) {
+    db := newDB(t)
+    f, err := Load(DefaultFixture)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := Apply(db, f, nil); err != nil {
+        t.Fatal(err)
+    }
+
+    // Kursus dari seed sebelum ada seed_key dicocokkan lewat judul dan pemilik
+    db.Model(&models.Course{}).Where("1 = 1").Update("seed_key", nil)
+    report, err := Apply(db, f, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if report.Courses != 0 {
+        t.Fatalf("expected the existing courses to be reused, got %d new", report.Courses)
+    }
+    var keyed int64
+    db.Model(&models.Course{}).Where("seed_key IS NOT NULL").Count(&keyed)
+    if keyed != int64(len(f.Courses)) {
+        t.Fatalf("expected every course to get its key, got %d", keyed)
+    }
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+// generationKey holds the current generation of the course list pages.
+// Changing it makes every cached page unreachable at once.
+const generationKey = "courses:generation"
+
+// Catalog caches the hot catalog reads (course list pages, course detail
+// trees and public profiles) and knows which entries a change affects.
+// A nil Catalog caches nothing, so services created outside New read
+// straight from the database.
+type Catalog struct {
+    db    *gorm.DB
+    store cache.Store
+    ttl   time.Duration
+}
+
+// NewCatalog returns a Catalog keeping entries in store for ttl
+func NewCatalog(db *gorm.DB, store cache.Store, ttl time.Duration) *Catalog {
+    return &Catalog{db: db, store: store, ttl: ttl}
+}
+
+func courseKey(id uint) string {
+    return fmt.Sprintf("course:%d", id)
+}
+
+func profileKey(userID uint) string {
+    return fmt.Sprintf("profile:%d", userID)
+}
+
+// fetch returns the cached value of key or loads it through load
+func fetch[T any](ctx context.Context, c *Catalog, key string, load func() (T, error)) (T, error) {
+    if c == nil {
+        return load()
+    }
+    return cache.Fetch(ctx, c.store, key, c.ttl, load)
+}
+
+// coursePage is a cached page of the course list
+type coursePage struct {
+    Courses []models.Course
+    Total   int64
+}
+
+// listKey returns the key of a course list page in the current generation
+func (c *Catalog) listKey(ctx context.Context, page Page) string {
+    generation, ok, err := c.store.Get(ctx, generationKey)
+    if err != nil || !ok {
+        generation = []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+        if err := c.store.Set(ctx, generationKey, generation, c.ttl); err != nil {
+            slog.WarnContext(ctx, "cache write failed", "key", generationKey, "error", err)
+        }
+    }
+    return fmt.Sprintf("courses:%s:%d:%d", generation, page.Number, page.Size)
+}
+
+// drop removes keys from the cache. A failure only leaves stale entries
+// until their TTL, so it is logged instead of failing the change.
+func (c *Catalog) drop(ctx context.Context, keys ...string) {
+    if c == nil || len(keys) == 0 {
+        return
+    }
+    if err := c.store.Delete(ctx, keys...); err != nil {
+        slog.WarnContext(ctx, "cache invalidation failed", "keys", keys, "error", err)
+    }
+}
+
+// CourseChanged drops the given courses, the list pages and the profiles
+// showing them: those of the owners and of the enrolled users
+func (c *Catalog) CourseChanged(ctx context.Context, courseIDs ...uint) {
+    if c == nil {
+        return
+    }
+    keys := []string{generationKey}
+    for _, id := range courseIDs {
+        keys = append(keys, courseKey(id))
+    }
+
+    // Termasuk kursus dan pendaftaran yang baru saja di-soft delete
+    var userIDs []uint
+    db := c.db.WithContext(ctx).Unscoped().Session(&gorm.Session{})
+    owners := db.Model(&models.Course{}).Select("user_id").Where("id IN ?", courseIDs)
+    enrolled := db.Model(&models.Enrollment{}).Select("user_id").Where("course_id IN ?", courseIDs)
+    if err := db.Model(&models.User{}).Where("id IN (?) OR id IN (?)", owners, enrolled).Pluck("id", &userIDs).Error; err != nil {
+        slog.WarnContext(ctx, "cache invalidation failed", "courses", courseIDs, "error", err)
+    }
+    for _, id := range userIDs {
+        keys = append(keys, profileKey(id))
+    }
+    c.drop(ctx, keys...)
+}
+
+// LessonChanged drops the detail trees of the given courses. Lessons, their
+// quizzes and videos only appear there.
+func (c *Catalog) LessonChanged(ctx context.Context, courseIDs ...uint) {
+    if c == nil {
+        return
+    }
+    keys := make([]string, 0, len(courseIDs))
+    for _, id := range courseIDs {
+        keys = append(keys, courseKey(id))
+    }
+    c.drop(ctx, keys...)
+}
+
+// ProfileChanged drops the profile of a user and every entry showing them as
+// instructor: the list pages and the detail trees of their courses
+func (c *Catalog) ProfileChanged(ctx context.Context, userID uint) {
+    if c == nil {
+        return
+    }
+    keys := []string{generationKey, profileKey(userID)}
+
+    var courseIDs []uint
+    if err := c.db.WithContext(ctx).Model(&models.Course{}).Where("user_id = ?", userID).Pluck("id", &courseIDs).Error; err != nil {
+        slog.WarnContext(ctx, "cache invalidation failed", "user", userID, "error", err)
+    }
+    for _, id := range courseIDs {
+        keys = append(keys, courseKey(id))
+    }
+    c.drop(ctx, keys...)
+}
+
+// EnrollmentChanged drops the profile of a user whose enrollments or course
+// progress changed
+func (c *Catalog) EnrollmentChanged(ctx context.Context, userID uint) {
+    c.drop(ctx, profileKey(userID))
+}
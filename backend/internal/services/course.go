@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CourseChanges holds the fields to update on a course; empty values are
+// left unchanged. When Version is set the update fails with ErrModified if
+// the course was updated after that time.
+type CourseChanges struct {
+    Title       string
+    Description string
+    Image       string
+    Version     time.Time
+}
+
+// LessonChanges holds the new values of a lesson; an empty Image keeps the
+// current image. Version works like CourseChanges.Version.
+type LessonChanges struct {
+    CourseID uint
+    Title    string
+    Content  string
+    Order    int
+    Image    string
+    Version  time.Time
+}
+
+// CourseService manages courses and their lessons
+type CourseService interface {
+    // List returns a page of courses and the total number of courses
+    List(ctx context.Context, page Page) ([]models.Course, int64, error)
+    Get(ctx context.Context, id uint) (models.Course, error)
+    Create(ctx context.Context, course *models.Course) error
+    Update(ctx context.Context, userID, id uint, changes CourseChanges) (models.Course, error)
+    Delete(ctx context.Context, userID, id uint) error
+    // CanAccess reports whether a user owns or is enrolled in a course
+    CanAccess(ctx context.Context, userID uint, course models.Course) (bool, error)
+
+    GetLesson(ctx context.Context, id uint) (models.Lesson, models.Course, error)
+    CreateLesson(ctx context.Context, lesson *models.Lesson) error
+    UpdateLesson(ctx context.Context, id uint, changes LessonChanges) (models.Lesson, error)
+    DeleteLesson(ctx context.Context, id uint) error
+    // IsLessonImage reports whether a file in the public folder is the image
+    // of a lesson, which is only served through signed URLs
+    IsLessonImage(ctx context.Context, path string) (bool, error)
+    // LessonChanged reports a change of a lesson made outside this service,
+    // e.g. a finished video upload, so cached course data is refreshed
+    LessonChanged(ctx context.Context, id uint) error
+}
+
+type gormCourseService struct {
+    db      *gorm.DB
+    catalog *Catalog
+}
+
+// NewCourseService returns a CourseService backed by GORM
+func NewCourseService(db *gorm.DB) CourseService {
+    return &gormCourseService{db: db}
+}
+
+func (s *gormCourseService) List(ctx context.Context, page Page) ([]models.Course, int64, error) {
+    load := func() (coursePage, error) {
+        var courses []models.Course
+        query := s.db.WithContext(ctx).
+            Model(&models.Course{}).
+            Preload("User.Profile")
+        total, err := paginate(query, page, &courses)
+        return coursePage{Courses: courses, Total: total}, err
+    }
+
+    var result coursePage
+    var err error
+    if s.catalog == nil {
+        result, err = load()
+    } else {
+        result, err = fetch(ctx, s.catalog, s.catalog.listKey(ctx, page), load)
+    }
+    return result.Courses, result.Total, err
+}
+
+func (s *gormCourseService) Get(ctx context.Context, id uint) (models.Course, error) {
+    return fetch(ctx, s.catalog, courseKey(id), func() (models.Course, error) {
+        var course models.Course
+        err := s.db.WithContext(ctx).
+            Preload("User.Profile").
+            Preload("Lessons.Quizzes").
+            Preload("Lessons.Video").
+            Preload("Lessons").
+            First(&course, id).Error
+        return course, notFound(err, "course", id)
+    })
+}
+
+func (s *gormCourseService) Create(ctx context.Context, course *models.Course) error {
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(course).Error; err != nil {
+            return err
+        }
+        // Kursus langsung tampil di katalog, jadi sekaligus dipublikasikan
+        return events.Record(ctx, tx,
+            events.CourseCreated{CourseID: course.ID, UserID: course.UserID, Title: course.Title},
+            events.CoursePublished{CourseID: course.ID, UserID: course.UserID, Title: course.Title})
+    })
+    if err != nil {
+        return err
+    }
+    s.catalog.CourseChanged(ctx, course.ID)
+    return nil
+}
+
+func (s *gormCourseService) Update(ctx context.Context, userID, id uint, changes CourseChanges) (models.Course, error) {
+    course, err := s.owned(ctx, userID, id)
+    if err != nil {
+        return course, err
+    }
+
+    if changes.Title != "" {
+        course.Title = changes.Title
+    }
+    if changes.Description != "" {
+        course.Description = changes.Description
+    }
+    if changes.Image != "" {
+        course.Image = changes.Image
+    }
+    if err := s.save(ctx, &course, course.UpdatedAt, changes.Version); err != nil {
+        return course, err
+    }
+    s.catalog.CourseChanged(ctx, course.ID)
+    return course, nil
+}
+
+func (s *gormCourseService) Delete(ctx context.Context, userID, id uint) error {
+    course, err := s.owned(ctx, userID, id)
+    if err != nil {
+        return err
+    }
+    if err := s.db.WithContext(ctx).Delete(&course).Error; err != nil {
+        return err
+    }
+    s.catalog.CourseChanged(ctx, course.ID)
+    return nil
+}
+
+// owned loads a course and checks that it belongs to the user
+func (s *gormCourseService) owned(ctx context.Context, userID, id uint) (models.Course, error) {
+    var course models.Course
+    if err := s.db.WithContext(ctx).First(&course, id).Error; err != nil {
+        return course, notFound(err, "course", id)
+    }
+    if course.UserID != userID {
+        return course, fmt.Errorf("course %d: %w", id, ErrForbidden)
+    }
+    return course, nil
+}
+
+func (s *gormCourseService) CanAccess(ctx context.Context, userID uint, course models.Course) (bool, error) {
+    if course.UserID == userID {
+        return true, nil
+    }
+
+    var count int64
+    if err := s.db.WithContext(ctx).Model(&models.Enrollment{}).
+        Where("user_id = ? AND course_id = ?", userID, course.ID).
+        Count(&count).Error; err != nil {
+        return false, err
+    }
+    return count > 0, nil
+}
+
+func (s *gormCourseService) GetLesson(ctx context.Context, id uint) (models.Lesson, models.Course, error) {
+    var lesson models.Lesson
+    var course models.Course
+    db := s.db.WithContext(ctx)
+    if err := db.Preload("Quizzes").Preload("Video").First(&lesson, id).Error; err != nil {
+        return lesson, course, notFound(err, "lesson", id)
+    }
+    if err := db.First(&course, lesson.CourseID).Error; err != nil {
+        return lesson, course, notFound(err, "course", lesson.CourseID)
+    }
+    return lesson, course, nil
+}
+
+func (s *gormCourseService) CreateLesson(ctx context.Context, lesson *models.Lesson) error {
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(lesson).Error; err != nil {
+            return err
+        }
+        return events.Record(ctx, tx, events.LessonAdded{LessonID: lesson.ID, CourseID: lesson.CourseID, Title: lesson.Title})
+    })
+    if err != nil {
+        return err
+    }
+    s.catalog.LessonChanged(ctx, lesson.CourseID)
+    return nil
+}
+
+func (s *gormCourseService) UpdateLesson(ctx context.Context, id uint, changes LessonChanges) (models.Lesson, error) {
+    var lesson models.Lesson
+    if err := s.db.WithContext(ctx).First(&lesson, id).Error; err != nil {
+        return lesson, notFound(err, "lesson", id)
+    }
+
+    // Lesson bisa pindah kursus, jadi kedua kursus perlu di-refresh
+    previousCourse := lesson.CourseID
+    lesson.Title = changes.Title
+    lesson.Content = changes.Content
+    lesson.Order = changes.Order
+    lesson.CourseID = changes.CourseID
+    if changes.Image != "" {
+        lesson.Image = changes.Image // kalau ada file baru, update image
+    }
+    if err := s.save(ctx, &lesson, lesson.UpdatedAt, changes.Version); err != nil {
+        return lesson, err
+    }
+    s.catalog.LessonChanged(ctx, previousCourse, lesson.CourseID)
+    return lesson, nil
+}
+
+// save stores a loaded record. With a version it only updates the row while
+// updated_at still equals the version, so concurrent updates are detected.
+func (s *gormCourseService) save(ctx context.Context, record interface{}, current, version time.Time) error {
+    if version.IsZero() {
+        return s.db.WithContext(ctx).Save(record).Error
+    }
+    if !current.Equal(version) {
+        return ErrModified
+    }
+
+    result := s.db.WithContext(ctx).Model(record).Where("updated_at = ?", version).Select("*").Omit("created_at").Updates(record)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrModified
+    }
+    return nil
+}
+
+func (s *gormCourseService) DeleteLesson(ctx context.Context, id uint) error {
+    var lesson models.Lesson
+    if err := s.db.WithContext(ctx).First(&lesson, id).Error; err != nil {
+        return notFound(err, "lesson", id)
+    }
+    if err := s.db.WithContext(ctx).Delete(&lesson).Error; err != nil {
+        return err
+    }
+    s.catalog.LessonChanged(ctx, lesson.CourseID)
+    return nil
+}
+
+func (s *gormCourseService) LessonChanged(ctx context.Context, id uint) error {
+    var lesson models.Lesson
+    if err := s.db.WithContext(ctx).First(&lesson, id).Error; err != nil {
+        return notFound(err, "lesson", id)
+    }
+    s.catalog.LessonChanged(ctx, lesson.CourseID)
+    return nil
+}
+
+func (s *gormCourseService) IsLessonImage(ctx context.Context, path string) (bool, error) {
+    var count int64
+    err := s.db.WithContext(ctx).Model(&models.Lesson{}).Where("image = ?", path).Count(&count).Error
+    return count > 0, err
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/metrics"
+
+	"gorm.io/gorm"
+)
+
+// EnrollmentService manages the enrollments of users in courses
+type EnrollmentService interface {
+    Enroll(ctx context.Context, userID, courseID uint) (models.Enrollment, error)
+    ListByUser(ctx context.Context, userID uint, page Page) ([]models.Enrollment, int64, error)
+    Cancel(ctx context.Context, userID, enrollmentID uint) error
+}
+
+type gormEnrollmentService struct {
+    db      *gorm.DB
+    catalog *Catalog
+}
+
+// NewEnrollmentService returns an EnrollmentService backed by GORM
+func NewEnrollmentService(db *gorm.DB) EnrollmentService {
+    return &gormEnrollmentService{db: db}
+}
+
+func (s *gormEnrollmentService) Enroll(ctx context.Context, userID, courseID uint) (models.Enrollment, error) {
+    db := s.db.WithContext(ctx)
+
+    // Cek apakah kursus ada
+    var course models.Course
+    if err := db.First(&course, courseID).Error; err != nil {
+        return models.Enrollment{}, notFound(err, "course", courseID)
+    }
+
+    // Cek apakah pengguna sudah terdaftar di kursus
+    var count int64
+    if err := db.Model(&models.Enrollment{}).
+        Where("user_id = ? AND course_id = ?", userID, courseID).
+        Count(&count).Error; err != nil {
+        return models.Enrollment{}, err
+    }
+    if count > 0 {
+        return models.Enrollment{}, ErrAlreadyEnrolled
+    }
+
+    enrollment := models.Enrollment{
+        UserID:   userID,
+        CourseID: courseID,
+    }
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&enrollment).Error; err != nil {
+            return err
+        }
+        return events.Record(ctx, tx, events.Enrolled{EnrollmentID: enrollment.ID, UserID: userID, CourseID: courseID})
+    })
+    // Enroll bersamaan bisa lolos dari cek di atas, unique index yang menolaknya
+    if errors.Is(err, gorm.ErrDuplicatedKey) {
+        return models.Enrollment{}, ErrAlreadyEnrolled
+    }
+    if err != nil {
+        return enrollment, err
+    }
+    metrics.EnrollmentCreated()
+    s.catalog.EnrollmentChanged(ctx, userID)
+    return enrollment, nil
+}
+
+func (s *gormEnrollmentService) ListByUser(ctx context.Context, userID uint, page Page) ([]models.Enrollment, int64, error) {
+    var enrollments []models.Enrollment
+    query := s.db.WithContext(ctx).Model(&models.Enrollment{}).Preload("Course").Where("user_id = ?", userID)
+    total, err := paginate(query, page, &enrollments)
+    return enrollments, total, err
+}
+
+func (s *gormEnrollmentService) Cancel(ctx context.Context, userID, enrollmentID uint) error {
+    db := s.db.WithContext(ctx)
+
+    var enrollment models.Enrollment
+    if err := db.First(&enrollment, enrollmentID).Error; err != nil {
+        return notFound(err, "enrollment", enrollmentID)
+    }
+
+    // Verifikasi bahwa pengguna adalah pemilik pendaftaran
+    if enrollment.UserID != userID {
+        return fmt.Errorf("enrollment %d: %w", enrollmentID, ErrForbidden)
+    }
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Delete(&enrollment).Error; err != nil {
+            return err
+        }
+        return events.Record(ctx, tx, events.EnrollmentCancelled{EnrollmentID: enrollment.ID, UserID: userID, CourseID: enrollment.CourseID})
+    })
+    if err != nil {
+        return err
+    }
+    s.catalog.EnrollmentChanged(ctx, userID)
+    return nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// JobFilter narrows a job list; empty fields match every job
+type JobFilter struct {
+    Status string
+    Kind   string
+}
+
+// JobService lets admins inspect the background job queue and retry jobs
+type JobService interface {
+    // List returns a page of jobs, newest first
+    List(ctx context.Context, filter JobFilter, page Page) ([]models.Job, int64, error)
+    Get(ctx context.Context, id uint) (models.Job, error)
+    // Retry runs a dead or waiting job again as soon as possible with a
+    // fresh set of attempts
+    Retry(ctx context.Context, id uint) (models.Job, error)
+}
+
+type gormJobService struct {
+    db *gorm.DB
+}
+
+// NewJobService returns a JobService backed by GORM
+func NewJobService(db *gorm.DB) JobService {
+    return &gormJobService{db: db}
+}
+
+func (s *gormJobService) List(ctx context.Context, filter JobFilter, page Page) ([]models.Job, int64, error) {
+    query := s.db.WithContext(ctx).Model(&models.Job{})
+    if filter.Status != "" {
+        query = query.Where("status = ?", filter.Status)
+    }
+    if filter.Kind != "" {
+        query = query.Where("kind = ?", filter.Kind)
+    }
+
+    var total int64
+    if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    var jobs []models.Job
+    err := query.Order("id DESC").Offset(page.Offset()).Limit(page.Size).Find(&jobs).Error
+    return jobs, total, err
+}
+
+func (s *gormJobService) Get(ctx context.Context, id uint) (models.Job, error) {
+    var job models.Job
+    err := s.db.WithContext(ctx).First(&job, id).Error
+    return job, notFound(err, "job", id)
+}
+
+func (s *gormJobService) Retry(ctx context.Context, id uint) (models.Job, error) {
+    job, err := s.Get(ctx, id)
+    if err != nil {
+        return job, err
+    }
+    if job.Status != models.JobDead && job.Status != models.JobPending {
+        return job, fmt.Errorf("job %d is %s: %w", id, job.Status, ErrNotRetryable)
+    }
+
+    // Hanya diubah kalau status belum berubah sejak dibaca
+    result := s.db.WithContext(ctx).Model(&job).
+        Where("status = ?", job.Status).
+        Updates(map[string]interface{}{
+            "status":      models.JobPending,
+            "attempts":    0,
+            "run_at":      time.Now(),
+            "finished_at": nil,
+        })
+    if result.Error != nil {
+        return job, result.Error
+    }
+    if result.RowsAffected == 0 {
+        return job, fmt.Errorf("job %d: %w", id, ErrNotRetryable)
+    }
+    return s.Get(ctx, id)
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LessonAddedPayload is the payload of a lesson.added notification
+type LessonAddedPayload struct {
+    CourseID    uint   `json:"course_id"`
+    CourseTitle string `json:"course_title"`
+    LessonID    uint   `json:"lesson_id"`
+    LessonTitle string `json:"lesson_title"`
+}
+
+// QuizGradedPayload is the payload of a quiz.graded notification
+type QuizGradedPayload struct {
+    ResultID    uint   `json:"result_id"`
+    QuizID      uint   `json:"quiz_id"`
+    LessonID    uint   `json:"lesson_id"`
+    CourseID    uint   `json:"course_id"`
+    CourseTitle string `json:"course_title"`
+    Score       int    `json:"score"`
+}
+
+// NotificationService manages the in-app inbox of every user and streams
+// new notifications while the user is connected
+type NotificationService interface {
+    // List returns a page of notifications, newest first
+    List(ctx context.Context, userID uint, unreadOnly bool, page Page) ([]models.Notification, int64, error)
+    // Since returns up to limit notifications created after the given ID,
+    // oldest first, for clients resuming a stream
+    Since(ctx context.Context, userID, afterID uint, limit int) ([]models.Notification, error)
+    MarkRead(ctx context.Context, userID, id uint) (models.Notification, error)
+    // MarkAllRead marks every unread notification as read and returns how
+    // many there were
+    MarkAllRead(ctx context.Context, userID uint) (int64, error)
+
+    // Stream returns the notifications of a user created from now on, until
+    // the returned function is called or the service is closed
+    Stream(userID uint) (<-chan models.Notification, func())
+    // Close ends all streams, e.g. when the server shuts down
+    Close()
+}
+
+type gormNotificationService struct {
+    db  *gorm.DB
+    hub *hub
+}
+
+// NewNotificationService returns a NotificationService backed by GORM. Open
+// streams poll for new notifications every interval.
+func NewNotificationService(db *gorm.DB, interval time.Duration) NotificationService {
+    return &gormNotificationService{db: db, hub: newHub(db, interval)}
+}
+
+func (s *gormNotificationService) List(ctx context.Context, userID uint, unreadOnly bool, page Page) ([]models.Notification, int64, error) {
+    query := s.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ?", userID)
+    if unreadOnly {
+        query = query.Where("read_at IS NULL")
+    }
+    var total int64
+    if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    var notifications []models.Notification
+    err := query.Order("id DESC").Offset(page.Offset()).Limit(page.Size).Find(&notifications).Error
+    return notifications, total, err
+}
+
+func (s *gormNotificationService) Since(ctx context.Context, userID, afterID uint, limit int) ([]models.Notification, error) {
+    var notifications []models.Notification
+    err := s.db.WithContext(ctx).
+        Where("user_id = ? AND id > ?", userID, afterID).
+        Order("id").
+        Limit(limit).
+        Find(&notifications).Error
+    return notifications, err
+}
+
+func (s *gormNotificationService) MarkRead(ctx context.Context, userID, id uint) (models.Notification, error) {
+    var notification models.Notification
+    db := s.db.WithContext(ctx)
+    if err := db.Where("user_id = ?", userID).First(&notification, id).Error; err != nil {
+        return notification, notFound(err, "notification", id)
+    }
+    if notification.ReadAt != nil {
+        return notification, nil
+    }
+    now := time.Now()
+    notification.ReadAt = &now
+    err := db.Model(&notification).Update("read_at", now).Error
+    return notification, err
+}
+
+func (s *gormNotificationService) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+    result := s.db.WithContext(ctx).Model(&models.Notification{}).
+        Where("user_id = ? AND read_at IS NULL", userID).
+        Update("read_at", time.Now())
+    return result.RowsAffected, result.Error
+}
+
+func (s *gormNotificationService) Stream(userID uint) (<-chan models.Notification, func()) {
+    return s.hub.subscribe(userID)
+}
+
+func (s *gormNotificationService) Close() {
+    s.hub.close()
+}
+
+// lessonAdded notifies the students of a course about a new lesson
+func (s *gormNotificationService) lessonAdded(ctx context.Context, event events.LessonAdded) error {
+    db := s.db.WithContext(ctx)
+    var course models.Course
+    if err := db.First(&course, event.CourseID).Error; err != nil {
+        return ignoreNotFound(err)
+    }
+    var userIDs []uint
+    if err := db.Model(&models.Enrollment{}).Where("course_id = ?", event.CourseID).
+        Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+        return err
+    }
+
+    payload := LessonAddedPayload{CourseID: course.ID, CourseTitle: course.Title, LessonID: event.LessonID, LessonTitle: event.Title}
+    return s.notify(ctx, userIDs, models.NotificationLessonAdded, fmt.Sprintf("lesson:%d", event.LessonID), payload)
+}
+
+// quizGraded notifies a student about the score of a completed quiz. Quizzes
+// are graded when they are submitted.
+func (s *gormNotificationService) quizGraded(ctx context.Context, event events.QuizSubmitted) error {
+    var course models.Course
+    if err := s.db.WithContext(ctx).First(&course, event.CourseID).Error; err != nil {
+        return ignoreNotFound(err)
+    }
+    payload := QuizGradedPayload{
+        ResultID:    event.ResultID,
+        QuizID:      event.QuizID,
+        LessonID:    event.LessonID,
+        CourseID:    course.ID,
+        CourseTitle: course.Title,
+        Score:       event.Score,
+    }
+    return s.notify(ctx, []uint{event.UserID}, models.NotificationQuizGraded, fmt.Sprintf("quiz:%d", event.ResultID), payload)
+}
+
+// notify stores a notification for every user. Users who already have one
+// with the same key are skipped, so a retried subscriber notifies once.
+func (s *gormNotificationService) notify(ctx context.Context, userIDs []uint, kind, key string, payload interface{}) error {
+    if len(userIDs) == 0 {
+        return nil
+    }
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+    rows := make([]models.Notification, 0, len(userIDs))
+    for _, userID := range userIDs {
+        rows = append(rows, models.Notification{UserID: userID, DedupeKey: key, Type: kind, Payload: string(data)})
+    }
+    return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, hubBatch).Error
+}
+
+// ignoreNotFound drops gorm.ErrRecordNotFound, for events about records that
+// were deleted in the meantime
+func ignoreNotFound(err error) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil
+    }
+    return err
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// hubBatch is the number of notifications read per poll query
+const hubBatch = 500
+
+// hubLookback is how far back each poll reads. IDs are taken at insert time,
+// so a notification may commit after a newer one; reading a window instead
+// of everything above the last ID also picks up these late commits.
+const hubLookback = time.Minute
+
+// hub hands new notifications to the streams of this process. While any
+// stream is open, one poller reads the notifications created since its
+// last poll, by this or any other process, and passes them to the streams
+// of their user. Notifications already passed on are remembered for the
+// lookback window, so every stream gets each notification once.
+type hub struct {
+    db       *gorm.DB
+    interval time.Duration
+
+    mu      sync.Mutex
+    streams map[uint]map[chan models.Notification]struct{} // User -> stream
+    seen    map[uint]time.Time // ID -> CreatedAt, selama masih dalam jendela
+    stop    context.CancelFunc
+    closed  bool
+}
+
+func newHub(db *gorm.DB, interval time.Duration) *hub {
+    return &hub{db: db, interval: interval, streams: map[uint]map[chan models.Notification]struct{}{}}
+}
+
+// subscribe opens a stream of the notifications of a user created from now
+// on. The channel is closed by the returned function or by close.
+func (h *hub) subscribe(userID uint) (<-chan models.Notification, func()) {
+    ch := make(chan models.Notification, 16)
+
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.closed {
+        close(ch)
+        return ch, func() {}
+    }
+    if h.stop == nil {
+        h.start()
+    }
+    if h.streams[userID] == nil {
+        h.streams[userID] = map[chan models.Notification]struct{}{}
+    }
+    h.streams[userID][ch] = struct{}{}
+
+    var once sync.Once
+    return ch, func() {
+        once.Do(func() { h.unsubscribe(userID, ch) })
+    }
+}
+
+// start begins polling. Notifications that already exist are marked as
+// seen so streams only get new ones. h.mu must be held.
+func (h *hub) start() {
+    var existing []models.Notification
+    err := h.db.Select("id", "created_at").
+        Where("created_at >= ?", time.Now().Add(-hubLookback)).
+        Find(&existing).Error
+    if err != nil {
+        slog.Error("Failed to read the recent notifications", "error", err)
+    }
+    h.seen = make(map[uint]time.Time, len(existing))
+    for _, notification := range existing {
+        h.seen[notification.ID] = notification.CreatedAt
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    h.stop = cancel
+    go h.poll(ctx)
+}
+
+func (h *hub) unsubscribe(userID uint, ch chan models.Notification) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if _, ok := h.streams[userID][ch]; !ok {
+        return // Sudah ditutup oleh close
+    }
+    delete(h.streams[userID], ch)
+    if len(h.streams[userID]) == 0 {
+        delete(h.streams, userID)
+    }
+    close(ch)
+
+    // Tanpa stream tidak perlu polling
+    if len(h.streams) == 0 && h.stop != nil {
+        h.stop()
+        h.stop = nil
+    }
+}
+
+// close ends every stream, e.g. when the server shuts down, and refuses new
+// ones
+func (h *hub) close() {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.closed = true
+    if h.stop != nil {
+        h.stop()
+        h.stop = nil
+    }
+    for userID, streams := range h.streams {
+        for ch := range streams {
+            close(ch)
+        }
+        delete(h.streams, userID)
+    }
+}
+
+func (h *hub) poll(ctx context.Context) {
+    ticker := time.NewTicker(h.interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            if err := h.publish(ctx); err != nil && ctx.Err() == nil {
+                slog.Error("Failed to poll notifications", "error", err)
+            }
+        }
+    }
+}
+
+// publish passes the notifications created within the lookback window
+// that were not passed on yet to their streams. A stream that does not keep
+// up loses notifications; clients catch up with the list endpoint or
+// Last-Event-ID.
+func (h *hub) publish(ctx context.Context) error {
+    since := time.Now().Add(-hubLookback)
+    var after uint
+    for {
+        var batch []models.Notification
+        err := h.db.WithContext(ctx).
+            Where("created_at >= ? AND id > ?", since, after).
+            Order("id").
+            Limit(hubBatch).
+            Find(&batch).Error
+        if err != nil {
+            return err
+        }
+        if len(batch) == 0 {
+            break
+        }
+
+        h.mu.Lock()
+        // close bisa dipanggil selama query berjalan
+        if ctx.Err() != nil {
+            h.mu.Unlock()
+            return nil
+        }
+        for _, notification := range batch {
+            if _, ok := h.seen[notification.ID]; ok {
+                continue
+            }
+            h.seen[notification.ID] = notification.CreatedAt
+            for ch := range h.streams[notification.UserID] {
+                select {
+                case ch <- notification:
+                default:
+                }
+            }
+        }
+        h.mu.Unlock()
+
+        after = batch[len(batch)-1].ID
+        if len(batch) < hubBatch {
+            break
+        }
+    }
+
+    // Notifikasi di luar jendela tidak akan dibaca lagi
+    h.mu.Lock()
+    for id, createdAt := range h.seen {
+        if createdAt.Before(since) {
+            delete(h.seen, id)
+        }
+    }
+    h.mu.Unlock()
+    return nil
+}
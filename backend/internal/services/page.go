@@ -0,0 +1,25 @@
+package services
+
+import "gorm.io/gorm"
+
+// Page selects a slice of a list; Number starts at 1
+type Page struct {
+    Number int
+    Size   int
+}
+
+// Offset returns the number of rows to skip
+func (p Page) Offset() int {
+    return (p.Number - 1) * p.Size
+}
+
+// paginate counts all rows matched by query and loads the requested page
+// into dest. The query must already carry the model and conditions.
+func paginate(query *gorm.DB, page Page, dest interface{}) (int64, error) {
+    var total int64
+    if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+        return 0, err
+    }
+    err := query.Order("id").Offset(page.Offset()).Limit(page.Size).Find(dest).Error
+    return total, err
+}
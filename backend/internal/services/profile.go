@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ProfileChanges holds the new values of a profile; an empty Image keeps the
+// current image
+type ProfileChanges struct {
+    Name  string
+    Image string
+}
+
+// NotificationChanges holds new email preferences; nil leaves one as it is
+type NotificationChanges struct {
+    Enrollments *bool
+    NewLessons  *bool
+    QuizResults *bool
+}
+
+// ProfileService manages user profiles. Users are returned with their
+// profile, created courses and enrollments preloaded.
+type ProfileService interface {
+    Get(ctx context.Context, userID uint) (models.User, error)
+    // Public returns the same data as Get, served from the cache when
+    // possible; use it for the public profile page
+    Public(ctx context.Context, userID uint) (models.User, error)
+    // Update changes the profile of a user, creating it when missing
+    Update(ctx context.Context, userID uint, changes ProfileChanges) (models.User, error)
+
+    // Notifications returns the profile holding the email preferences of a
+    // user; users without a profile get the defaults
+    Notifications(ctx context.Context, userID uint) (models.Profile, error)
+    // UpdateNotifications changes the email preferences, creating the
+    // profile when missing
+    UpdateNotifications(ctx context.Context, userID uint, changes NotificationChanges) (models.Profile, error)
+}
+
+type gormProfileService struct {
+    db      *gorm.DB
+    catalog *Catalog
+}
+
+// NewProfileService returns a ProfileService backed by GORM
+func NewProfileService(db *gorm.DB) ProfileService {
+    return &gormProfileService{db: db}
+}
+
+func (s *gormProfileService) Get(ctx context.Context, userID uint) (models.User, error) {
+    var user models.User
+    err := s.db.WithContext(ctx).
+        Preload("Profile").
+        Preload("Courses").
+        Preload("Enrollments.Course").
+        First(&user, userID).Error
+    return user, notFound(err, "user", userID)
+}
+
+func (s *gormProfileService) Public(ctx context.Context, userID uint) (models.User, error) {
+    return fetch(ctx, s.catalog, profileKey(userID), func() (models.User, error) {
+        return s.Get(ctx, userID)
+    })
+}
+
+func (s *gormProfileService) Update(ctx context.Context, userID uint, changes ProfileChanges) (models.User, error) {
+    user, err := s.Get(ctx, userID)
+    if err != nil {
+        return user, err
+    }
+
+    // Jika profil belum ada, buat baru
+    db := s.db.WithContext(ctx)
+    if user.Profile.UserID == 0 {
+        user.Profile = models.Profile{
+            UserID: user.ID,
+            Name:   changes.Name,
+            Image:  changes.Image,
+        }
+        err = db.Create(&user.Profile).Error
+    } else {
+        user.Profile.Name = changes.Name
+        if changes.Image != "" {
+            user.Profile.Image = changes.Image
+        }
+        err = db.Save(&user.Profile).Error
+    }
+    if err != nil {
+        return user, err
+    }
+
+    s.catalog.ProfileChanged(ctx, user.ID)
+    return user, nil
+}
+
+func (s *gormProfileService) Notifications(ctx context.Context, userID uint) (models.Profile, error) {
+    user, err := s.Get(ctx, userID)
+    if err != nil {
+        return models.Profile{}, err
+    }
+    if user.Profile.ID == 0 {
+        return defaultProfile(user.ID), nil
+    }
+    return user.Profile, nil
+}
+
+func (s *gormProfileService) UpdateNotifications(ctx context.Context, userID uint, changes NotificationChanges) (models.Profile, error) {
+    profile, err := s.Notifications(ctx, userID)
+    if err != nil {
+        return profile, err
+    }
+
+    for _, change := range []struct {
+        value *bool
+        field *bool
+    }{
+        {changes.Enrollments, &profile.EmailEnrollments},
+        {changes.NewLessons, &profile.EmailNewLessons},
+        {changes.QuizResults, &profile.EmailQuizResults},
+    } {
+        if change.value != nil {
+            *change.field = *change.value
+        }
+    }
+
+    // Select agar nilai false tidak diganti default kolom
+    columns := []string{"email_enrollments", "email_new_lessons", "email_quiz_results"}
+    db := s.db.WithContext(ctx)
+    if profile.ID == 0 {
+        err = db.Select(append(columns, "user_id", "created_at", "updated_at")).Create(&profile).Error
+    } else {
+        err = db.Model(&profile).Select(columns).Updates(&profile).Error
+    }
+    return profile, err
+}
+
+// defaultProfile is the profile of a user who never saved one
+func defaultProfile(userID uint) models.Profile {
+    return models.Profile{UserID: userID, EmailEnrollments: true, EmailNewLessons: true, EmailQuizResults: true}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+    heartbeatMaxGap  = 30.0 // Detik maksimum yang dihitung per heartbeat
+    watchedThreshold = 0.9  // Lesson selesai setelah 90% video ditonton
+)
+
+// Heartbeat is the playback state of a lesson video after a heartbeat
+type Heartbeat struct {
+    LessonID       uint
+    Position       float64
+    WatchedSeconds float64
+    Duration       float64
+    Completed      bool
+}
+
+// ProgressService tracks how far users got through their courses
+type ProgressService interface {
+    // CourseProgress returns the completion percentage of an enrolled user
+    CourseProgress(ctx context.Context, userID, courseID uint) (float64, error)
+    // Recalculate stores the current completion percentage on the enrollment
+    // and records CourseCompleted when it reaches 100%
+    Recalculate(ctx context.Context, userID, courseID uint) error
+    // RecordHeartbeat records the playback position of a lesson video
+    RecordHeartbeat(ctx context.Context, userID, lessonID uint, position float64) (Heartbeat, error)
+}
+
+type gormProgressService struct {
+    db      *gorm.DB
+    catalog *Catalog
+}
+
+// NewProgressService returns a ProgressService backed by GORM
+func NewProgressService(db *gorm.DB) ProgressService {
+    return &gormProgressService{db: db}
+}
+
+func (s *gormProgressService) CourseProgress(ctx context.Context, userID, courseID uint) (float64, error) {
+    // Cek apakah pengguna terdaftar di kursus
+    var count int64
+    if err := s.db.WithContext(ctx).Model(&models.Enrollment{}).
+        Where("user_id = ? AND course_id = ?", userID, courseID).
+        Count(&count).Error; err != nil {
+        return 0, err
+    }
+    if count == 0 {
+        return 0, ErrNotEnrolled
+    }
+
+    return s.progress(ctx, userID, courseID)
+}
+
+func (s *gormProgressService) Recalculate(ctx context.Context, userID, courseID uint) error {
+    progress, err := s.progress(ctx, userID, courseID)
+    if err != nil {
+        return err
+    }
+
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        // Perbarui progress di tabel Enrollment
+        query := tx.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", userID, courseID)
+        if progress < 100 {
+            return query.Update("progress", progress).Error
+        }
+
+        // Hanya update yang menaikkan progress ke 100 yang mencatat event,
+        // sehingga perhitungan bersamaan tidak mencatatnya dua kali
+        result := query.Where("progress < ?", 100).Update("progress", progress)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 1 {
+            return events.Record(ctx, tx, events.CourseCompleted{UserID: userID, CourseID: courseID})
+        }
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+    // Progress tampil di profil publik
+    s.catalog.EnrollmentChanged(ctx, userID)
+    return nil
+}
+
+// progress computes the percentage of completed lessons in a course
+func (s *gormProgressService) progress(ctx context.Context, userID, courseID uint) (float64, error) {
+    db := s.db.WithContext(ctx)
+
+    // Hitung total lesson dalam kursus
+    var totalLessons int64
+    if err := db.Model(&models.Lesson{}).Where("course_id = ?", courseID).Count(&totalLessons).Error; err != nil {
+        return 0, err
+    }
+    if totalLessons == 0 {
+        return 0, nil
+    }
+
+    // Lesson selesai jika salah satu quiz-nya dijawab atau videonya ditonton
+    answeredLessons := db.Table("quizzes").
+        Select("quizzes.lesson_id").
+        Joins("JOIN quiz_results ON quiz_results.quiz_id = quizzes.id AND quiz_results.deleted_at IS NULL").
+        Where("quiz_results.user_id = ? AND quizzes.deleted_at IS NULL", userID)
+
+    watchedLessons := db.Model(&models.LessonProgress{}).
+        Select("lesson_id").
+        Where("user_id = ? AND completed_at IS NOT NULL", userID)
+
+    var completedLessons int64
+    if err := db.Model(&models.Lesson{}).
+        Where("course_id = ?", courseID).
+        Where(db.Where("id IN (?)", answeredLessons).Or("id IN (?)", watchedLessons)).
+        Count(&completedLessons).Error; err != nil {
+        return 0, err
+    }
+
+    return (float64(completedLessons) / float64(totalLessons)) * 100, nil
+}
+
+// lessonDone reports whether a user already completed a lesson, by answering
+// one of its quizzes or by watching its video
+func lessonDone(db *gorm.DB, userID, lessonID uint) (bool, error) {
+    var answered int64
+    if err := db.Table("quiz_results").
+        Joins("JOIN quizzes ON quizzes.id = quiz_results.quiz_id AND quizzes.deleted_at IS NULL").
+        Where("quiz_results.user_id = ? AND quizzes.lesson_id = ? AND quiz_results.deleted_at IS NULL", userID, lessonID).
+        Count(&answered).Error; err != nil {
+        return false, err
+    }
+    if answered > 0 {
+        return true, nil
+    }
+
+    var watched int64
+    err := db.Model(&models.LessonProgress{}).
+        Where("user_id = ? AND lesson_id = ? AND completed_at IS NOT NULL", userID, lessonID).
+        Count(&watched).Error
+    return watched > 0, err
+}
+
+func (s *gormProgressService) RecordHeartbeat(ctx context.Context, userID, lessonID uint, position float64) (Heartbeat, error) {
+    db := s.db.WithContext(ctx)
+
+    var lesson models.Lesson
+    if err := db.Preload("Video").First(&lesson, lessonID).Error; err != nil {
+        return Heartbeat{}, notFound(err, "lesson", lessonID)
+    }
+    if lesson.Video == nil {
+        return Heartbeat{}, fmt.Errorf("video of lesson %d: %w", lessonID, ErrNotFound)
+    }
+
+    // Heartbeat hanya dihitung untuk peserta yang terdaftar
+    var count int64
+    if err := db.Model(&models.Enrollment{}).
+        Where("user_id = ? AND course_id = ?", userID, lesson.CourseID).
+        Count(&count).Error; err != nil {
+        return Heartbeat{}, err
+    }
+    if count == 0 {
+        return Heartbeat{}, ErrNotEnrolled
+    }
+
+    duration := lesson.Video.DurationSeconds
+    if duration > 0 && position > duration {
+        position = duration
+    }
+
+    var progress models.LessonProgress
+    if err := db.Where(models.LessonProgress{UserID: userID, LessonID: lesson.ID}).
+        FirstOrCreate(&progress).Error; err != nil {
+        return Heartbeat{}, fmt.Errorf("failed to load progress: %w", err)
+    }
+
+    now := time.Now()
+    if progress.LastHeartbeat != nil {
+        // Hanya hitung maju yang wajar: tidak melompat dan tidak lebih cepat dari waktu nyata
+        delta := position - progress.LastPosition
+        elapsed := now.Sub(*progress.LastHeartbeat).Seconds() + 1
+        if delta > 0 && delta <= heartbeatMaxGap && delta <= elapsed {
+            progress.WatchedSeconds += delta
+        }
+    }
+    if duration > 0 && progress.WatchedSeconds > duration {
+        progress.WatchedSeconds = duration
+    }
+    progress.LastPosition = position
+    progress.LastHeartbeat = &now
+
+    justCompleted := false
+    if progress.CompletedAt == nil && duration > 0 && progress.WatchedSeconds >= duration*watchedThreshold {
+        // Lesson yang sudah selesai lewat quiz tidak dilaporkan lagi
+        done, err := lessonDone(db, userID, lesson.ID)
+        if err != nil {
+            return Heartbeat{}, err
+        }
+        progress.CompletedAt = &now
+        justCompleted = !done
+    }
+
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Save(&progress).Error; err != nil {
+            return err
+        }
+        if !justCompleted {
+            return nil
+        }
+        return events.Record(ctx, tx, events.LessonCompleted{UserID: userID, LessonID: lesson.ID, CourseID: lesson.CourseID})
+    })
+    if err != nil {
+        return Heartbeat{}, fmt.Errorf("failed to save progress: %w", err)
+    }
+
+    return Heartbeat{
+        LessonID:       lesson.ID,
+        Position:       progress.LastPosition,
+        WatchedSeconds: progress.WatchedSeconds,
+        Duration:       duration,
+        Completed:      progress.CompletedAt != nil,
+    }, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/metrics"
+
+	"gorm.io/gorm"
+)
+
+// QuizService manages quizzes and the results users get on them
+type QuizService interface {
+    List(ctx context.Context, page Page) ([]models.Quiz, int64, error)
+    Create(ctx context.Context, quiz *models.Quiz) error
+    Delete(ctx context.Context, id uint) error
+    // Complete stores the score of an enrolled user. The course progress
+    // follows from the recorded events.
+    Complete(ctx context.Context, userID, quizID uint, score int) (models.QuizResult, error)
+
+    ListResults(ctx context.Context, page Page) ([]models.QuizResult, int64, error)
+    DeleteResult(ctx context.Context, userID, resultID uint) error
+}
+
+type gormQuizService struct {
+    db      *gorm.DB
+    catalog *Catalog
+}
+
+// NewQuizService returns a QuizService backed by GORM
+func NewQuizService(db *gorm.DB) QuizService {
+    return &gormQuizService{db: db}
+}
+
+func (s *gormQuizService) List(ctx context.Context, page Page) ([]models.Quiz, int64, error) {
+    var quizzes []models.Quiz
+    total, err := paginate(s.db.WithContext(ctx).Model(&models.Quiz{}), page, &quizzes)
+    return quizzes, total, err
+}
+
+func (s *gormQuizService) Create(ctx context.Context, quiz *models.Quiz) error {
+    // Cek apakah lesson ada
+    var lesson models.Lesson
+    if err := s.db.WithContext(ctx).First(&lesson, quiz.LessonID).Error; err != nil {
+        return notFound(err, "lesson", quiz.LessonID)
+    }
+    if err := s.db.WithContext(ctx).Create(quiz).Error; err != nil {
+        return err
+    }
+    s.catalog.LessonChanged(ctx, lesson.CourseID)
+    return nil
+}
+
+func (s *gormQuizService) Delete(ctx context.Context, id uint) error {
+    var quiz models.Quiz
+    if err := s.db.WithContext(ctx).Preload("Lesson").First(&quiz, id).Error; err != nil {
+        return notFound(err, "quiz", id)
+    }
+    if err := s.db.WithContext(ctx).Delete(&quiz).Error; err != nil {
+        return err
+    }
+    s.catalog.LessonChanged(ctx, quiz.Lesson.CourseID)
+    return nil
+}
+
+func (s *gormQuizService) Complete(ctx context.Context, userID, quizID uint, score int) (models.QuizResult, error) {
+    db := s.db.WithContext(ctx)
+
+    // Cek apakah quiz ada dan muat data Lesson terkait
+    var quiz models.Quiz
+    if err := db.Preload("Lesson").First(&quiz, quizID).Error; err != nil {
+        return models.QuizResult{}, notFound(err, "quiz", quizID)
+    }
+
+    // Hanya peserta kursus yang bisa menyelesaikan quiz
+    var enrolled int64
+    if err := db.Model(&models.Enrollment{}).
+        Where("user_id = ? AND course_id = ?", userID, quiz.Lesson.CourseID).
+        Count(&enrolled).Error; err != nil {
+        return models.QuizResult{}, err
+    }
+    if enrolled == 0 {
+        return models.QuizResult{}, ErrNotEnrolled
+    }
+
+    // Cek apakah pengguna sudah menyelesaikan quiz ini
+    var count int64
+    if err := db.Model(&models.QuizResult{}).
+        Where("user_id = ? AND quiz_id = ?", userID, quizID).
+        Count(&count).Error; err != nil {
+        return models.QuizResult{}, err
+    }
+    if count > 0 {
+        return models.QuizResult{}, ErrAlreadyCompleted
+    }
+
+    result := models.QuizResult{
+        UserID: userID,
+        QuizID: quizID,
+        Score:  score,
+    }
+    err := db.Transaction(func(tx *gorm.DB) error {
+        done, err := lessonDone(tx, userID, quiz.LessonID)
+        if err != nil {
+            return err
+        }
+        if err := tx.Create(&result).Error; err != nil {
+            return err
+        }
+
+        recorded := []events.Event{events.QuizSubmitted{
+            ResultID: result.ID,
+            UserID:   userID,
+            QuizID:   quizID,
+            LessonID: quiz.LessonID,
+            CourseID: quiz.Lesson.CourseID,
+            Score:    score,
+        }}
+        if !done {
+            recorded = append(recorded, events.LessonCompleted{UserID: userID, LessonID: quiz.LessonID, CourseID: quiz.Lesson.CourseID})
+        }
+        return events.Record(ctx, tx, recorded...)
+    })
+    if err != nil {
+        return models.QuizResult{}, err
+    }
+    metrics.QuizCompleted()
+    return result, nil
+}
+
+func (s *gormQuizService) ListResults(ctx context.Context, page Page) ([]models.QuizResult, int64, error) {
+    var results []models.QuizResult
+    total, err := paginate(s.db.WithContext(ctx).Model(&models.QuizResult{}), page, &results)
+    return results, total, err
+}
+
+func (s *gormQuizService) DeleteResult(ctx context.Context, userID, resultID uint) error {
+    db := s.db.WithContext(ctx)
+
+    var result models.QuizResult
+    if err := db.First(&result, resultID).Error; err != nil {
+        return notFound(err, "quiz result", resultID)
+    }
+
+    // Verifikasi bahwa pengguna adalah pemilik hasil quiz
+    if result.UserID != userID {
+        return fmt.Errorf("quiz result %d: %w", resultID, ErrForbidden)
+    }
+    return db.Delete(&result).Error
+}
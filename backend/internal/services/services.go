@@ -0,0 +1,97 @@
+// Package services holds the business logic of the platform behind small
+// interfaces, so it can be reused outside gin handlers (CLI, seed, tests).
+// The GORM implementations work with Postgres as well as SQLite.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/pkg/cache"
+	"go-learn-platform/internal/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+var (
+    // ErrNotFound is returned when a record does not exist
+    ErrNotFound = errors.New("not found")
+    // ErrForbidden is returned when the user may not touch a record
+    ErrForbidden = errors.New("forbidden")
+    // ErrAlreadyEnrolled is returned when enrolling twice in a course
+    ErrAlreadyEnrolled = errors.New("user is already enrolled in this course")
+    // ErrNotEnrolled is returned for actions that require an enrollment
+    ErrNotEnrolled = errors.New("user is not enrolled in this course")
+    // ErrAlreadyCompleted is returned when a quiz is completed twice
+    ErrAlreadyCompleted = errors.New("quiz is already completed")
+    // ErrModified is returned when a conditional update finds a newer version
+    ErrModified = errors.New("record was modified by another request")
+    // ErrNotRetryable is returned when retrying a job that is running or done
+    ErrNotRetryable = errors.New("only dead or pending jobs can be retried")
+    // ErrWebhookDisabled is returned when redelivering to a disabled webhook
+    ErrWebhookDisabled = errors.New("webhook is disabled")
+)
+
+// Services bundles the domain services used by the HTTP handlers
+type Services struct {
+    Courses     CourseService
+    Enrollments EnrollmentService
+    Quizzes     QuizService
+    Progress    ProgressService
+    Profiles    ProfileService
+    Jobs        JobService
+    Webhooks    WebhookService
+    Uploads     UploadService
+    // Catalog drops cached reads after changes made outside the services,
+    // e.g. by the seed command
+    Catalog *Catalog
+
+    Notifications NotificationService
+    notifications *gormNotificationService // Subscriber event notifikasi
+}
+
+// New wires the GORM implementations of all services. Catalog reads are
+// cached in store for ttl and dropped again by the services changing them;
+// use cache.Nop to read straight from the database.
+func New(db *gorm.DB, store cache.Store, ttl time.Duration, hooks config.WebhooksConfig, live config.NotificationsConfig) *Services {
+    catalog := NewCatalog(db, store, ttl)
+    progress := &gormProgressService{db: db, catalog: catalog}
+    notifications := &gormNotificationService{db: db, hub: newHub(db, live.PollInterval)}
+    return &Services{
+        Courses:     &gormCourseService{db: db, catalog: catalog},
+        Enrollments: &gormEnrollmentService{db: db, catalog: catalog},
+        Quizzes:     &gormQuizService{db: db, catalog: catalog},
+        Progress:    progress,
+        Profiles:    &gormProfileService{db: db, catalog: catalog},
+        Jobs:        NewJobService(db),
+        Webhooks:    NewWebhookService(db, hooks),
+        Uploads:     &gormUploadService{db: db, catalog: catalog},
+        Catalog:     catalog,
+
+        Notifications: notifications,
+        notifications: notifications,
+    }
+}
+
+// Subscribe registers the reactions of the services to domain events
+func (s *Services) Subscribe(bus *events.Bus) {
+    // Progress kursus dihitung ulang setiap kali sebuah lesson selesai
+    events.Subscribe(bus, "progress", func(ctx context.Context, event events.LessonCompleted) error {
+        return s.Progress.Recalculate(ctx, event.UserID, event.CourseID)
+    })
+
+    // Notifikasi in-app untuk lesson baru dan hasil kuis
+    events.Subscribe(bus, "notifications", s.notifications.lessonAdded)
+    events.Subscribe(bus, "notifications", s.notifications.quizGraded)
+}
+
+// notFound converts gorm.ErrRecordNotFound into ErrNotFound
+func notFound(err error, what string, id interface{}) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return fmt.Errorf("%s %v: %w", what, id, ErrNotFound)
+    }
+    return err
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/media"
+	"go-learn-platform/internal/pkg/metrics"
+	"go-learn-platform/internal/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+    // ErrUnsupportedVideo is returned for video types that cannot be uploaded
+    ErrUnsupportedVideo = errors.New("unsupported video type")
+    // ErrVideoTooLarge is returned for videos above the configured size
+    ErrVideoTooLarge = errors.New("video is too large")
+    // ErrUploadCompleted is returned when changing a finished upload
+    ErrUploadCompleted = errors.New("upload is already completed")
+    // ErrOffsetMismatch is returned for a chunk that does not start at the
+    // current offset of its upload
+    ErrOffsetMismatch = errors.New("upload offset does not match")
+    // ErrChunkChecksum is returned when a chunk does not match its checksum
+    ErrChunkChecksum = errors.New("chunk checksum mismatch")
+    // ErrBadChunk is returned when a chunk cannot be read or is too large
+    ErrBadChunk = errors.New("invalid chunk")
+    // ErrUploadReset is returned when a complete upload does not match its
+    // checksum; the upload starts over from offset 0
+    ErrUploadReset = errors.New("upload checksum mismatch, the upload has been reset")
+)
+
+// VideoTypes lists the content types accepted for lesson videos and the
+// extension of their files
+var VideoTypes = map[string]string{
+    "video/mp4":       ".mp4",
+    "video/webm":      ".webm",
+    "video/ogg":       ".ogv",
+    "video/quicktime": ".mov",
+}
+
+// UploadInput describes a new video upload
+type UploadInput struct {
+    LessonID    uint
+    Filename    string
+    ContentType string
+    Size        int64
+    Duration    float64
+    Checksum    string // SHA-256 (hex) dari seluruh file, opsional
+}
+
+// Chunk is a part of an upload sent by the client
+type Chunk struct {
+    Offset   int64
+    Body     io.Reader
+    Checksum []byte // SHA-256 dari chunk, opsional
+}
+
+// UploadService manages resumable uploads of lesson videos. Chunks are
+// written to a part file that becomes the lesson video once complete.
+type UploadService interface {
+    // Create starts an upload for a lesson of a course owned by the user
+    Create(ctx context.Context, userID uint, input UploadInput) (models.UploadSession, error)
+    // Get returns an upload of the user
+    Get(ctx context.Context, userID uint, id string) (models.UploadSession, error)
+    // Append writes a chunk at the current offset and attaches the video to
+    // its lesson once all bytes arrived. With ErrOffsetMismatch the returned
+    // upload holds the current offset.
+    Append(ctx context.Context, userID uint, id string, chunk Chunk) (models.UploadSession, error)
+    // Delete aborts an unfinished upload and removes its data
+    Delete(ctx context.Context, userID uint, id string) error
+}
+
+type gormUploadService struct {
+    db      *gorm.DB
+    catalog *Catalog
+}
+
+// NewUploadService returns an UploadService backed by GORM and the storage dir
+func NewUploadService(db *gorm.DB) UploadService {
+    return &gormUploadService{db: db}
+}
+
+func (s *gormUploadService) Create(ctx context.Context, userID uint, input UploadInput) (models.UploadSession, error) {
+    var session models.UploadSession
+    if _, ok := VideoTypes[input.ContentType]; !ok {
+        return session, ErrUnsupportedVideo
+    }
+    if input.Size <= 0 || input.Size > media.MaxVideoSize() {
+        return session, ErrVideoTooLarge
+    }
+
+    // Hanya pemilik kursus yang boleh mengunggah video
+    db := s.db.WithContext(ctx)
+    var lesson models.Lesson
+    if err := db.First(&lesson, input.LessonID).Error; err != nil {
+        return session, notFound(err, "lesson", input.LessonID)
+    }
+    var course models.Course
+    if err := db.First(&course, lesson.CourseID).Error; err != nil {
+        return session, notFound(err, "course", lesson.CourseID)
+    }
+    if course.UserID != userID {
+        return session, fmt.Errorf("lesson %d: %w", lesson.ID, ErrForbidden)
+    }
+
+    id, err := newUploadID()
+    if err != nil {
+        return session, err
+    }
+    if err := os.MkdirAll(media.TempDir(), os.ModePerm); err != nil {
+        return session, fmt.Errorf("create upload directory: %w", err)
+    }
+    file, err := os.Create(uploadPartPath(id))
+    if err != nil {
+        return session, fmt.Errorf("create upload: %w", err)
+    }
+    file.Close()
+
+    session = models.UploadSession{
+        ID:              id,
+        UserID:          userID,
+        LessonID:        lesson.ID,
+        Filename:        filepath.Base(input.Filename),
+        ContentType:     input.ContentType,
+        Size:            input.Size,
+        DurationSeconds: input.Duration,
+        Checksum:        strings.ToLower(input.Checksum),
+    }
+    if err := db.Create(&session).Error; err != nil {
+        os.Remove(uploadPartPath(id))
+        return session, err
+    }
+    return session, nil
+}
+
+func (s *gormUploadService) Get(ctx context.Context, userID uint, id string) (models.UploadSession, error) {
+    var session models.UploadSession
+    if err := s.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+        return session, notFound(err, "upload", id)
+    }
+    if session.UserID != userID {
+        return session, fmt.Errorf("upload %s: %w", id, ErrForbidden)
+    }
+    return session, nil
+}
+
+func (s *gormUploadService) Append(ctx context.Context, userID uint, id string, chunk Chunk) (models.UploadSession, error) {
+    session, err := s.Get(ctx, userID, id)
+    if err != nil {
+        return session, err
+    }
+
+    // Baris upload dikunci sampai chunk tersimpan: request lain dengan offset
+    // yang sama menunggu, lalu ditolak karena offset sudah maju
+    var done *finished
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, "id = ?", id).Error; err != nil {
+            return notFound(err, "upload", id)
+        }
+        if session.CompletedAt != nil {
+            return ErrUploadCompleted
+        }
+        if chunk.Offset != session.Offset {
+            return ErrOffsetMismatch
+        }
+        if err := s.write(ctx, tx, &session, chunk); err != nil {
+            return err
+        }
+        if session.Offset < session.Size {
+            return nil
+        }
+        done, err = s.finish(ctx, tx, &session)
+        return err
+    })
+    if done != nil {
+        done.cleanup(err == nil)
+    }
+    if err != nil {
+        return session, err
+    }
+    if done != nil && done.reset {
+        return session, ErrUploadReset
+    }
+    if session.CompletedAt != nil {
+        // Video baru tampil di detail kursus
+        var lesson models.Lesson
+        if err := s.db.WithContext(ctx).First(&lesson, session.LessonID).Error; err == nil {
+            s.catalog.LessonChanged(ctx, lesson.CourseID)
+        }
+    }
+    return session, nil
+}
+
+// write appends a chunk to the part file and saves the new offset. The part
+// file is cut back to the old offset when anything fails.
+func (s *gormUploadService) write(ctx context.Context, tx *gorm.DB, session *models.UploadSession, chunk Chunk) error {
+    remaining := session.Size - session.Offset
+    limit := media.MaxChunkSize()
+    if remaining < limit {
+        limit = remaining
+    }
+
+    file, err := os.OpenFile(uploadPartPath(session.ID), os.O_WRONLY, 0)
+    if err != nil {
+        return fmt.Errorf("open upload: %w", err)
+    }
+    defer file.Close()
+    // Buang sisa chunk dari transaksi yang gagal sebelumnya
+    if err := file.Truncate(session.Offset); err != nil {
+        return fmt.Errorf("open upload: %w", err)
+    }
+    if _, err := file.Seek(session.Offset, io.SeekStart); err != nil {
+        return fmt.Errorf("open upload: %w", err)
+    }
+
+    // Tulis chunk langsung ke disk sambil menghitung checksum, tanpa menampung di memori
+    hasher := sha256.New()
+    _, span := tracing.Start(ctx, "storage.write_chunk",
+        attribute.String("upload.id", session.ID),
+        attribute.Int64("upload.offset", session.Offset))
+    written, err := io.Copy(io.MultiWriter(file, hasher), io.LimitReader(chunk.Body, limit+1))
+    if err == nil && written > limit {
+        err = errors.New("chunk exceeds the remaining upload size")
+    }
+    span.SetAttributes(attribute.Int64("upload.chunk_size", written))
+    tracing.End(span, err)
+    if err != nil {
+        file.Truncate(session.Offset)
+        return fmt.Errorf("%w: %v", ErrBadChunk, err)
+    }
+    if chunk.Checksum != nil && !bytes.Equal(hasher.Sum(nil), chunk.Checksum) {
+        file.Truncate(session.Offset)
+        return ErrChunkChecksum
+    }
+
+    // Update juga mengisi session.Offset dengan nilai baru
+    previous := session.Offset
+    if err := tx.Model(session).Update("offset", previous+written).Error; err != nil {
+        file.Truncate(previous)
+        return fmt.Errorf("save upload progress: %w", err)
+    }
+    metrics.AddUploadBytes("video", written)
+    return nil
+}
+
+func (s *gormUploadService) Delete(ctx context.Context, userID uint, id string) error {
+    session, err := s.Get(ctx, userID, id)
+    if err != nil {
+        return err
+    }
+    if session.CompletedAt != nil {
+        return ErrUploadCompleted
+    }
+    if err := s.db.WithContext(ctx).Delete(&session).Error; err != nil {
+        return err
+    }
+    os.Remove(uploadPartPath(session.ID))
+    return nil
+}
+
+// finished is the outcome of finish. Files are only moved or removed by
+// cleanup once the transaction is over.
+type finished struct {
+    reset     bool   // Checksum tidak cocok, upload dimulai ulang
+    partPath  string
+    finalPath string
+    previous  string // Video lama yang diganti
+}
+
+// cleanup removes the files that are no longer needed after the transaction
+// committed or rolled back
+func (f *finished) cleanup(committed bool) {
+    switch {
+    case f.reset || f.finalPath == "":
+    case committed:
+        os.Remove(f.partPath)
+        if f.previous != "" && f.previous != f.finalPath {
+            os.Remove(f.previous)
+        }
+    default:
+        // Part file tetap ada sehingga upload bisa diselesaikan lagi
+        os.Remove(f.finalPath)
+    }
+}
+
+// finish verifies a fully received upload and attaches it to its lesson in
+// tx. A corrupt upload is reset to offset 0 instead.
+func (s *gormUploadService) finish(ctx context.Context, tx *gorm.DB, session *models.UploadSession) (done *finished, err error) {
+    _, span := tracing.Start(ctx, "storage.finish_upload",
+        attribute.String("upload.id", session.ID),
+        attribute.Int64("file.size", session.Size))
+    defer func() { tracing.End(span, err) }()
+
+    done = &finished{partPath: uploadPartPath(session.ID)}
+    if session.Checksum != "" {
+        sum, err := fileChecksum(done.partPath)
+        if err != nil {
+            return done, fmt.Errorf("failed to verify upload: %w", err)
+        }
+        if sum != session.Checksum {
+            // File rusak: mulai ulang dari awal
+            if err := tx.Model(session).Update("offset", 0).Error; err != nil {
+                return done, fmt.Errorf("failed to reset upload: %w", err)
+            }
+            if err := os.Truncate(done.partPath, 0); err != nil {
+                return done, fmt.Errorf("failed to reset upload: %w", err)
+            }
+            session.Offset = 0
+            done.reset = true
+            return done, nil
+        }
+    }
+
+    if err := os.MkdirAll(media.VideoDir(), os.ModePerm); err != nil {
+        return done, fmt.Errorf("failed to create video directory: %w", err)
+    }
+    // Video disalin (hard link) dulu; part file baru dihapus setelah commit
+    finalPath := filepath.Join(media.VideoDir(), session.ID+VideoTypes[session.ContentType])
+    if err := linkFile(done.partPath, finalPath); err != nil {
+        return done, fmt.Errorf("failed to store video: %w", err)
+    }
+    done.finalPath = finalPath
+
+    // Pakai ulang baris lama (termasuk yang sudah di-soft delete) karena lesson_id unik
+    var video models.LessonVideo
+    if err := tx.Unscoped().Where("lesson_id = ?", session.LessonID).Limit(1).Find(&video).Error; err != nil {
+        return done, err
+    }
+    done.previous = video.Path
+    video.LessonID = session.LessonID
+    video.Path = finalPath
+    video.ContentType = session.ContentType
+    video.Size = session.Size
+    video.DurationSeconds = session.DurationSeconds
+    video.Checksum = session.Checksum
+    video.DeletedAt = gorm.DeletedAt{}
+    if err := tx.Unscoped().Save(&video).Error; err != nil {
+        return done, err
+    }
+
+    now := time.Now()
+    if err := tx.Model(session).Update("completed_at", now).Error; err != nil {
+        return done, err
+    }
+    session.CompletedAt = &now
+    return done, nil
+}
+
+// linkFile makes dst a hard link of src, or a copy where the file system
+// does not support links
+func linkFile(src, dst string) error {
+    os.Remove(dst) // Sisa percobaan sebelumnya
+    if err := os.Link(src, dst); err == nil {
+        return nil
+    }
+
+    in, err := os.Open(src)
+    if err != nil {
+        return err
+    }
+    defer in.Close()
+    out, err := os.Create(dst)
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(out, in); err != nil {
+        out.Close()
+        os.Remove(dst)
+        return err
+    }
+    return out.Close()
+}
+
+// uploadPartPath returns the location of the partial data of an upload
+func uploadPartPath(id string) string {
+    return filepath.Join(media.TempDir(), id+".part")
+}
+
+// newUploadID generates a random identifier for an upload session
+func newUploadID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 of a file
+func fileChecksum(path string) (string, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer file.Close()
+
+    hasher := sha256.New()
+    if _, err := io.Copy(hasher, file); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(hasher.Sum(nil)), nil
+}
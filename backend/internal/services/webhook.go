@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/webhooks"
+
+	"gorm.io/gorm"
+)
+
+// Actor is the user calling a service that lets admins do more than others
+type Actor struct {
+    UserID uint
+    Admin  bool
+}
+
+// WebhookInput holds the settings of a webhook. CourseID is only used when
+// creating; nil registers the webhook for all courses, which only admins
+// may do. A nil Active leaves the webhook as it is.
+type WebhookInput struct {
+    URL      string
+    Events   []string
+    CourseID *uint
+    Active   *bool
+}
+
+// WebhookService manages the webhooks of admins and course owners and the
+// log of their deliveries. Users only see their own webhooks, admins all.
+type WebhookService interface {
+    List(ctx context.Context, actor Actor, page Page) ([]models.Webhook, int64, error)
+    Get(ctx context.Context, actor Actor, id uint) (models.Webhook, error)
+    // Create registers a webhook with a new signing secret
+    Create(ctx context.Context, actor Actor, input WebhookInput) (models.Webhook, error)
+    Update(ctx context.Context, actor Actor, id uint, input WebhookInput) (models.Webhook, error)
+    Delete(ctx context.Context, actor Actor, id uint) error
+
+    // Deliveries returns a page of the deliveries of a webhook, newest first
+    Deliveries(ctx context.Context, actor Actor, id uint, page Page) ([]models.WebhookDelivery, int64, error)
+    // Redeliver sends the payload of a delivery again as a new delivery
+    Redeliver(ctx context.Context, actor Actor, id, deliveryID uint) (models.WebhookDelivery, error)
+}
+
+type gormWebhookService struct {
+    db  *gorm.DB
+    cfg config.WebhooksConfig
+}
+
+// NewWebhookService returns a WebhookService backed by GORM
+func NewWebhookService(db *gorm.DB, cfg config.WebhooksConfig) WebhookService {
+    return &gormWebhookService{db: db, cfg: cfg}
+}
+
+func (s *gormWebhookService) List(ctx context.Context, actor Actor, page Page) ([]models.Webhook, int64, error) {
+    query := s.db.WithContext(ctx).Model(&models.Webhook{})
+    if !actor.Admin {
+        query = query.Where("user_id = ?", actor.UserID)
+    }
+    var hooks []models.Webhook
+    total, err := paginate(query, page, &hooks)
+    return hooks, total, err
+}
+
+func (s *gormWebhookService) Get(ctx context.Context, actor Actor, id uint) (models.Webhook, error) {
+    var hook models.Webhook
+    if err := s.db.WithContext(ctx).First(&hook, id).Error; err != nil {
+        return hook, notFound(err, "webhook", id)
+    }
+    if !actor.Admin && hook.UserID != actor.UserID {
+        return hook, fmt.Errorf("webhook %d: %w", id, ErrForbidden)
+    }
+    return hook, nil
+}
+
+func (s *gormWebhookService) Create(ctx context.Context, actor Actor, input WebhookInput) (models.Webhook, error) {
+    // Webhook untuk semua kursus khusus admin, selain itu harus pemilik kursus
+    if input.CourseID == nil && !actor.Admin {
+        return models.Webhook{}, fmt.Errorf("webhook for all courses: %w", ErrForbidden)
+    }
+    if input.CourseID != nil {
+        var course models.Course
+        if err := s.db.WithContext(ctx).First(&course, *input.CourseID).Error; err != nil {
+            return models.Webhook{}, notFound(err, "course", *input.CourseID)
+        }
+        if !actor.Admin && course.UserID != actor.UserID {
+            return models.Webhook{}, fmt.Errorf("course %d: %w", course.ID, ErrForbidden)
+        }
+    }
+
+    secret, err := webhooks.NewSecret()
+    if err != nil {
+        return models.Webhook{}, err
+    }
+    hook := models.Webhook{
+        UserID:   actor.UserID,
+        CourseID: input.CourseID,
+        URL:      input.URL,
+        Secret:   secret,
+        Events:   eventList(input.Events),
+        Active:   input.Active == nil || *input.Active,
+    }
+    // Select agar Active=false tidak diganti default kolom
+    err = s.db.WithContext(ctx).
+        Select("user_id", "course_id", "url", "secret", "events", "active", "created_at", "updated_at").
+        Create(&hook).Error
+    return hook, err
+}
+
+func (s *gormWebhookService) Update(ctx context.Context, actor Actor, id uint, input WebhookInput) (models.Webhook, error) {
+    hook, err := s.Get(ctx, actor, id)
+    if err != nil {
+        return hook, err
+    }
+
+    hook.URL = input.URL
+    hook.Events = eventList(input.Events)
+    if input.Active != nil {
+        hook.Active = *input.Active
+    }
+    err = s.db.WithContext(ctx).Model(&hook).Select("url", "events", "active").Updates(&hook).Error
+    return hook, err
+}
+
+func (s *gormWebhookService) Delete(ctx context.Context, actor Actor, id uint) error {
+    hook, err := s.Get(ctx, actor, id)
+    if err != nil {
+        return err
+    }
+    return s.db.WithContext(ctx).Delete(&hook).Error
+}
+
+func (s *gormWebhookService) Deliveries(ctx context.Context, actor Actor, id uint, page Page) ([]models.WebhookDelivery, int64, error) {
+    if _, err := s.Get(ctx, actor, id); err != nil {
+        return nil, 0, err
+    }
+
+    query := s.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", id)
+    var total int64
+    if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    var deliveries []models.WebhookDelivery
+    err := query.Order("id DESC").Offset(page.Offset()).Limit(page.Size).Find(&deliveries).Error
+    return deliveries, total, err
+}
+
+func (s *gormWebhookService) Redeliver(ctx context.Context, actor Actor, id, deliveryID uint) (models.WebhookDelivery, error) {
+    hook, err := s.Get(ctx, actor, id)
+    if err != nil {
+        return models.WebhookDelivery{}, err
+    }
+    if !hook.Active {
+        return models.WebhookDelivery{}, fmt.Errorf("webhook %d: %w", id, ErrWebhookDisabled)
+    }
+
+    var original models.WebhookDelivery
+    if err := s.db.WithContext(ctx).Where("webhook_id = ?", id).First(&original, deliveryID).Error; err != nil {
+        return original, notFound(err, "delivery", deliveryID)
+    }
+
+    delivery := models.WebhookDelivery{
+        WebhookID:    id,
+        EventID:      original.EventID,
+        Event:        original.Event,
+        Payload:      original.Payload,
+        Status:       models.DeliveryPending,
+        RedeliveryOf: &original.ID,
+    }
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&delivery).Error; err != nil {
+            return err
+        }
+        return webhooks.Enqueue(ctx, tx, s.cfg, delivery.ID)
+    })
+    return delivery, err
+}
+
+// eventList stores the event names of a webhook in the order of
+// webhooks.Events, without duplicates
+func eventList(names []string) string {
+    var list []string
+    for _, name := range webhooks.Events {
+        if slices.Contains(names, name) {
+            list = append(list, name)
+        }
+    }
+    return strings.Join(list, ",")
+}
@@ -0,0 +1,101 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-learn-platform/internal/events"
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+// envelope is the JSON body of a delivery
+type envelope struct {
+    ID        uint            `json:"id"` // ID event, sama untuk pengiriman ulang
+    Event     string          `json:"event"`
+    CreatedAt time.Time       `json:"created_at"`
+    Data      json.RawMessage `json:"data"`
+}
+
+// Subscribe makes bus create the deliveries of every event in Events
+func Subscribe(bus *events.Bus, db *gorm.DB, cfg config.WebhooksConfig) {
+    for _, name := range Events {
+        bus.Handle(name, "webhooks", func(ctx context.Context, event models.OutboxEvent) error {
+            return fanOut(ctx, db, cfg, event)
+        })
+    }
+}
+
+// Subscribed reports whether a webhook receives the event name
+func Subscribed(hook models.Webhook, name string) bool {
+    return slices.Contains(strings.Split(hook.Events, ","), name)
+}
+
+// Enqueue schedules the sending of a delivery. Pass the transaction that
+// created the delivery as db.
+func Enqueue(ctx context.Context, db *gorm.DB, cfg config.WebhooksConfig, deliveryID uint) error {
+    _, err := Send.Enqueue(ctx, db, SendPayload{DeliveryID: deliveryID}, jobs.MaxAttempts(cfg.MaxAttempts))
+    return err
+}
+
+// fanOut creates and enqueues a delivery for every active webhook that
+// subscribed to the event and covers its course
+func fanOut(ctx context.Context, db *gorm.DB, cfg config.WebhooksConfig, event models.OutboxEvent) error {
+    var scope struct {
+        CourseID uint `json:"course_id"`
+    }
+    if err := json.Unmarshal([]byte(event.Payload), &scope); err != nil {
+        return fmt.Errorf("%s %d: %w: %v", event.Name, event.ID, events.ErrInvalidPayload, err)
+    }
+    body, err := json.Marshal(envelope{ID: event.ID, Event: event.Name, CreatedAt: event.CreatedAt, Data: json.RawMessage(event.Payload)})
+    if err != nil {
+        return err
+    }
+
+    return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var hooks []models.Webhook
+        if err := tx.Where("active = ? AND (course_id IS NULL OR course_id = ?)", true, scope.CourseID).
+            Order("id").
+            Find(&hooks).Error; err != nil {
+            return err
+        }
+
+        for _, hook := range hooks {
+            if !Subscribed(hook, event.Name) {
+                continue
+            }
+            // Subscriber yang diulang tidak membuat pengiriman ganda
+            var existing int64
+            if err := tx.Model(&models.WebhookDelivery{}).
+                Where("webhook_id = ? AND event_id = ? AND redelivery_of IS NULL", hook.ID, event.ID).
+                Count(&existing).Error; err != nil {
+                return err
+            }
+            if existing > 0 {
+                continue
+            }
+
+            delivery := models.WebhookDelivery{
+                WebhookID: hook.ID,
+                EventID:   event.ID,
+                Event:     event.Name,
+                Payload:   string(body),
+                Status:    models.DeliveryPending,
+            }
+            if err := tx.Create(&delivery).Error; err != nil {
+                return err
+            }
+            if err := Enqueue(ctx, tx, cfg, delivery.ID); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go-learn-platform/internal/jobs"
+	"go-learn-platform/internal/models"
+	"go-learn-platform/internal/pkg/config"
+	"go-learn-platform/internal/pkg/metrics"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"gorm.io/gorm"
+)
+
+// responseExcerpt is the number of response bytes kept in the delivery log
+const responseExcerpt = 1024
+
+// ErrPrivateAddress is returned when a webhook resolves to a loopback,
+// private or link-local address and WEBHOOK_ALLOW_PRIVATE is off
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// sender posts deliveries to their webhooks
+type sender struct {
+    db     *gorm.DB
+    cfg    config.WebhooksConfig
+    client *http.Client
+}
+
+// Register handles Send jobs on q
+func Register(q *jobs.Queue, db *gorm.DB, cfg config.WebhooksConfig) {
+    s := &sender{db: db, cfg: cfg, client: newClient(cfg)}
+    jobs.Handle(q, Send, s.send)
+}
+
+// newClient returns the HTTP client of the deliveries. Redirects are not
+// followed, and unless allowed only public addresses are dialed; the check
+// runs on the resolved IP, so DNS cannot point a webhook inside the network.
+func newClient(cfg config.WebhooksConfig) *http.Client {
+    dialer := &net.Dialer{Timeout: cfg.Timeout}
+    if !cfg.AllowPrivate {
+        dialer.Control = publicOnly
+    }
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.Proxy = nil
+    transport.DialContext = dialer.DialContext
+
+    return &http.Client{
+        Transport: otelhttp.NewTransport(transport),
+        Timeout:   cfg.Timeout,
+        CheckRedirect: func(*http.Request, []*http.Request) error {
+            return http.ErrUseLastResponse
+        },
+    }
+}
+
+// publicOnly refuses connections to addresses inside the network
+func publicOnly(network, address string, _ syscall.RawConn) error {
+    host, _, err := net.SplitHostPort(address)
+    if err != nil {
+        return err
+    }
+    ip := net.ParseIP(host)
+    if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+        ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+        return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+    }
+    return nil
+}
+
+// send posts a delivery and logs the outcome on it. Failed attempts return
+// an error so the job queue retries them.
+func (s *sender) send(ctx context.Context, payload SendPayload) error {
+    db := s.db.WithContext(ctx)
+
+    var delivery models.WebhookDelivery
+    if err := db.First(&delivery, payload.DeliveryID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return jobs.Permanent(fmt.Errorf("delivery %d: %w", payload.DeliveryID, err))
+        }
+        return err
+    }
+    if delivery.Status != models.DeliveryPending {
+        return nil // Sudah selesai, mis. job yang diambil ulang
+    }
+
+    var hook models.Webhook
+    err := db.First(&hook, delivery.WebhookID).Error
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        return s.abandon(ctx, &delivery, "webhook was deleted")
+    case err != nil:
+        return err
+    case !hook.Active:
+        return s.abandon(ctx, &delivery, "webhook is disabled")
+    }
+
+    start := time.Now()
+    err = s.post(ctx, hook, &delivery)
+    delivery.Attempts++
+    delivery.DurationMS = time.Since(start).Milliseconds()
+
+    result := "succeeded"
+    switch {
+    case err == nil:
+        delivery.Status = models.DeliverySucceeded
+        delivery.Error = ""
+        delivery.DeliveredAt = &start
+    case delivery.Attempts >= s.cfg.MaxAttempts || jobs.IsPermanent(err) || errors.Is(err, ErrPrivateAddress):
+        delivery.Status = models.DeliveryFailed
+        delivery.Error = err.Error()
+        err = jobs.Permanent(err)
+        result = "failed"
+    default:
+        delivery.Error = err.Error()
+        result = "retry"
+    }
+    metrics.WebhookDelivered(delivery.Event, result)
+
+    if saveErr := s.save(ctx, &delivery); saveErr != nil {
+        return saveErr
+    }
+    return err
+}
+
+// post sends the delivery and stores the response on it. A response other
+// than 2xx is an error.
+func (s *sender) post(ctx context.Context, hook models.Webhook, delivery *models.WebhookDelivery) error {
+    delivery.StatusCode = 0
+    delivery.Response = ""
+
+    body := []byte(delivery.Payload)
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+    if err != nil {
+        return jobs.Permanent(err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("User-Agent", "go-learn-platform-webhooks/1")
+    req.Header.Set(SignatureHeader, Sign(hook.Secret, time.Now(), body))
+    req.Header.Set(EventHeader, delivery.Event)
+    req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+
+    res, err := s.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer res.Body.Close()
+
+    excerpt, _ := io.ReadAll(io.LimitReader(res.Body, responseExcerpt))
+    delivery.StatusCode = res.StatusCode
+    delivery.Response = string(excerpt)
+    if res.StatusCode < 200 || res.StatusCode > 299 {
+        return fmt.Errorf("webhook answered %d", res.StatusCode)
+    }
+    return nil
+}
+
+// abandon marks a delivery as failed without sending it
+func (s *sender) abandon(ctx context.Context, delivery *models.WebhookDelivery, reason string) error {
+    delivery.Status = models.DeliveryFailed
+    delivery.Error = reason
+    if err := s.save(ctx, delivery); err != nil {
+        return err
+    }
+    return jobs.Permanent(errors.New(reason))
+}
+
+// save stores the outcome of an attempt
+func (s *sender) save(ctx context.Context, delivery *models.WebhookDelivery) error {
+    return s.db.WithContext(ctx).Model(delivery).
+        Select("status", "attempts", "status_code", "response", "error", "duration_ms", "delivered_at").
+        Updates(delivery).Error
+}
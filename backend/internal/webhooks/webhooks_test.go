@@ -0,0 +1,44 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+    now := time.Unix(1700000000, 0)
+    body := []byte(`{"event":"enrollment.created"}`)
+    header := Sign("whsec_test", now, body)
+
+    if err := Verify("whsec_test", header, body, time.Minute, now.Add(30*time.Second)); err != nil {
+        t.Fatalf("expected a valid signature: %v", err)
+    }
+    cases := map[string]struct {
+        secret, header string
+        body           []byte
+        now            time.Time
+    }{
+        "other secret":  {"whsec_other", header, body, now},
+        "changed body":  {"whsec_test", header, []byte(`{}`), now},
+        "too old":       {"whsec_test", header, body, now.Add(2 * time.Minute)},
+        "missing parts": {"whsec_test", "t=1700000000", body, now},
+        "garbage":       {"whsec_test", "nonsense", body, now},
+    }
+    for name, c := range cases {
+        if err := Verify(c.secret, c.header, c.body, time.Minute, c.now); !errors.Is(err, ErrInvalidSignature) {
+            t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+        }
+    }
+}
+
+func TestPublicOnly(t *testing.T) {
+    for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "192.168.0.1:80", "169.254.169.254:80", "[::1]:80", "0.0.0.0:80"} {
+        if err := publicOnly("tcp", address, nil); !errors.Is(err, ErrPrivateAddress) {
+            t.Errorf("%s: expected ErrPrivateAddress, got %v", address, err)
+        }
+    }
+    if err := publicOnly("tcp", "93.184.216.34:443", nil); err != nil {
+        t.Errorf("expected a public address to pass, got %v", err)
+    }
+}
@@ -12,6 +12,7 @@ import LoginView from '@/views/LoginView/LoginView.vue'
 import CreateProfile from '@/views/Profile/Create/CreateProfile.vue'
 import MyProfile from '@/views/Profile/Get/MyProfile.vue'
 import UpdateProfile from '@/views/Profile/Update/UpdateProfile.vue'
+import UnsubscribeView from '@/views/Unsubscribe/UnsubscribeView.vue'
 import { createRouter, createWebHistory } from 'vue-router'
 
 const router = createRouter({
@@ -37,6 +38,11 @@ const router = createRouter({
       name: 'Login',
       component: LoginView,
     },
+    {
+      path: '/unsubscribe',
+      name: 'Unsubscribe',
+      component: UnsubscribeView, // Link dari email notifikasi, tanpa login
+    },
     {
       path: '/profile/create',
       name: 'CreateProfile',
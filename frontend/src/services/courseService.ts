@@ -1,34 +1,27 @@
 import axiosInstance from './axiosInstance'
 
-export interface Profile {
-  ID: number
-  Name: string
-  Image: string
-}
-
-export interface User {
-  ID: number
-  Email: string
-  Profile: Profile
+export interface Instructor {
+  id: number
+  name: string
+  image: string
 }
 
 export interface Course {
-  ID: number
-  CreatedAt: string
-  UpdatedAt: string
-  DeletedAt: string | null
-  Title: string
-  Description: string
-  Image: string
-  User: User
-  Progress: number
+  id: number
+  title: string
+  description: string
+  image: string
+  instructor_id: number
+  instructor?: Instructor
+  created_at: string
+  updated_at: string
 }
 
 export interface Quiz {
   id: number
   lessonId: number
   question: string
-  options: string[]
+  options: string
 }
 
 export interface Lesson {
@@ -50,7 +43,6 @@ export interface CourseDetail {
   image?: string
   user: {
     id: number
-    email: string
     profile: {
       id: number
       name: string
@@ -61,6 +53,23 @@ export interface CourseDetail {
 }
 
 
+/**
+ * Map a lesson from the API to the Lesson interface.
+ */
+export const mapLesson = (lesson: any): Lesson => ({
+  id: lesson.id,
+  title: lesson.title,
+  content: lesson.content,
+  order: lesson.order,
+  image: lesson.image || undefined,
+  quizzes: (lesson.quizzes || []).map((q: any) => ({
+    id: q.id,
+    lessonId: q.lesson_id,
+    question: q.question,
+    options: q.options,
+  })),
+})
+
 /**
  * Fetch all courses from the backend API.
  * @returns {Promise<Course[]>} - A list of courses.
@@ -72,7 +81,7 @@ export const fetchCourses = async (): Promise<Course[]> => {
         Authorization: `Bearer ${localStorage.getItem('authToken')}`, // Assuming token is stored in localStorage
       },
     })
-    return response.data.courses
+    return response.data.data
   } catch (error) {
     console.error('Error fetching courses:', error)
     throw error
@@ -86,36 +95,23 @@ export const fetchCourseById = async (courseId: number): Promise<CourseDetail> =
     })
 
     // raw API payload
-    const raw = data.course
+    const raw = data.data
 
     // map to your CourseDetail interface
     const mapped: CourseDetail = {
-      id: raw.ID,
-      title: raw.Title,
-      description: raw.Description,
-      image: raw.Image || undefined,
+      id: raw.id,
+      title: raw.title,
+      description: raw.description,
+      image: raw.image || undefined,
       user: {
-        id: raw.User.ID,
-        email: raw.User.Email,
+        id: raw.instructor_id,
         profile: {
-          id: raw.User.Profile?.ID ?? 0,
-          name: raw.User.Profile?.Name ?? 'Unknown',
-          image: raw.User.Profile?.Image,
+          id: raw.instructor?.id ?? 0,
+          name: raw.instructor?.name || 'Unknown',
+          image: raw.instructor?.image || undefined,
         },
       },
-      lessons: (raw.Lessons || []).map((l: any) => ({
-        id: l.ID,
-        title: l.Title,
-        content: l.Content,
-        order: l.Order,
-        image: l.Image,
-        quizzes: (l.Quizzes || []).map((q: any) => ({
-          id: q.ID,
-          lessonId: q.LessonID,
-          question: q.Question,
-          options: q.Options,
-        })),
-      })),
+      lessons: (raw.lessons || []).map(mapLesson),
     }
 
     return mapped
@@ -140,7 +136,7 @@ export const createCourse = async (formData: FormData): Promise<Course> => {
         Authorization: `Bearer ${localStorage.getItem('authToken')}`,
       },
     })
-    return response.data.course
+    return response.data.data
   } catch (error) {
     console.error('Error creating course:', error)
     throw error
@@ -164,7 +160,7 @@ export const updateCourseWithFormData = async (
         Authorization: `Bearer ${localStorage.getItem('authToken')}`,
       },
     });
-    return response.data.course; // Sesuaikan dengan respons API
+    return response.data.data;
   } catch (error) {
     console.error('Error updating course with image:', error);
     throw error;
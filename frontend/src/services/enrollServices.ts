@@ -4,10 +4,10 @@ import axiosInstance from './axiosInstance'
  * Represents a course as returned within an enrollment.
  */
 export interface Course {
-  ID: number
-  Title: string
-  Description: string
-  Image: string
+  id: number
+  title: string
+  description: string
+  image: string
   // include other fields if needed
 }
 
@@ -15,11 +15,11 @@ export interface Course {
  * Represents an enrollment record.
  */
 export interface Enrollment {
-  ID: number
-  UserID: number
-  CourseID: number
-  Progress: number
-  Course: Course
+  id: number
+  user_id: number
+  course_id: number
+  progress: number
+  course?: Course
 }
 
 /**
@@ -38,7 +38,7 @@ export const enrollUser = async (courseId: number): Promise<Enrollment> => {
         },
       }
     )
-    return response.data.enrollment as Enrollment
+    return response.data.data as Enrollment
   } catch (error) {
     console.error('Error enrolling user:', error)
     throw error
@@ -60,7 +60,7 @@ export const getEnrollments = async (userId: number): Promise<Enrollment[]> => {
         },
       }
     )
-    return response.data.enrollments as Enrollment[]
+    return response.data.data as Enrollment[]
   } catch (error) {
     console.error('Error fetching enrollments:', error)
     throw error
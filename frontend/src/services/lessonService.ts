@@ -1,5 +1,5 @@
 import axiosInstance from './axiosInstance'
-import type { Lesson } from './courseService'
+import { mapLesson, type Lesson } from './courseService'
 
 /**
  * Fetch semua lesson berdasarkan course ID
@@ -13,19 +13,8 @@ export const fetchLessonsByCourse = async (courseId: number): Promise<Lesson[]>
         Authorization: `Bearer ${localStorage.getItem('authToken')}`,
       },
     })
-    return response.data.data.map((lesson: any) => ({
-      id: lesson.ID,
-      title: lesson.Title,
-      content: lesson.Content,
-      order: lesson.Order,
-      image: lesson.Image || undefined,
-      quizzes: (lesson.Quizzes || []).map((q: any) => ({
-        id: q.ID,
-        lessonId: q.LessonID,
-        question: q.Question,
-        options: q.Options,
-      })),
-    }))
+    const data = response.data.data
+    return (Array.isArray(data) ? data : [data]).map(mapLesson)
   } catch (error) {
     console.error('Error fetching lessons:', error)
     throw error
@@ -44,20 +33,7 @@ export const fetchLessonById = async (lessonId: number): Promise<Lesson> => {
         Authorization: `Bearer ${localStorage.getItem('authToken')}`,
       },
     })
-    const data = response.data.data
-    return {
-      id: data.ID,
-      title: data.Title,
-      content: data.Content,
-      order: data.Order,
-      image: data.Image || undefined,
-      quizzes: (data.Quizzes || []).map((q: any) => ({
-        id: q.ID,
-        lessonId: q.LessonID,
-        question: q.Question,
-        options: q.Options,
-      })),
-    }
+    return mapLesson(response.data.data)
   } catch (error) {
     console.error('Error fetching lesson:', error)
     throw error
@@ -77,7 +53,7 @@ export const createLesson = async (formData: FormData): Promise<Lesson> => {
         Authorization: `Bearer ${localStorage.getItem('authToken')}`,
       },
     })
-    return response.data.data
+    return mapLesson(response.data.data)
   } catch (error) {
     console.error('Error creating lesson:', error)
     throw error
@@ -98,7 +74,7 @@ export const updateLesson = async (lessonId: number, formData: FormData): Promis
         Authorization: `Bearer ${localStorage.getItem('authToken')}`,
       },
     })
-    return response.data.data
+    return mapLesson(response.data.data)
   } catch (error) {
     console.error('Error updating lesson:', error)
     throw error
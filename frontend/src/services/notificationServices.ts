@@ -0,0 +1,83 @@
+import axiosInstance from './axiosInstance'
+
+/**
+ * Represents an in-app notification.
+ */
+export interface Notification {
+  id: number
+  type: string
+  payload: Record<string, unknown>
+  read: boolean
+  read_at: string | null
+  created_at: string
+}
+
+/**
+ * Count the unread notifications of the current user.
+ * @returns The number of unread notifications.
+ */
+export const getUnreadCount = async (): Promise<number> => {
+  const response = await axiosInstance.get('/notifications', {
+    params: { unread: true, per_page: 1 },
+  })
+  return response.data.meta.total as number
+}
+
+/**
+ * Mark every unread notification of the current user as read.
+ */
+export const markAllNotificationsRead = async (): Promise<void> => {
+  await axiosInstance.post('/notifications/read-all')
+}
+
+/**
+ * Receive new notifications live. EventSource cannot send the Authorization
+ * header, so the stream is opened with a short-lived stream token. When the
+ * connection is lost and the token has expired, a new token is requested and
+ * the stream resumes after the last notification received.
+ * @param onNotification - Called for every new notification.
+ * @returns A function that closes the stream.
+ */
+export const openNotificationStream = (
+  onNotification: (notification: Notification) => void,
+): (() => void) => {
+  const baseURL = axiosInstance.defaults.baseURL ?? ''
+  let source: EventSource | null = null
+  let lastEventId = ''
+  let closed = false
+
+  const connect = async () => {
+    try {
+      const response = await axiosInstance.post('/notifications/stream-token')
+      if (closed) return
+
+      const params = new URLSearchParams({ token: response.data.data.token })
+      if (lastEventId) {
+        params.set('last_event_id', lastEventId)
+      }
+      source = new EventSource(`${baseURL}/notifications/stream?${params}`)
+      source.addEventListener('notification', (event) => {
+        const message = event as MessageEvent
+        lastEventId = message.lastEventId
+        onNotification(JSON.parse(message.data) as Notification)
+      })
+      source.onerror = () => {
+        // EventSource menyambung sendiri, kecuali jika ditolak (token kedaluwarsa)
+        if (source?.readyState === EventSource.CLOSED && !closed) {
+          setTimeout(connect, 3000)
+        }
+      }
+    } catch (error) {
+      console.error('Error opening notification stream:', error)
+      if (!closed) {
+        setTimeout(connect, 10000)
+      }
+    }
+  }
+
+  connect()
+  return () => {
+    closed = true
+    source?.close()
+  }
+}
@@ -2,5 +2,36 @@ import axiosInstance from '@/services/axiosInstance'
 
 export const getMyProfile = async () => {
   const response = await axiosInstance.get('/profile/me') // atau endpoint yang sesuai
-  return response.data
+  return response.data.data
+}
+
+/**
+ * Notification emails a user receives.
+ */
+export interface NotificationSettings {
+  enrollments: boolean
+  new_lessons: boolean
+  quiz_results: boolean
+}
+
+export const getNotificationSettings = async (): Promise<NotificationSettings> => {
+  const response = await axiosInstance.get('/profile/notifications')
+  return response.data.data as NotificationSettings
+}
+
+export const updateNotificationSettings = async (
+  settings: Partial<NotificationSettings>,
+): Promise<NotificationSettings> => {
+  const response = await axiosInstance.put('/profile/notifications', settings)
+  return response.data.data as NotificationSettings
+}
+
+/**
+ * Turn off notification emails with the token of an unsubscribe link; no
+ * login is needed.
+ * @param token - The token from the link in the email.
+ */
+export const unsubscribe = async (token: string): Promise<NotificationSettings> => {
+  const response = await axiosInstance.post('/unsubscribe', null, { params: { token } })
+  return response.data.data as NotificationSettings
 }
@@ -33,9 +33,9 @@ export const useAuthStore = defineStore('auth', {
     
       try {
         const res = await axiosInstance.get('/profile/me')
-        const userData = res.data
+        const userData = res.data.data
 
-        console.log(res.data)
+        console.log(userData)
     
         // Pastikan data sesuai dengan interface User
         this.user = {